@@ -0,0 +1,238 @@
+package admin
+
+import (
+	"database/sql"
+	"net/http"
+	"ticket/api/authen/users"
+	"ticket/pkg/apikit"
+	"ticket/pkg/auth"
+	"ticket/pkg/db"
+	"ticket/pkg/store"
+	"ticket/pkg/wire"
+	"time"
+
+	"github.com/guregu/null/v5"
+	"github.com/labstack/echo/v4"
+)
+
+const defaultPerPage = 20
+
+type Handler struct {
+	Store store.Store
+	Auth  *auth.Auth
+}
+
+func New(api *apikit.API) *Handler {
+	return &Handler{
+		Store: api.Store,
+		Auth:  auth.New(api.Config),
+	}
+}
+
+type UsersPage struct {
+	Users   []users.UserResponse `json:"users"`
+	Total   int64                `json:"total"`
+	Page    int32                `json:"page"`
+	PerPage int32                `json:"per_page"`
+}
+
+type ResetPasswordResponse struct {
+	TemporaryPassword string `json:"temporary_password"`
+}
+
+type UsersQuery struct {
+	Q       string `query:"q" validate:"max=255"`
+	Page    int32  `query:"page" validate:"omitempty,min=1"`
+	PerPage int32  `query:"per_page" validate:"omitempty,min=1,max=100"`
+}
+
+func (h *Handler) GetUsers(c echo.Context) error {
+	var query UsersQuery
+
+	err := c.Bind(&query)
+	if err != nil {
+		return err
+	}
+
+	err = c.Validate(&query)
+	if err != nil {
+		return err
+	}
+
+	if query.Page == 0 {
+		query.Page = 1
+	}
+
+	if query.PerPage == 0 {
+		query.PerPage = defaultPerPage
+	}
+
+	ctx := c.Request().Context()
+
+	total, err := h.Store.CountSearchUsers(ctx, query.Q)
+	if err != nil {
+		return err
+	}
+
+	found, err := h.Store.SearchUsers(ctx, db.SearchUsersParams{
+		Query:  query.Q,
+		Limit:  query.PerPage,
+		Offset: (query.Page - 1) * query.PerPage,
+	})
+	if err != nil {
+		return err
+	}
+
+	page := UsersPage{
+		Users:   []users.UserResponse{},
+		Total:   total,
+		Page:    query.Page,
+		PerPage: query.PerPage,
+	}
+
+	for _, u := range found {
+		page.Users = append(page.Users, users.NewUserResponse(u))
+	}
+
+	return c.JSON(http.StatusOK, page)
+}
+
+func (h *Handler) GetUser(c echo.Context) error {
+	user, err := h.findUser(c)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, users.NewUserResponse(user))
+}
+
+// DisableUser stops the user from signing in or refreshing tokens at once.
+// Sessions already open end within the status cache lifetime of auth.Middleware.
+func (h *Handler) DisableUser(c echo.Context) error {
+	claims := c.Get("claims").(*auth.Claims)
+
+	user, err := h.findUser(c)
+	if err != nil {
+		return err
+	}
+
+	if user.ID == claims.UserID {
+		return echo.NewHTTPError(http.StatusBadRequest, "cannot disable your own account")
+	}
+
+	return h.setDisabledAt(c, user, null.TimeFrom(time.Now()))
+}
+
+func (h *Handler) EnableUser(c echo.Context) error {
+	user, err := h.findUser(c)
+	if err != nil {
+		return err
+	}
+
+	return h.setDisabledAt(c, user, null.Time{})
+}
+
+// ResetPassword replaces the user's password with a generated one that is
+// returned once to the admin. The user is asked to change it after signing in.
+func (h *Handler) ResetPassword(c echo.Context) error {
+	user, err := h.findUser(c)
+	if err != nil {
+		return err
+	}
+
+	password, err := auth.RandomString(12)
+	if err != nil {
+		return err
+	}
+
+	hash, err := h.Auth.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	err = h.Store.UpdateUserPassword(c.Request().Context(), db.UpdateUserPasswordParams{
+		ID:                    user.ID,
+		Password:              null.NewString(hash, true),
+		PasswordResetRequired: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, wire.GenericResponse[ResetPasswordResponse]{
+		Error:   false,
+		Message: "password reset",
+		Data: ResetPasswordResponse{
+			TemporaryPassword: password,
+		},
+	})
+}
+
+// Impersonate issues tokens for another user so support can see what they
+// see. The tokens carry the admin's id as impersonator_id, and each one issued
+// is logged.
+func (h *Handler) Impersonate(c echo.Context) error {
+	claims := c.Get("claims").(*auth.Claims)
+
+	user, err := h.findUser(c)
+	if err != nil {
+		return err
+	}
+
+	if user.IsAdmin {
+		return echo.NewHTTPError(http.StatusForbidden, "cannot impersonate an admin")
+	}
+
+	if user.DisabledAt.Valid {
+		return apikit.NewError(http.StatusForbidden, wire.CodeAccountDisabled, "account disabled")
+	}
+
+	tokens, err := h.Auth.GenerateTokens(auth.TokenPayload{
+		UserID:         user.ID,
+		ImpersonatorID: claims.UserID,
+	})
+	if err != nil {
+		return err
+	}
+
+	apikit.Logger(c).Info("impersonation started", "impersonator_id", claims.UserID, "user_id", user.ID)
+
+	return c.JSON(http.StatusOK, tokens)
+}
+
+func (h *Handler) findUser(c echo.Context) (db.User, error) {
+	userID, err := apikit.ParamID(c, "user_id", 64)
+	if err != nil {
+		return db.User{}, err
+	}
+
+	user, err := h.Store.FindUserByID(c.Request().Context(), userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return db.User{}, echo.NewHTTPError(http.StatusNotFound, "user not found")
+		}
+
+		return db.User{}, err
+	}
+
+	return user, nil
+}
+
+func (h *Handler) setDisabledAt(c echo.Context, user db.User, disabledAt null.Time) error {
+	ctx := c.Request().Context()
+
+	err := h.Store.UpdateUserDisabledAt(ctx, db.UpdateUserDisabledAtParams{
+		ID:         user.ID,
+		DisabledAt: disabledAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	user, err = h.Store.FindUserByID(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, users.NewUserResponse(user))
+}
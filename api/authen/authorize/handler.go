@@ -7,6 +7,9 @@ import (
 	"ticket/pkg/apikit"
 	"ticket/pkg/auth"
 	"ticket/pkg/db"
+	"ticket/pkg/metrics"
+	"ticket/pkg/store"
+	"ticket/pkg/wire"
 	"time"
 
 	"github.com/guregu/null/v5"
@@ -15,207 +18,275 @@ import (
 )
 
 type Handler struct {
-	DB        *sql.DB
-	Queries   *db.Queries
+	Store     store.Store
 	DBTimeOut time.Duration
 	Auth      *auth.Auth
+	Providers map[string]*auth.OIDCProvider
 }
 
 func New(api *apikit.API) *Handler {
 	return &Handler{
-		DB:        api.DB,
-		Queries:   db.New(api.DB),
+		Store:     api.Store,
 		DBTimeOut: api.Config.DB().TimeOut,
 		Auth:      auth.New(api.Config),
+		Providers: auth.NewOIDCProviders(api.Config.GLobal().OIDC.Providers),
 	}
 }
 
+type SignInRequest struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+}
+
 func (h *Handler) SignIn(c echo.Context) error {
-	var body struct {
-		Email    string `json:"email" validate:"required,email"`
-		Password string `json:"password" validate:"required"`
-	}
+	var body SignInRequest
 
 	err := c.Bind(&body)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	err = c.Validate(&body)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	ctx, cancel := context.WithTimeout(c.Request().Context(), h.DBTimeOut)
 	defer cancel()
 
-	user, err := h.Queries.FindUserByEmail(ctx, null.NewString(body.Email, true))
+	user, err := h.Store.FindUserByEmail(ctx, null.NewString(body.Email, true))
 	if err != nil {
 		if err == sql.ErrNoRows {
+			metrics.RecordSignIn("password", false)
 			return echo.NewHTTPError(http.StatusNotFound, "user not found")
 		}
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	if user.ID == 0 {
+		metrics.RecordSignIn("password", false)
 		return echo.NewHTTPError(http.StatusNotFound, "user not found")
 	}
 
 	err = h.Auth.ComparePassword(user.Password.String, body.Password)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusUnauthorized, "invalid password")
+		metrics.RecordSignIn("password", false)
+		return apikit.NewError(http.StatusUnauthorized, wire.CodeInvalidCredentials, "invalid password")
 	}
 
-	payload := auth.TokenPayload{
-		UserID: user.ID,
+	if user.DisabledAt.Valid {
+		metrics.RecordSignIn("password", false)
+		return apikit.NewError(http.StatusForbidden, wire.CodeAccountDisabled, "account disabled")
 	}
 
-	tokens, err := h.Auth.GenerateTokens(payload)
+	tokens, err := h.Auth.GenerateTokens(auth.PayloadFor(user))
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
+	metrics.RecordSignIn("password", true)
+
 	return c.JSON(http.StatusOK, tokens)
 
 }
 
+type SignUpRequest struct {
+	Name     string `json:"name" validate:"required,min=3,max=100"`
+	Lastname string `json:"lastname" validate:"required,min=3,max=100"`
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required,min=8,max=32"`
+}
+
 func (h *Handler) SignUp(c echo.Context) error {
-	var body struct {
-		Name     string `json:"name" validate:"required,min=3,max=100"`
-		Lastname string `json:"lastname" validate:"required,min=3,max=100"`
-		Email    string `json:"email" validate:"required,email"`
-		Password string `json:"password" validate:"required,min=8,max=32"`
-	}
+	var body SignUpRequest
 
 	err := c.Bind(&body)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	err = c.Validate(&body)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	ctx, cancel := context.WithTimeout(c.Request().Context(), h.DBTimeOut)
 	defer cancel()
 
-	user, err := h.Queries.FindUserByEmail(ctx, null.NewString(body.Email, true))
+	user, err := h.Store.FindUserByEmail(ctx, null.NewString(body.Email, true))
 	if err != nil && err != sql.ErrNoRows {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	if user.ID != 0 {
-		return echo.NewHTTPError(http.StatusConflict, "email already exists")
+		return apikit.NewError(http.StatusConflict, wire.CodeEmailTaken, "email already exists")
 	}
 
 	hash, err := h.Auth.HashPassword(body.Password)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
-	tx, err := h.DB.Begin()
+	err = store.WithTx(ctx, h.Store, func(qtx store.Querier) error {
+		_, err := h.createUser(ctx, qtx, db.CreateUserParams{
+			Name:     null.NewString(body.Name, true),
+			Lastname: null.NewString(body.Lastname, true),
+			Email:    null.NewString(body.Email, true),
+			Password: null.NewString(hash, true),
+		})
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
-	defer tx.Rollback()
-	qtx := h.Queries.WithTx(tx)
 
-	err = qtx.CreateUser(ctx, db.CreateUserParams{
-		Name:     null.NewString(body.Name, true),
-		Lastname: null.NewString(body.Lastname, true),
-		Email:    null.NewString(body.Email, true),
-		Password: null.NewString(hash, true),
+	return c.JSON(http.StatusCreated, wire.GenericResponse[any]{
+		Error:   false,
+		Message: "user created",
 	})
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+func (h *Handler) RefreshToken(c echo.Context) error {
+	var body RefreshTokenRequest
+
+	err := c.Bind(&body)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
-	userID, err := qtx.GetLastInsertUserID(ctx)
+	err = c.Validate(&body)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
-	err = qtx.CreateBoard(ctx, db.CreateBoardParams{
-		UserID: uint64(userID),
-		Title:  null.NewString("My first board", true),
-	})
+	claims, err := h.Auth.ParseToken(body.RefreshToken)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid token")
 	}
 
-	boardID, err := qtx.GetLastInsertBoardID(ctx)
+	ctx, cancel := context.WithTimeout(c.Request().Context(), h.DBTimeOut)
+	defer cancel()
+
+	user, err := h.Store.FindUserByID(ctx, claims.UserID)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		if err == sql.ErrNoRows {
+			return echo.NewHTTPError(http.StatusUnauthorized, "invalid token")
+		}
+
+		return err
 	}
 
-	subctx, cancel := context.WithCancel(ctx)
-	e, subctx := errgroup.WithContext(subctx)
-	defer cancel()
+	if user.DisabledAt.Valid {
+		return apikit.NewError(http.StatusForbidden, wire.CodeAccountDisabled, "account disabled")
+	}
 
-	statusTitles := []string{"pending", "accepted", "resolved", "rejected"}
-	for i, title := range statusTitles {
-		i, title := i, title
-		e.Go(func() error {
-			err = qtx.CreateStatus(subctx, db.CreateStatusParams{
-				BoardID:   uint32(boardID),
-				Title:     null.NewString(title, true),
-				SortOrder: uint32(i + 1),
-			})
-			if err != nil {
-				cancel()
+	// The scopes follow the user, so a session regains full access on its
+	// first refresh after changing the password. Impersonation is not
+	// limited, as Impersonate does not limit it either, but only lasts while
+	// the impersonator is still an enabled admin.
+	payload := auth.PayloadFor(user)
+	if claims.ImpersonatorID != 0 {
+		err = h.checkImpersonator(ctx, claims.ImpersonatorID)
+		if err != nil {
+			return err
+		}
 
-				return err
-			}
+		payload = auth.TokenPayload{
+			UserID:         user.ID,
+			ImpersonatorID: claims.ImpersonatorID,
+		}
 
-			return nil
-		})
+		apikit.Logger(c).Info("impersonation refreshed", "impersonator_id", claims.ImpersonatorID, "user_id", user.ID)
 	}
 
-	err = e.Wait()
+	tokens, err := h.Auth.GenerateTokens(payload)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
-	err = tx.Commit()
+	return c.JSON(http.StatusOK, tokens)
+}
+
+// checkImpersonator rejects refreshing an impersonation session whose admin
+// has since lost the admin flag or been disabled.
+func (h *Handler) checkImpersonator(ctx context.Context, id uint64) error {
+	impersonator, err := h.Store.FindUserByID(ctx, id)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		if err == sql.ErrNoRows {
+			return echo.NewHTTPError(http.StatusUnauthorized, "invalid token")
+		}
+
+		return err
 	}
 
-	return c.JSON(http.StatusCreated, apikit.GenericResponse[any]{
-		Error:   false,
-		Message: "user created",
-	})
+	if !impersonator.IsAdmin || impersonator.DisabledAt.Valid {
+		return echo.NewHTTPError(http.StatusForbidden, "impersonation ended")
+	}
+
+	return nil
 }
 
-func (h *Handler) RefreshToken(c echo.Context) error {
-	var body struct {
-		RefreshToken string `json:"refresh_token" validate:"required"`
+// createUser inserts the user together with a first board and its default
+// statuses, the same starting point SignUp gives every new account.
+func (h *Handler) createUser(ctx context.Context, qtx store.Querier, arg db.CreateUserParams) (uint64, error) {
+	res, err := qtx.CreateUser(ctx, arg)
+	if err != nil {
+		return 0, err
 	}
 
-	err := c.Bind(&body)
+	userID, err := res.LastInsertId()
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return 0, err
 	}
 
-	err = c.Validate(&body)
+	res, err = qtx.CreateBoard(ctx, db.CreateBoardParams{
+		UserID: uint64(userID),
+		Title:  null.NewString("My first board", true),
+	})
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return 0, err
 	}
 
-	claims, err := h.Auth.ParseToken(body.RefreshToken)
+	boardID, err := res.LastInsertId()
 	if err != nil {
-		return echo.NewHTTPError(http.StatusUnauthorized, "invalid token")
+		return 0, err
 	}
 
-	payload := auth.TokenPayload{
-		UserID: claims.UserID,
+	subctx, cancel := context.WithCancel(ctx)
+	e, subctx := errgroup.WithContext(subctx)
+	defer cancel()
+
+	statusTitles := []string{"pending", "accepted", "resolved", "rejected"}
+	for i, title := range statusTitles {
+		i, title := i, title
+		e.Go(func() error {
+			_, err := qtx.CreateStatus(subctx, db.CreateStatusParams{
+				BoardID:   uint32(boardID),
+				Title:     null.NewString(title, true),
+				SortOrder: uint32(i + 1),
+			})
+			if err != nil {
+				cancel()
+
+				return err
+			}
+
+			return nil
+		})
 	}
 
-	tokens, err := h.Auth.GenerateTokens(payload)
+	err = e.Wait()
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return 0, err
 	}
 
-	return c.JSON(http.StatusOK, tokens)
+	return uint64(userID), nil
 }
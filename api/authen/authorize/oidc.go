@@ -0,0 +1,229 @@
+package authorize
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"ticket/pkg/apikit"
+	"ticket/pkg/auth"
+	"ticket/pkg/db"
+	"ticket/pkg/metrics"
+	"ticket/pkg/store"
+	"ticket/pkg/wire"
+
+	"github.com/guregu/null/v5"
+	"github.com/labstack/echo/v4"
+	"golang.org/x/oauth2"
+)
+
+const (
+	oidcStateCookie    = "oidc_state"
+	oidcNonceCookie    = "oidc_nonce"
+	oidcVerifierCookie = "oidc_verifier"
+	oidcCookieMaxAge   = 600
+)
+
+var errEmailNotVerified = errors.New("email is not verified by the identity provider")
+
+func (h *Handler) OIDCLogin(c echo.Context) error {
+	provider, ok := h.Providers[c.Param("provider")]
+	if !ok {
+		return echo.NewHTTPError(http.StatusNotFound, "provider not found")
+	}
+
+	state, err := auth.RandomString(32)
+	if err != nil {
+		return err
+	}
+
+	nonce, err := auth.RandomString(32)
+	if err != nil {
+		return err
+	}
+
+	verifier := oauth2.GenerateVerifier()
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), h.DBTimeOut)
+	defer cancel()
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		apikit.Logger(c).Error("oidc login failed", "provider", provider.Name, "error", err)
+
+		return apikit.NewError(http.StatusBadGateway, wire.CodeBadGateway, "identity provider unavailable")
+	}
+
+	setOIDCCookie(c, oidcStateCookie, state, oidcCookieMaxAge)
+	setOIDCCookie(c, oidcNonceCookie, nonce, oidcCookieMaxAge)
+	setOIDCCookie(c, oidcVerifierCookie, verifier, oidcCookieMaxAge)
+
+	return c.Redirect(http.StatusFound, authURL)
+}
+
+func (h *Handler) OIDCCallback(c echo.Context) error {
+	provider, ok := h.Providers[c.Param("provider")]
+	if !ok {
+		return echo.NewHTTPError(http.StatusNotFound, "provider not found")
+	}
+
+	if e := c.QueryParam("error"); e != "" {
+		apikit.Logger(c).Warn("oidc provider returned an error", "provider", provider.Name,
+			"error", e, "description", c.QueryParam("error_description"))
+
+		return apikit.NewError(http.StatusUnauthorized, wire.CodeOIDCFailed, "sign-in with the identity provider failed")
+	}
+
+	state, err := c.Cookie(oidcStateCookie)
+	if err != nil || state.Value == "" || state.Value != c.QueryParam("state") {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid state")
+	}
+
+	nonce, err := c.Cookie(oidcNonceCookie)
+	if err != nil || nonce.Value == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "missing nonce")
+	}
+
+	verifier, err := c.Cookie(oidcVerifierCookie)
+	if err != nil || verifier.Value == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "missing code verifier")
+	}
+
+	setOIDCCookie(c, oidcStateCookie, "", -1)
+	setOIDCCookie(c, oidcNonceCookie, "", -1)
+	setOIDCCookie(c, oidcVerifierCookie, "", -1)
+
+	code := c.QueryParam("code")
+	if code == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "missing code")
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), h.DBTimeOut)
+	defer cancel()
+
+	// The provider's errors stay in the log; they can describe its setup.
+	identity, err := provider.Exchange(ctx, code, verifier.Value, nonce.Value)
+	if err != nil {
+		metrics.RecordSignIn("oidc", false)
+		if errors.Is(err, auth.ErrDiscovery) {
+			apikit.Logger(c).Error("oidc callback failed", "provider", provider.Name, "error", err)
+
+			return apikit.NewError(http.StatusBadGateway, wire.CodeBadGateway, "identity provider unavailable")
+		}
+
+		apikit.Logger(c).Warn("oidc callback failed", "provider", provider.Name, "error", err)
+
+		return apikit.NewError(http.StatusUnauthorized, wire.CodeOIDCFailed, "sign-in with the identity provider failed")
+	}
+
+	userID, err := h.findOrCreateOIDCUser(ctx, provider.Name, identity)
+	if err != nil {
+		if err == errEmailNotVerified {
+			return apikit.NewError(http.StatusForbidden, wire.CodeOIDCFailed, err.Error())
+		}
+
+		return err
+	}
+
+	user, err := h.Store.FindUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.DisabledAt.Valid {
+		metrics.RecordSignIn("oidc", false)
+		return apikit.NewError(http.StatusForbidden, wire.CodeAccountDisabled, "account disabled")
+	}
+
+	tokens, err := h.Auth.GenerateTokens(auth.PayloadFor(user))
+	if err != nil {
+		return err
+	}
+
+	metrics.RecordSignIn("oidc", true)
+
+	if redirect := provider.ClientRedirectURL(); redirect != "" {
+		fragment := url.Values{}
+		fragment.Set("access_token", tokens.AccessToken)
+		fragment.Set("refresh_token", tokens.RefreshToken)
+
+		return c.Redirect(http.StatusFound, fmt.Sprintf("%s#%s", redirect, fragment.Encode()))
+	}
+
+	return c.JSON(http.StatusOK, tokens)
+}
+
+// findOrCreateOIDCUser resolves the local user for an external identity. A
+// known (provider, subject) pair wins; otherwise the identity is linked to the
+// account with the same verified email, or a new account is created for it.
+func (h *Handler) findOrCreateOIDCUser(ctx context.Context, provider string, identity auth.OIDCIdentity) (uint64, error) {
+	var userID uint64
+	err := store.WithTx(ctx, h.Store, func(qtx store.Querier) error {
+		linked, err := qtx.GetUserIdentity(ctx, db.GetUserIdentityParams{
+			Provider: provider,
+			Subject:  identity.Subject,
+		})
+		if err == nil {
+			userID = linked.UserID
+
+			return nil
+		}
+
+		if err != sql.ErrNoRows {
+			return err
+		}
+
+		if identity.Email == "" || !identity.EmailVerified {
+			return errEmailNotVerified
+		}
+
+		user, err := qtx.FindUserByEmail(ctx, null.NewString(identity.Email, true))
+		switch {
+		case err == nil:
+			userID = user.ID
+		case err == sql.ErrNoRows:
+			name, lastname := identity.GivenName, identity.FamilyName
+			if name == "" {
+				name, lastname, _ = strings.Cut(identity.Name, " ")
+			}
+
+			userID, err = h.createUser(ctx, qtx, db.CreateUserParams{
+				Name:     null.NewString(name, name != ""),
+				Lastname: null.NewString(lastname, lastname != ""),
+				Email:    null.NewString(identity.Email, true),
+			})
+			if err != nil {
+				return err
+			}
+		default:
+			return err
+		}
+
+		return qtx.CreateUserIdentity(ctx, db.CreateUserIdentityParams{
+			UserID:   userID,
+			Provider: provider,
+			Subject:  identity.Subject,
+			Email:    null.NewString(identity.Email, true),
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return userID, nil
+}
+
+func setOIDCCookie(c echo.Context, name, value string, maxAge int) {
+	c.SetCookie(&http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   c.Scheme() == "https",
+		SameSite: http.SameSiteLaxMode,
+	})
+}
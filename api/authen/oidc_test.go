@@ -0,0 +1,278 @@
+package authen
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"ticket/config"
+	"ticket/pkg/apikit"
+	"ticket/pkg/apikit/apitest"
+	"ticket/pkg/auth"
+	"ticket/pkg/auth/oidctest"
+	"ticket/pkg/db"
+	"ticket/pkg/wire"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/guregu/null/v5"
+)
+
+// newOIDCFixture is newFixture with mock as the provider "test".
+func newOIDCFixture(t *testing.T, mock *oidctest.Provider, edit ...func(*config.OIDCProvider)) *apitest.Server {
+	t.Helper()
+
+	pc := mock.Config()
+	for _, e := range edit {
+		e(&pc)
+	}
+
+	cf := apitest.Config()
+	cf.OIDC.Providers = map[string]config.OIDCProvider{"test": pc}
+
+	return newFixtureWith(t, apikit.WithGlobal(cf))
+}
+
+// oidcCallback signs in at /oidc/test/login, lets mock authorize it with
+// claims and returns the callback request the browser would send, cookies
+// included.
+func oidcCallback(t *testing.T, s *apitest.Server, mock *oidctest.Provider, claims jwt.MapClaims) *http.Request {
+	t.Helper()
+
+	rec := s.Do(t, http.MethodGet, "/oidc/test/login", "", nil)
+	if rec.Code != http.StatusFound {
+		t.Fatalf("login status = %d, want %d: %s", rec.Code, http.StatusFound, rec.Body)
+	}
+
+	code, state := mock.Authorize(t, rec.Header().Get("Location"), claims)
+
+	req := httptest.NewRequest(http.MethodGet, "/oidc/test/callback?"+url.Values{"code": {code}, "state": {state}}.Encode(), nil)
+	for _, c := range rec.Result().Cookies() {
+		req.AddCookie(c)
+	}
+
+	return req
+}
+
+func verified(sub, email string) jwt.MapClaims {
+	return jwt.MapClaims{"sub": sub, "email": email, "email_verified": true}
+}
+
+func identityUser(t *testing.T, s *apitest.Server, subject string) uint64 {
+	t.Helper()
+
+	ui, err := s.Store.GetUserIdentity(context.Background(), db.GetUserIdentityParams{Provider: "test", Subject: subject})
+	if err != nil {
+		t.Fatalf("identity %s: %v", subject, err)
+	}
+
+	return ui.UserID
+}
+
+func TestOIDCCreatesUser(t *testing.T) {
+	mock := oidctest.New(t)
+	s := newOIDCFixture(t, mock)
+
+	claims := verified("new-1", "grace@example.com")
+	claims["given_name"], claims["family_name"] = "Grace", "Hopper"
+
+	rec := s.Serve(oidcCallback(t, s, mock, claims))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body)
+	}
+
+	c := session(t, s, rec)
+	u := user(t, s, c.UserID)
+	if u.Email.String != "grace@example.com" || u.Name.String != "Grace" || u.Lastname.String != "Hopper" || u.Password.Valid {
+		t.Errorf("user = %+v, want Grace Hopper without a password", u)
+	}
+
+	if id := identityUser(t, s, "new-1"); id != c.UserID {
+		t.Errorf("identity belongs to user %d, want %d", id, c.UserID)
+	}
+
+	boards, err := s.Store.GetBoardsByUserID(context.Background(), c.UserID)
+	if err != nil || len(boards) != 1 {
+		t.Errorf("boards = %v, %v, want the first board", boards, err)
+	}
+}
+
+func TestOIDCLinksExistingEmail(t *testing.T) {
+	mock := oidctest.New(t)
+	s := newOIDCFixture(t, mock)
+
+	rec := s.Serve(oidcCallback(t, s, mock, verified("linked-1", "user@example.com")))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body)
+	}
+
+	if c := session(t, s, rec); c.UserID != userID {
+		t.Fatalf("signed in as user %d, want the existing user %d", c.UserID, userID)
+	}
+
+	if id := identityUser(t, s, "linked-1"); id != userID {
+		t.Errorf("identity belongs to user %d, want %d", id, userID)
+	}
+
+	// The subject is known now, so it signs in the same user whatever email
+	// the provider reports later.
+	rec = s.Serve(oidcCallback(t, s, mock, verified("linked-1", "renamed@example.com")))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("second sign-in status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body)
+	}
+
+	if c := session(t, s, rec); c.UserID != userID {
+		t.Errorf("second sign-in as user %d, want %d", c.UserID, userID)
+	}
+
+	total, err := s.Store.CountSearchUsers(context.Background(), "")
+	if err != nil || total != 4 {
+		t.Errorf("%d users, %v; want no user created", total, err)
+	}
+}
+
+func TestOIDCLinkedUserKeepsRestrictions(t *testing.T) {
+	tests := []struct {
+		name     string
+		email    string
+		want     int
+		wantCode wire.Code
+	}{
+		{"disabled", "disabled@example.com", http.StatusForbidden, wire.CodeAccountDisabled},
+		{"password reset required", "flagged@example.com", http.StatusOK, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := oidctest.New(t)
+			s := newOIDCFixture(t, mock)
+
+			rec := s.Serve(oidcCallback(t, s, mock, verified("sub", tt.email)))
+			if rec.Code != tt.want || apitest.Code(t, rec) != tt.wantCode {
+				t.Fatalf("status = %d, want %d %s: %s", rec.Code, tt.want, tt.wantCode, rec.Body)
+			}
+
+			if rec.Code == http.StatusOK && !session(t, s, rec).HasScope(auth.ScopePasswordReset) {
+				t.Error("session is not limited to resetting the password")
+			}
+		})
+	}
+}
+
+func TestOIDCCallbackRejects(t *testing.T) {
+	tests := []struct {
+		name     string
+		claims   jwt.MapClaims
+		edit     func(req *http.Request) *http.Request
+		want     int
+		wantCode wire.Code
+	}{
+		{
+			name:   "state mismatch",
+			claims: verified("sub", "grace@example.com"),
+			edit: func(req *http.Request) *http.Request {
+				q := req.URL.Query()
+				q.Set("state", "forged")
+				req.URL.RawQuery = q.Encode()
+
+				return req
+			},
+			want: http.StatusBadRequest, wantCode: wire.CodeBadRequest,
+		},
+		{
+			name:   "missing cookies",
+			claims: verified("sub", "grace@example.com"),
+			edit: func(req *http.Request) *http.Request {
+				return httptest.NewRequest(http.MethodGet, req.URL.String(), nil)
+			},
+			want: http.StatusBadRequest, wantCode: wire.CodeBadRequest,
+		},
+		{
+			name:   "nonce mismatch",
+			claims: jwt.MapClaims{"sub": "sub", "email": "grace@example.com", "email_verified": true, "nonce": "replayed"},
+			want:   http.StatusUnauthorized, wantCode: wire.CodeOIDCFailed,
+		},
+		{
+			name:   "provider error",
+			claims: verified("sub", "grace@example.com"),
+			edit: func(req *http.Request) *http.Request {
+				q := req.URL.Query()
+				q.Set("error", "access_denied")
+				req.URL.RawQuery = q.Encode()
+
+				return req
+			},
+			want: http.StatusUnauthorized, wantCode: wire.CodeOIDCFailed,
+		},
+		{
+			name:   "unverified email",
+			claims: jwt.MapClaims{"sub": "sub", "email": "user@example.com", "email_verified": false},
+			want:   http.StatusForbidden, wantCode: wire.CodeOIDCFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := oidctest.New(t)
+			s := newOIDCFixture(t, mock)
+
+			req := oidcCallback(t, s, mock, tt.claims)
+			if tt.edit != nil {
+				req = tt.edit(req)
+			}
+
+			rec := s.Serve(req)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.want, rec.Body)
+			}
+
+			if code := apitest.Code(t, rec); code != tt.wantCode {
+				t.Errorf("code = %q, want %q", code, tt.wantCode)
+			}
+
+			_, err := s.Store.GetUserIdentity(context.Background(), db.GetUserIdentityParams{Provider: "test", Subject: "sub"})
+			if err == nil {
+				t.Error("the identity was linked")
+			}
+
+			_, err = s.Store.FindUserByEmail(context.Background(), null.StringFrom("grace@example.com"))
+			if err == nil {
+				t.Error("a user was created")
+			}
+		})
+	}
+}
+
+func TestOIDCClientRedirect(t *testing.T) {
+	mock := oidctest.New(t)
+	s := newOIDCFixture(t, mock, func(pc *config.OIDCProvider) {
+		pc.ClientRedirectURL = "https://app.example.com/signed-in"
+	})
+
+	rec := s.Serve(oidcCallback(t, s, mock, verified("sub", "user@example.com")))
+	if rec.Code != http.StatusFound {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusFound, rec.Body)
+	}
+
+	target, fragment, _ := strings.Cut(rec.Header().Get("Location"), "#")
+	values, err := url.ParseQuery(fragment)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if target != "https://app.example.com/signed-in" || values.Get("access_token") == "" || values.Get("refresh_token") == "" {
+		t.Errorf("redirected to %s, want the client with tokens in the fragment", rec.Header().Get("Location"))
+	}
+}
+
+func TestOIDCProviderDown(t *testing.T) {
+	mock := oidctest.New(t)
+	s := newOIDCFixture(t, mock)
+	mock.Close()
+
+	rec := s.Do(t, http.MethodGet, "/oidc/test/login", "", nil)
+	if rec.Code != http.StatusBadGateway || apitest.Code(t, rec) != wire.CodeBadGateway {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusBadGateway, rec.Body)
+	}
+}
@@ -0,0 +1,67 @@
+package authen
+
+import (
+	"net/http"
+	"ticket/api/authen/admin"
+	"ticket/api/authen/authorize"
+	"ticket/api/authen/users"
+	"ticket/pkg/apikit"
+	"ticket/pkg/openapi"
+	"ticket/pkg/wire"
+)
+
+// Spec describes the routes of Router. Keep the two in step: the service
+// warns on startup, and cmd/openapi -check fails, when a route is missing.
+func Spec() *openapi.Document {
+	doc := apikit.NewSpec("Authen service").
+		PathParam("user_id", uint64(0))
+
+	a := doc.Group("").Tag("authorize")
+	a.POST("/sign-in", "signIn").Summarize("Sign in with an email and password").
+		Body(authorize.SignInRequest{}).
+		Returns(http.StatusOK, wire.Tokens{})
+	a.POST("/sign-up", "signUp").Summarize("Create an account").
+		Body(authorize.SignUpRequest{}).
+		Returns(http.StatusCreated, wire.GenericResponse[any]{})
+	a.POST("/refresh-token", "refreshToken").Summarize("Exchange a refresh token for new tokens").
+		Body(authorize.RefreshTokenRequest{}).
+		Returns(http.StatusOK, wire.Tokens{})
+	a.GET("/oidc/:provider/login", "oidcLogin").Summarize("Redirect to an OpenID Connect provider").
+		Returns(http.StatusFound, nil)
+	a.GET("/oidc/:provider/callback", "oidcCallback").Summarize("Finish signing in with an OpenID Connect provider").
+		Returns(http.StatusOK, wire.Tokens{}).
+		Returns(http.StatusFound, nil)
+
+	usersGroup := doc.Group("/users").Tag("users").Secured()
+	usersGroup.GET("/me", "getMe").Summarize("Get the current user").
+		Returns(http.StatusOK, users.UserResponse{})
+	usersGroup.PATCH("/me", "updateMe").Summarize("Change the current user's name").
+		Body(users.UpdateMeRequest{}).
+		Returns(http.StatusOK, users.UserResponse{})
+	usersGroup.DELETE("/me", "deleteMe").Summarize("Delete the current user and their boards").
+		Body(users.DeleteMeRequest{}).
+		Returns(http.StatusNoContent, nil)
+	usersGroup.PUT("/me/email", "changeEmail").Summarize("Change the current user's email").
+		Body(users.ChangeEmailRequest{}).
+		Returns(http.StatusOK, users.UserResponse{})
+	usersGroup.PUT("/me/password", "changePassword").Summarize("Change the current user's password").
+		Body(users.ChangePasswordRequest{}).
+		Returns(http.StatusOK, wire.GenericResponse[any]{})
+
+	adminGroup := doc.Group("/admin").Tag("admin").Secured()
+	adminGroup.GET("/users", "getUsers").Summarize("Search users").
+		Query(admin.UsersQuery{}).
+		Returns(http.StatusOK, admin.UsersPage{})
+	adminGroup.GET("/users/:user_id", "getUser").Summarize("Get a user").
+		Returns(http.StatusOK, users.UserResponse{})
+	adminGroup.POST("/users/:user_id/disable", "disableUser").Summarize("Disable a user; open sessions end within 30 seconds").
+		Returns(http.StatusOK, users.UserResponse{})
+	adminGroup.POST("/users/:user_id/enable", "enableUser").Summarize("Enable a user").
+		Returns(http.StatusOK, users.UserResponse{})
+	adminGroup.POST("/users/:user_id/reset-password", "resetPassword").Summarize("Reset a user's password to a temporary one").
+		Returns(http.StatusOK, wire.GenericResponse[admin.ResetPasswordResponse]{})
+	adminGroup.POST("/users/:user_id/impersonate", "impersonate").Summarize("Issue tokens that act as a user").
+		Returns(http.StatusOK, wire.Tokens{})
+
+	return doc
+}
@@ -0,0 +1,15 @@
+package authen
+
+import (
+	"testing"
+	"ticket/pkg/apikit"
+)
+
+func TestSpecCoversRoutes(t *testing.T) {
+	api := apikit.NewAPI(apikit.WithLog(apikit.LogConfig{Level: "error"})).UseRouter(Router).UseSpec(Spec())
+	api.Mount()
+
+	if missing := api.Missing(); len(missing) > 0 {
+		t.Fatalf("routes missing from Spec: %v", missing)
+	}
+}
@@ -1,6 +1,7 @@
 package authen
 
 import (
+	"ticket/api/authen/admin"
 	"ticket/api/authen/authorize"
 	"ticket/api/authen/users"
 	"ticket/pkg/apikit"
@@ -13,10 +14,31 @@ func Router(api *apikit.API) {
 	api.App.POST("/sign-in", a.SignIn)
 	api.App.POST("/sign-up", a.SignUp)
 	api.App.POST("/refresh-token", a.RefreshToken)
+	api.App.GET("/oidc/:provider/login", a.OIDCLogin)
+	api.App.GET("/oidc/:provider/callback", a.OIDCCallback)
+
+	guard := auth.Middleware(api.Config, api.Store)
 
 	u := users.New(api)
 
+	// A user who must change their password can only read their profile and
+	// change the password.
+	resetGuard := auth.Middleware(api.Config, api.Store, auth.AllowPasswordReset())
+
 	usersGroup := api.App.Group("/users")
-	usersGroup.Use(auth.Middleware(api.Config))
-	usersGroup.GET("/me", u.GetMe)
+	usersGroup.GET("/me", u.GetMe, resetGuard)
+	usersGroup.PATCH("/me", u.UpdateMe, guard)
+	usersGroup.DELETE("/me", u.DeleteMe, guard)
+	usersGroup.PUT("/me/email", u.ChangeEmail, guard)
+	usersGroup.PUT("/me/password", u.ChangePassword, resetGuard)
+
+	ad := admin.New(api)
+
+	adminGroup := api.App.Group("/admin", guard, auth.AdminMiddleware(api.Store))
+	adminGroup.GET("/users", ad.GetUsers)
+	adminGroup.GET("/users/:user_id", ad.GetUser)
+	adminGroup.POST("/users/:user_id/disable", ad.DisableUser)
+	adminGroup.POST("/users/:user_id/enable", ad.EnableUser)
+	adminGroup.POST("/users/:user_id/reset-password", ad.ResetPassword)
+	adminGroup.POST("/users/:user_id/impersonate", ad.Impersonate)
 }
@@ -0,0 +1,343 @@
+package authen
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"ticket/api/authen/admin"
+	"ticket/api/authen/users"
+	"ticket/pkg/apikit"
+	"ticket/pkg/apikit/apitest"
+	"ticket/pkg/auth"
+	"ticket/pkg/db"
+	"ticket/pkg/wire"
+	"time"
+
+	"github.com/guregu/null/v5"
+)
+
+// fixture ids. Memory hands them out in order, so every fixture has the same.
+const (
+	userID     = 1
+	adminID    = 2
+	disabledID = 3
+	flaggedID  = 4
+)
+
+// newFixture has a user, an admin, a disabled user and a user who must change
+// their password, all with apitest.Password.
+func newFixture(t *testing.T) *apitest.Server {
+	t.Helper()
+
+	return newFixtureWith(t)
+}
+
+// newFixtureWith is newFixture with options for the API, e.g. OIDC providers.
+func newFixtureWith(t *testing.T, opts ...apikit.Option) *apitest.Server {
+	t.Helper()
+
+	s := apitest.New(t, []apikit.Router{Router}, opts...)
+	s.Spec = Spec()
+
+	s.AddUser(t, db.User{Email: null.StringFrom("user@example.com"), Name: null.StringFrom("Ada"), Lastname: null.StringFrom("Lovelace")})
+	s.AddUser(t, db.User{Email: null.StringFrom("admin@example.com"), IsAdmin: true})
+	s.AddUser(t, db.User{Email: null.StringFrom("disabled@example.com"), DisabledAt: null.TimeFrom(time.Now())})
+	s.AddUser(t, db.User{Email: null.StringFrom("flagged@example.com"), PasswordResetRequired: true})
+
+	return s
+}
+
+func user(t *testing.T, s *apitest.Server, id uint64) db.User {
+	t.Helper()
+
+	u, err := s.Store.FindUserByID(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return u
+}
+
+// session parses the access token of a tokens response.
+func session(t *testing.T, s *apitest.Server, rec *httptest.ResponseRecorder) *auth.Claims {
+	t.Helper()
+
+	tokens := apitest.Decode[wire.Tokens](t, rec)
+	c, err := s.Auth.ParseToken(tokens.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return c
+}
+
+func TestSignInRoutes(t *testing.T) {
+	s := newFixture(t)
+	refresh := func(id uint64, impersonator uint64) string {
+		tokens, err := s.Auth.GenerateTokens(auth.TokenPayload{UserID: id, ImpersonatorID: impersonator})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return tokens.RefreshToken
+	}
+	signIn := func(email, password string) map[string]any {
+		return map[string]any{"email": email, "password": password}
+	}
+
+	apitest.Run(t, newFixture, []apitest.Case{
+		{
+			Name: "sign in", Method: http.MethodPost, Path: "/sign-in", Body: signIn("user@example.com", apitest.Password), Want: http.StatusOK,
+			Check: func(t *testing.T, s *apitest.Server, rec *httptest.ResponseRecorder) {
+				if c := session(t, s, rec); c.UserID != userID || len(c.Scopes) != 0 {
+					t.Errorf("claims = %+v, want a full session for user %d", c, userID)
+				}
+			},
+		},
+		{Name: "sign in with invalid email", Method: http.MethodPost, Path: "/sign-in", Body: signIn("user", apitest.Password), Want: http.StatusBadRequest, WantCode: wire.CodeValidation},
+		{Name: "sign in with wrong password", Method: http.MethodPost, Path: "/sign-in", Body: signIn("user@example.com", "wrong password"), Want: http.StatusUnauthorized, WantCode: wire.CodeInvalidCredentials},
+		{Name: "sign in as unknown user", Method: http.MethodPost, Path: "/sign-in", Body: signIn("nobody@example.com", apitest.Password), Want: http.StatusNotFound, WantCode: wire.CodeNotFound},
+		{Name: "sign in while disabled", Method: http.MethodPost, Path: "/sign-in", Body: signIn("disabled@example.com", apitest.Password), Want: http.StatusForbidden, WantCode: wire.CodeAccountDisabled},
+		{
+			Name: "sign in with password reset required", Method: http.MethodPost, Path: "/sign-in", Body: signIn("flagged@example.com", apitest.Password), Want: http.StatusOK,
+			Check: func(t *testing.T, s *apitest.Server, rec *httptest.ResponseRecorder) {
+				if c := session(t, s, rec); !c.HasScope(auth.ScopePasswordReset) {
+					t.Errorf("claims = %+v, want a session limited to resetting the password", c)
+				}
+			},
+		},
+
+		{
+			Name: "sign up", Method: http.MethodPost, Path: "/sign-up", Want: http.StatusCreated,
+			Body: map[string]any{"name": "Grace", "lastname": "Hopper", "email": "grace@example.com", "password": "password123"},
+			Check: func(t *testing.T, s *apitest.Server, rec *httptest.ResponseRecorder) {
+				u, err := s.Store.FindUserByEmail(context.Background(), null.StringFrom("grace@example.com"))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				boards, err := s.Store.GetBoardsByUserID(context.Background(), u.ID)
+				if err != nil || len(boards) != 1 {
+					t.Errorf("boards = %v, %v, want the first board", boards, err)
+				}
+			},
+		},
+		{
+			Name: "sign up with short password", Method: http.MethodPost, Path: "/sign-up", Want: http.StatusBadRequest, WantCode: wire.CodeValidation,
+			Body: map[string]any{"name": "Grace", "lastname": "Hopper", "email": "grace@example.com", "password": "short"},
+		},
+		{
+			Name: "sign up with a taken email", Method: http.MethodPost, Path: "/sign-up", Want: http.StatusConflict, WantCode: wire.CodeEmailTaken,
+			Body: map[string]any{"name": "Grace", "lastname": "Hopper", "email": "user@example.com", "password": "password123"},
+		},
+
+		{
+			Name: "refresh token", Method: http.MethodPost, Path: "/refresh-token", Body: map[string]any{"refresh_token": refresh(userID, 0)}, Want: http.StatusOK,
+			Check: func(t *testing.T, s *apitest.Server, rec *httptest.ResponseRecorder) {
+				if c := session(t, s, rec); c.UserID != userID {
+					t.Errorf("claims = %+v, want user %d", c, userID)
+				}
+			},
+		},
+		{
+			Name: "refresh token keeps the impersonator", Method: http.MethodPost, Path: "/refresh-token", Body: map[string]any{"refresh_token": refresh(flaggedID, adminID)}, Want: http.StatusOK,
+			Check: func(t *testing.T, s *apitest.Server, rec *httptest.ResponseRecorder) {
+				if c := session(t, s, rec); c.ImpersonatorID != adminID || len(c.Scopes) != 0 {
+					t.Errorf("claims = %+v, want an unlimited session impersonated by %d", c, adminID)
+				}
+			},
+		},
+		{
+			Name: "refresh token limits a flagged user", Method: http.MethodPost, Path: "/refresh-token", Body: map[string]any{"refresh_token": refresh(flaggedID, 0)}, Want: http.StatusOK,
+			Check: func(t *testing.T, s *apitest.Server, rec *httptest.ResponseRecorder) {
+				if c := session(t, s, rec); !c.HasScope(auth.ScopePasswordReset) {
+					t.Errorf("claims = %+v, want a session limited to resetting the password", c)
+				}
+			},
+		},
+		{Name: "refresh token without token", Method: http.MethodPost, Path: "/refresh-token", Body: map[string]any{}, Want: http.StatusBadRequest, WantCode: wire.CodeValidation},
+		{Name: "refresh token with invalid token", Method: http.MethodPost, Path: "/refresh-token", Body: map[string]any{"refresh_token": "not-a-token"}, Want: http.StatusUnauthorized, WantCode: wire.CodeUnauthorized},
+		{Name: "refresh token of unknown user", Method: http.MethodPost, Path: "/refresh-token", Body: map[string]any{"refresh_token": refresh(99, 0)}, Want: http.StatusUnauthorized, WantCode: wire.CodeUnauthorized},
+		{Name: "refresh token impersonated by a non-admin", Method: http.MethodPost, Path: "/refresh-token", Body: map[string]any{"refresh_token": refresh(flaggedID, userID)}, Want: http.StatusForbidden, WantCode: wire.CodeForbidden},
+		{Name: "refresh token impersonated by a missing admin", Method: http.MethodPost, Path: "/refresh-token", Body: map[string]any{"refresh_token": refresh(flaggedID, 99)}, Want: http.StatusUnauthorized, WantCode: wire.CodeUnauthorized},
+		{Name: "refresh token while disabled", Method: http.MethodPost, Path: "/refresh-token", Body: map[string]any{"refresh_token": refresh(disabledID, 0)}, Want: http.StatusForbidden, WantCode: wire.CodeAccountDisabled},
+
+		{Name: "oidc login with unknown provider", Method: http.MethodGet, Path: "/oidc/unknown/login", Want: http.StatusNotFound, WantCode: wire.CodeNotFound},
+		{Name: "oidc callback with unknown provider", Method: http.MethodGet, Path: "/oidc/unknown/callback?code=x&state=y", Want: http.StatusNotFound, WantCode: wire.CodeNotFound},
+	})
+}
+
+func TestRefreshEndsImpersonationOfDisabledAdmin(t *testing.T) {
+	s := newFixture(t)
+
+	tokens, err := s.Auth.GenerateTokens(auth.TokenPayload{UserID: userID, ImpersonatorID: adminID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = s.Store.UpdateUserDisabledAt(context.Background(), db.UpdateUserDisabledAtParams{ID: adminID, DisabledAt: null.TimeFrom(time.Now())})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := s.Do(t, http.MethodPost, "/refresh-token", "", map[string]any{"refresh_token": tokens.RefreshToken})
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusForbidden, rec.Body)
+	}
+}
+
+func TestUserRoutes(t *testing.T) {
+	s := newFixture(t)
+	token := s.Token(t, userID)
+	flagged := s.Token(t, flaggedID, auth.ScopePasswordReset)
+
+	apitest.Run(t, newFixture, []apitest.Case{
+		{
+			Name: "get me", Method: http.MethodGet, Path: "/users/me", Token: token, Want: http.StatusOK,
+			Check: func(t *testing.T, s *apitest.Server, rec *httptest.ResponseRecorder) {
+				if me := apitest.Decode[users.UserResponse](t, rec); me.ID != userID || me.Email != "user@example.com" {
+					t.Errorf("me = %+v, want user %d", me, userID)
+				}
+			},
+		},
+		{Name: "get me while a password reset is required", Method: http.MethodGet, Path: "/users/me", Token: flagged, Want: http.StatusOK},
+		{Name: "get me unauthenticated", Method: http.MethodGet, Path: "/users/me", Want: http.StatusUnauthorized, WantCode: wire.CodeUnauthorized},
+		{Name: "get me while disabled", Method: http.MethodGet, Path: "/users/me", Token: s.Token(t, disabledID), Want: http.StatusForbidden, WantCode: wire.CodeAccountDisabled},
+
+		{
+			Name: "update me", Method: http.MethodPatch, Path: "/users/me", Token: token, Body: map[string]any{"name": "Augusta"}, Want: http.StatusOK,
+			Check: func(t *testing.T, s *apitest.Server, rec *httptest.ResponseRecorder) {
+				if me := apitest.Decode[users.UserResponse](t, rec); me.Name != "Augusta" || me.Lastname != "Lovelace" {
+					t.Errorf("me = %+v, want only the name changed", me)
+				}
+			},
+		},
+		{Name: "update me with short name", Method: http.MethodPatch, Path: "/users/me", Token: token, Body: map[string]any{"name": "Al"}, Want: http.StatusBadRequest, WantCode: wire.CodeValidation},
+		{Name: "update me while a password reset is required", Method: http.MethodPatch, Path: "/users/me", Token: flagged, Body: map[string]any{"name": "Augusta"}, Want: http.StatusForbidden, WantCode: wire.CodePasswordResetRequired},
+		{Name: "update me unauthenticated", Method: http.MethodPatch, Path: "/users/me", Body: map[string]any{"name": "Augusta"}, Want: http.StatusUnauthorized},
+
+		{
+			Name: "change email", Method: http.MethodPut, Path: "/users/me/email", Token: token, Body: map[string]any{"email": "ada@example.com", "password": apitest.Password}, Want: http.StatusOK,
+			Check: func(t *testing.T, s *apitest.Server, rec *httptest.ResponseRecorder) {
+				if u := user(t, s, userID); u.Email.String != "ada@example.com" {
+					t.Errorf("email = %q, want ada@example.com", u.Email.String)
+				}
+			},
+		},
+		{Name: "change email to an invalid one", Method: http.MethodPut, Path: "/users/me/email", Token: token, Body: map[string]any{"email": "ada", "password": apitest.Password}, Want: http.StatusBadRequest, WantCode: wire.CodeValidation},
+		{Name: "change email with wrong password", Method: http.MethodPut, Path: "/users/me/email", Token: token, Body: map[string]any{"email": "ada@example.com", "password": "wrong password"}, Want: http.StatusUnauthorized, WantCode: wire.CodeInvalidCredentials},
+		{Name: "change email to a taken one", Method: http.MethodPut, Path: "/users/me/email", Token: token, Body: map[string]any{"email": "admin@example.com", "password": apitest.Password}, Want: http.StatusConflict, WantCode: wire.CodeEmailTaken},
+		{Name: "change email unauthenticated", Method: http.MethodPut, Path: "/users/me/email", Body: map[string]any{"email": "ada@example.com", "password": apitest.Password}, Want: http.StatusUnauthorized},
+
+		{
+			Name: "change password", Method: http.MethodPut, Path: "/users/me/password", Token: flagged, Body: map[string]any{"old_password": apitest.Password, "new_password": "new password"}, Want: http.StatusOK,
+			Check: func(t *testing.T, s *apitest.Server, rec *httptest.ResponseRecorder) {
+				u := user(t, s, flaggedID)
+				if u.PasswordResetRequired || s.Auth.ComparePassword(u.Password.String, "new password") != nil {
+					t.Errorf("user = %+v, want the new password and the flag cleared", u)
+				}
+			},
+		},
+		{Name: "change password to a short one", Method: http.MethodPut, Path: "/users/me/password", Token: token, Body: map[string]any{"old_password": apitest.Password, "new_password": "short"}, Want: http.StatusBadRequest, WantCode: wire.CodeValidation},
+		{Name: "change password with wrong password", Method: http.MethodPut, Path: "/users/me/password", Token: token, Body: map[string]any{"old_password": "wrong password", "new_password": "new password"}, Want: http.StatusUnauthorized, WantCode: wire.CodeInvalidCredentials},
+		{Name: "change password unauthenticated", Method: http.MethodPut, Path: "/users/me/password", Body: map[string]any{"old_password": apitest.Password, "new_password": "new password"}, Want: http.StatusUnauthorized},
+
+		{
+			Name: "delete me", Method: http.MethodDelete, Path: "/users/me", Token: token, Body: map[string]any{"password": apitest.Password}, Want: http.StatusNoContent,
+			Check: func(t *testing.T, s *apitest.Server, rec *httptest.ResponseRecorder) {
+				_, err := s.Store.FindUserByID(context.Background(), userID)
+				if err == nil {
+					t.Error("user still exists")
+				}
+			},
+		},
+		{Name: "delete me with wrong password", Method: http.MethodDelete, Path: "/users/me", Token: token, Body: map[string]any{"password": "wrong password"}, Want: http.StatusUnauthorized, WantCode: wire.CodeInvalidCredentials},
+		{Name: "delete me while a password reset is required", Method: http.MethodDelete, Path: "/users/me", Token: flagged, Body: map[string]any{"password": apitest.Password}, Want: http.StatusForbidden, WantCode: wire.CodePasswordResetRequired},
+		{Name: "delete me unauthenticated", Method: http.MethodDelete, Path: "/users/me", Body: map[string]any{"password": apitest.Password}, Want: http.StatusUnauthorized},
+	})
+}
+
+func TestAdminRoutes(t *testing.T) {
+	s := newFixture(t)
+	token := s.Token(t, adminID)
+	nonAdmin := s.Token(t, userID)
+
+	apitest.Run(t, newFixture, []apitest.Case{
+		{
+			Name: "list users", Method: http.MethodGet, Path: "/admin/users?per_page=2&page=2", Token: token, Want: http.StatusOK,
+			Check: func(t *testing.T, s *apitest.Server, rec *httptest.ResponseRecorder) {
+				page := apitest.Decode[admin.UsersPage](t, rec)
+				if page.Total != 4 || len(page.Users) != 2 || page.Users[0].ID != disabledID {
+					t.Errorf("page = %+v, want the last 2 of 4 users", page)
+				}
+			},
+		},
+		{
+			Name: "search users", Method: http.MethodGet, Path: "/admin/users?q=flagged", Token: token, Want: http.StatusOK,
+			Check: func(t *testing.T, s *apitest.Server, rec *httptest.ResponseRecorder) {
+				page := apitest.Decode[admin.UsersPage](t, rec)
+				if page.Total != 1 || page.Users[0].ID != flaggedID {
+					t.Errorf("page = %+v, want only user %d", page, flaggedID)
+				}
+			},
+		},
+		{Name: "list users with too many per page", Method: http.MethodGet, Path: "/admin/users?per_page=1000", Token: token, Want: http.StatusBadRequest, WantCode: wire.CodeValidation},
+		{Name: "list users as non-admin", Method: http.MethodGet, Path: "/admin/users", Token: nonAdmin, Want: http.StatusForbidden, WantCode: wire.CodeForbidden},
+		{Name: "list users unauthenticated", Method: http.MethodGet, Path: "/admin/users", Want: http.StatusUnauthorized, WantCode: wire.CodeUnauthorized},
+
+		{Name: "get user", Method: http.MethodGet, Path: "/admin/users/1", Token: token, Want: http.StatusOK},
+		{Name: "get user with invalid id", Method: http.MethodGet, Path: "/admin/users/abc", Token: token, Want: http.StatusBadRequest, WantCode: wire.CodeBadRequest},
+		{Name: "get missing user", Method: http.MethodGet, Path: "/admin/users/99", Token: token, Want: http.StatusNotFound, WantCode: wire.CodeNotFound},
+		{Name: "get user as non-admin", Method: http.MethodGet, Path: "/admin/users/1", Token: nonAdmin, Want: http.StatusForbidden},
+
+		{
+			Name: "disable user", Method: http.MethodPost, Path: "/admin/users/1/disable", Token: token, Want: http.StatusOK,
+			Check: func(t *testing.T, s *apitest.Server, rec *httptest.ResponseRecorder) {
+				if u := user(t, s, userID); !u.DisabledAt.Valid {
+					t.Error("user is not disabled")
+				}
+			},
+		},
+		{Name: "disable yourself", Method: http.MethodPost, Path: "/admin/users/2/disable", Token: token, Want: http.StatusBadRequest, WantCode: wire.CodeBadRequest},
+		{Name: "disable missing user", Method: http.MethodPost, Path: "/admin/users/99/disable", Token: token, Want: http.StatusNotFound, WantCode: wire.CodeNotFound},
+		{Name: "disable user as non-admin", Method: http.MethodPost, Path: "/admin/users/1/disable", Token: nonAdmin, Want: http.StatusForbidden},
+
+		{
+			Name: "enable user", Method: http.MethodPost, Path: "/admin/users/3/enable", Token: token, Want: http.StatusOK,
+			Check: func(t *testing.T, s *apitest.Server, rec *httptest.ResponseRecorder) {
+				if u := user(t, s, disabledID); u.DisabledAt.Valid {
+					t.Error("user is still disabled")
+				}
+			},
+		},
+		{Name: "enable missing user", Method: http.MethodPost, Path: "/admin/users/99/enable", Token: token, Want: http.StatusNotFound, WantCode: wire.CodeNotFound},
+		{Name: "enable user as non-admin", Method: http.MethodPost, Path: "/admin/users/3/enable", Token: nonAdmin, Want: http.StatusForbidden},
+
+		{
+			Name: "reset password", Method: http.MethodPost, Path: "/admin/users/1/reset-password", Token: token, Want: http.StatusOK,
+			Check: func(t *testing.T, s *apitest.Server, rec *httptest.ResponseRecorder) {
+				res := apitest.Decode[wire.GenericResponse[admin.ResetPasswordResponse]](t, rec)
+				u := user(t, s, userID)
+				if !u.PasswordResetRequired || s.Auth.ComparePassword(u.Password.String, res.Data.TemporaryPassword) != nil {
+					t.Errorf("user = %+v, want the temporary password and the flag set", u)
+				}
+			},
+		},
+		{Name: "reset password of missing user", Method: http.MethodPost, Path: "/admin/users/99/reset-password", Token: token, Want: http.StatusNotFound, WantCode: wire.CodeNotFound},
+		{Name: "reset password as non-admin", Method: http.MethodPost, Path: "/admin/users/1/reset-password", Token: nonAdmin, Want: http.StatusForbidden},
+
+		{
+			Name: "impersonate", Method: http.MethodPost, Path: "/admin/users/1/impersonate", Token: token, Want: http.StatusOK,
+			Check: func(t *testing.T, s *apitest.Server, rec *httptest.ResponseRecorder) {
+				if c := session(t, s, rec); c.UserID != userID || c.ImpersonatorID != adminID {
+					t.Errorf("claims = %+v, want user %d impersonated by %d", c, userID, adminID)
+				}
+			},
+		},
+		{Name: "impersonate an admin", Method: http.MethodPost, Path: "/admin/users/2/impersonate", Token: token, Want: http.StatusForbidden, WantCode: wire.CodeForbidden},
+		{Name: "impersonate a disabled user", Method: http.MethodPost, Path: "/admin/users/3/impersonate", Token: token, Want: http.StatusForbidden, WantCode: wire.CodeAccountDisabled},
+		{Name: "impersonate missing user", Method: http.MethodPost, Path: "/admin/users/99/impersonate", Token: token, Want: http.StatusNotFound, WantCode: wire.CodeNotFound},
+		{Name: "impersonate as non-admin", Method: http.MethodPost, Path: "/admin/users/1/impersonate", Token: nonAdmin, Want: http.StatusForbidden},
+	})
+}
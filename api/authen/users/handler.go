@@ -1,47 +1,340 @@
 package users
 
 import (
+	"database/sql"
 	"net/http"
 	"ticket/pkg/apikit"
 	"ticket/pkg/auth"
 	"ticket/pkg/db"
+	"ticket/pkg/store"
 	"ticket/pkg/util"
+	"ticket/pkg/wire"
 
+	"github.com/guregu/null/v5"
 	"github.com/labstack/echo/v4"
 )
 
 type Handler struct {
-	DB *db.Queries
+	Store store.Store
+	Auth  *auth.Auth
 }
 
 func New(api *apikit.API) *Handler {
 	return &Handler{
-		DB: db.New(api.DB),
+		Store: api.Store,
+		Auth:  auth.New(api.Config),
 	}
 }
 
+type UserResponse struct {
+	ID                    uint64  `json:"id"`
+	Email                 string  `json:"email"`
+	Name                  string  `json:"name"`
+	Lastname              string  `json:"lastname"`
+	IsAdmin               bool    `json:"is_admin"`
+	PasswordResetRequired bool    `json:"password_reset_required"`
+	DisabledAt            *string `json:"disabled_at,omitempty"`
+	CreatedAt             string  `json:"created_at"`
+}
+
+func NewUserResponse(user db.User) UserResponse {
+	res := UserResponse{
+		ID:                    user.ID,
+		Email:                 user.Email.String,
+		Name:                  user.Name.String,
+		Lastname:              user.Lastname.String,
+		IsAdmin:               user.IsAdmin,
+		PasswordResetRequired: user.PasswordResetRequired,
+		CreatedAt:             user.CreatedAt.Time.Format(util.TimeFormat),
+	}
+
+	if user.DisabledAt.Valid {
+		disabledAt := user.DisabledAt.Time.Format(util.TimeFormat)
+		res.DisabledAt = &disabledAt
+	}
+
+	return res
+}
+
 func (h *Handler) GetMe(c echo.Context) error {
 	claims := c.Get("claims").(*auth.Claims)
-	user, err := h.DB.FindUserByID(c.Request().Context(), claims.UserID)
+	user, err := h.Store.FindUserByID(c.Request().Context(), claims.UserID)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		if err == sql.ErrNoRows {
+			return echo.NewHTTPError(http.StatusNotFound, "user not found")
+		}
+
+		return err
+	}
+
+	return c.JSON(http.StatusOK, NewUserResponse(user))
+}
+
+// UpdateMeRequest changes only the fields that are set.
+type UpdateMeRequest struct {
+	Name     *string `json:"name" validate:"omitempty,min=3,max=100"`
+	Lastname *string `json:"lastname" validate:"omitempty,min=3,max=100"`
+}
+
+func (h *Handler) UpdateMe(c echo.Context) error {
+	claims := c.Get("claims").(*auth.Claims)
+
+	var body UpdateMeRequest
+
+	err := c.Bind(&body)
+	if err != nil {
+		return err
+	}
+
+	err = c.Validate(&body)
+	if err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+
+	user, err := h.Store.FindUserByID(ctx, claims.UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return echo.NewHTTPError(http.StatusNotFound, "user not found")
+		}
+
+		return err
+	}
+
+	isChanged := false
+	userParams := db.UpdateUserParams{
+		ID:       user.ID,
+		Name:     user.Name,
+		Lastname: user.Lastname,
+		Email:    user.Email,
+		Password: user.Password,
+	}
+
+	if body.Name != nil {
+		isChanged = true
+		userParams.Name = null.NewString(*body.Name, true)
 	}
 
-	if user.ID == 0 {
-		return echo.NewHTTPError(http.StatusNotFound, "user not found")
+	if body.Lastname != nil {
+		isChanged = true
+		userParams.Lastname = null.NewString(*body.Lastname, true)
 	}
 
-	return c.JSON(http.StatusOK, struct {
-		ID        uint64 `json:"id"`
-		Email     string `json:"email"`
-		Name      string `json:"name"`
-		Lastname  string `json:"lastname"`
-		CreatedAt string `json:"created_at"`
-	}{
-		ID:        user.ID,
-		Email:     user.Email.String,
-		Name:      user.Name.String,
-		Lastname:  user.Lastname.String,
-		CreatedAt: user.CreatedAt.Time.Format(util.TimeFormat),
+	if isChanged {
+		err = h.Store.UpdateUser(ctx, userParams)
+		if err != nil {
+			return err
+		}
+
+		user, err = h.Store.FindUserByID(ctx, claims.UserID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return c.JSON(http.StatusOK, NewUserResponse(user))
+}
+
+type ChangeEmailRequest struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+}
+
+func (h *Handler) ChangeEmail(c echo.Context) error {
+	claims := c.Get("claims").(*auth.Claims)
+
+	var body ChangeEmailRequest
+
+	err := c.Bind(&body)
+	if err != nil {
+		return err
+	}
+
+	err = c.Validate(&body)
+	if err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+
+	user, err := h.Store.FindUserByID(ctx, claims.UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return echo.NewHTTPError(http.StatusNotFound, "user not found")
+		}
+
+		return err
+	}
+
+	err = h.Auth.ComparePassword(user.Password.String, body.Password)
+	if err != nil {
+		return apikit.NewError(http.StatusUnauthorized, wire.CodeInvalidCredentials, "invalid password")
+	}
+
+	if user.Email.String == body.Email {
+		return c.JSON(http.StatusOK, NewUserResponse(user))
+	}
+
+	err = store.WithTx(ctx, h.Store, func(qtx store.Querier) error {
+		existing, err := qtx.FindUserByEmail(ctx, null.NewString(body.Email, true))
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+
+		if existing.ID != 0 {
+			return apikit.NewError(http.StatusConflict, wire.CodeEmailTaken, "email already exists")
+		}
+
+		err = qtx.UpdateUser(ctx, db.UpdateUserParams{
+			ID:       user.ID,
+			Name:     user.Name,
+			Lastname: user.Lastname,
+			Email:    null.NewString(body.Email, true),
+			Password: user.Password,
+		})
+		if err != nil {
+			return err
+		}
+
+		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	user, err = h.Store.FindUserByID(ctx, claims.UserID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, NewUserResponse(user))
+}
+
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required,min=8,max=32"`
+}
+
+func (h *Handler) ChangePassword(c echo.Context) error {
+	claims := c.Get("claims").(*auth.Claims)
+
+	var body ChangePasswordRequest
+
+	err := c.Bind(&body)
+	if err != nil {
+		return err
+	}
+
+	err = c.Validate(&body)
+	if err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+
+	user, err := h.Store.FindUserByID(ctx, claims.UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return echo.NewHTTPError(http.StatusNotFound, "user not found")
+		}
+
+		return err
+	}
+
+	err = h.Auth.ComparePassword(user.Password.String, body.OldPassword)
+	if err != nil {
+		return apikit.NewError(http.StatusUnauthorized, wire.CodeInvalidCredentials, "invalid password")
+	}
+
+	hash, err := h.Auth.HashPassword(body.NewPassword)
+	if err != nil {
+		return err
+	}
+
+	err = h.Store.UpdateUserPassword(ctx, db.UpdateUserPasswordParams{
+		ID:                    user.ID,
+		Password:              null.NewString(hash, true),
+		PasswordResetRequired: false,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, wire.GenericResponse[any]{
+		Error:   false,
+		Message: "password changed",
+	})
+}
+
+// DeleteMeRequest carries the current password, which accounts that only
+// sign in through OpenID Connect do not have.
+type DeleteMeRequest struct {
+	Password string `json:"password"`
+}
+
+// DeleteMe removes the account together with every board it owns, including
+// the boards' statuses and tickets. Accounts that have a password must confirm
+// it; accounts created through single sign-on have none to confirm.
+func (h *Handler) DeleteMe(c echo.Context) error {
+	claims := c.Get("claims").(*auth.Claims)
+
+	var body DeleteMeRequest
+
+	err := c.Bind(&body)
+	if err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+
+	user, err := h.Store.FindUserByID(ctx, claims.UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return echo.NewHTTPError(http.StatusNotFound, "user not found")
+		}
+
+		return err
+	}
+
+	if user.Password.Valid {
+		err = h.Auth.ComparePassword(user.Password.String, body.Password)
+		if err != nil {
+			return apikit.NewError(http.StatusUnauthorized, wire.CodeInvalidCredentials, "invalid password")
+		}
+	}
+
+	err = store.WithTx(ctx, h.Store, func(qtx store.Querier) error {
+		err := qtx.DeleteTicketsByUserID(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+
+		err = qtx.DeleteStatusesByUserID(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+
+		err = qtx.DeleteBoardsByUserID(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+
+		err = qtx.DeleteUserIdentitiesByUserID(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+
+		err = qtx.DeleteUser(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
 }
@@ -0,0 +1,87 @@
+package gateway
+
+import (
+	"sync"
+	"ticket/config"
+	"time"
+)
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+// breaker opens after FailureThreshold consecutive failures and fails fast
+// until OpenTimeout has passed. Then a single probe request is let through;
+// its outcome closes the breaker again or restarts the timeout.
+type breaker struct {
+	mu       sync.Mutex
+	config   config.CircuitBreaker
+	state    breakerState
+	failures int
+	openedAt time.Time
+	// now is the clock, replaced in tests.
+	now func() time.Time
+}
+
+func newBreaker(cf config.CircuitBreaker) *breaker {
+	return &breaker{config: cf, now: time.Now}
+}
+
+func (b *breaker) Allow() bool {
+	if b.config.FailureThreshold <= 0 {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if b.now().Sub(b.openedAt) < b.config.OpenTimeout {
+			return false
+		}
+
+		b.state = breakerHalfOpen
+
+		return true
+	case breakerHalfOpen:
+		return false
+	default:
+		return true
+	}
+}
+
+func (b *breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = breakerClosed
+	b.failures = 0
+}
+
+func (b *breaker) Failure() {
+	if b.config.FailureThreshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= b.config.FailureThreshold {
+		b.state = breakerOpen
+		b.openedAt = b.now()
+	}
+}
+
+// Open reports whether the breaker is open or half-open.
+func (b *breaker) Open() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state != breakerClosed
+}
@@ -0,0 +1,69 @@
+package gateway
+
+import (
+	"testing"
+	"ticket/config"
+	"time"
+)
+
+func TestBreaker(t *testing.T) {
+	now := time.Now()
+	b := newBreaker(config.CircuitBreaker{FailureThreshold: 2, OpenTimeout: time.Minute})
+	b.now = func() time.Time { return now }
+
+	b.Failure()
+	if !b.Allow() || b.Open() {
+		t.Fatal("open after one failure, want closed until the threshold")
+	}
+
+	b.Success()
+	b.Failure()
+	if !b.Allow() {
+		t.Fatal("open after a success reset the count, want closed")
+	}
+
+	b.Failure()
+	if b.Allow() || !b.Open() {
+		t.Fatal("closed after two failures in a row, want open")
+	}
+
+	now = now.Add(59 * time.Second)
+	if b.Allow() {
+		t.Fatal("allowed before the open timeout")
+	}
+
+	now = now.Add(time.Second)
+	if !b.Allow() {
+		t.Fatal("probe not allowed after the open timeout, want half-open")
+	}
+	if b.Allow() || !b.Open() {
+		t.Fatal("second request allowed while half-open, want only one probe")
+	}
+
+	b.Failure()
+	if b.Allow() {
+		t.Fatal("allowed after the probe failed, want open again")
+	}
+
+	now = now.Add(time.Minute)
+	if !b.Allow() {
+		t.Fatal("probe not allowed after the second open timeout")
+	}
+
+	b.Success()
+	if !b.Allow() || !b.Allow() || b.Open() {
+		t.Fatal("not closed after the probe succeeded")
+	}
+}
+
+func TestBreakerDisabled(t *testing.T) {
+	b := newBreaker(config.CircuitBreaker{})
+
+	for range 10 {
+		b.Failure()
+	}
+
+	if !b.Allow() || b.Open() {
+		t.Error("breaker without a threshold opened")
+	}
+}
@@ -0,0 +1,29 @@
+package gateway
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	upstreamRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "gateway_upstream_requests_total",
+		Help: "Attempts sent to upstreams, by result. Status is \"error\" when no response came back.",
+	}, []string{"route", "upstream", "status"})
+
+	upstreamDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "gateway_upstream_request_duration_seconds",
+		Help:    "Time until an upstream returned response headers.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route", "upstream"})
+
+	upstreamHealthy = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "gateway_upstream_healthy",
+		Help: "1 when the upstream passes its health check.",
+	}, []string{"upstream"})
+
+	upstreamBreakerOpen = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "gateway_upstream_circuit_open",
+		Help: "1 while the upstream's circuit breaker is open or half-open.",
+	}, []string{"upstream"})
+)
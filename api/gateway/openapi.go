@@ -0,0 +1,74 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"ticket/pkg/apikit"
+	"ticket/pkg/openapi"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const specTimeout = 5 * time.Second
+
+// Spec serves the OpenAPI documents of the routes' upstreams as one, with the
+// paths of routes that strip their prefix put back under it. Routes whose
+// upstreams fail to answer are left out, so the docs stay up while a service
+// is down.
+func (p *Proxy) Spec(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(c.Request().Context(), specTimeout)
+	defer cancel()
+
+	doc := openapi.New("Gateway", apikit.SpecVersion)
+	for _, r := range p.routes {
+		rd, err := r.spec(ctx)
+		if err != nil {
+			apikit.Logger(c).Warn("failed to load OpenAPI document", "route", r.Prefix, "error", err)
+			continue
+		}
+
+		prefix := ""
+		if r.StripPrefix {
+			prefix = r.Prefix
+		}
+
+		doc.Merge(prefix, rd)
+	}
+
+	return c.JSON(http.StatusOK, doc)
+}
+
+// spec fetches the document of one of the route's upstreams, through the
+// route so unhealthy upstreams and open circuits are skipped.
+func (r *Route) spec(ctx context.Context) (*openapi.Document, error) {
+	path := "/openapi.json"
+	if !r.StripPrefix {
+		path = r.Prefix + path
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := r.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("upstream answered %s", res.Status)
+	}
+
+	var doc openapi.Document
+	err = json.NewDecoder(res.Body).Decode(&doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &doc, nil
+}
@@ -0,0 +1,158 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"ticket/config"
+	"ticket/pkg/apikit"
+	"ticket/pkg/auth"
+
+	"github.com/labstack/echo/v4"
+)
+
+type Proxy struct {
+	routes      []*Route
+	healthCheck config.HealthCheck
+	auth        *auth.Auth
+	logger      *slog.Logger
+}
+
+func NewProxy(routes []config.GatewayRoute, hc config.HealthCheck, cb config.CircuitBreaker, a *auth.Auth, logger *slog.Logger) (*Proxy, error) {
+	p := &Proxy{
+		routes:      make([]*Route, 0, len(routes)),
+		healthCheck: hc,
+		auth:        a,
+		logger:      logger,
+	}
+
+	for _, rc := range routes {
+		route, err := newRoute(rc, cb, logger)
+		if err != nil {
+			return nil, err
+		}
+
+		p.routes = append(p.routes, route)
+	}
+
+	sort.SliceStable(p.routes, func(i, j int) bool {
+		return len(p.routes[i].Prefix) > len(p.routes[j].Prefix)
+	})
+
+	return p, nil
+}
+
+// StartHealthChecks polls every upstream until ctx is done. Without a
+// configured interval all upstreams are assumed healthy.
+func (p *Proxy) StartHealthChecks(ctx context.Context) {
+	if p.healthCheck.Interval <= 0 {
+		return
+	}
+
+	var upstreams []*Upstream
+	for _, r := range p.routes {
+		upstreams = append(upstreams, r.upstreams...)
+	}
+
+	go checkHealthEvery(ctx, upstreams, p.healthCheck, p.logger)
+}
+
+// Ready fails when every upstream of a route fails its health check. Open
+// circuit breakers are left out: they only close again after a request is let
+// through, which would not happen once the gateway is taken out of rotation.
+func (p *Proxy) Ready(context.Context) error {
+	var down []string
+	for _, r := range p.routes {
+		healthy := false
+		for _, u := range r.upstreams {
+			healthy = healthy || u.Healthy()
+		}
+
+		if !healthy {
+			down = append(down, r.Prefix)
+		}
+	}
+
+	if len(down) > 0 {
+		return fmt.Errorf("no healthy upstream for %s", strings.Join(down, ", "))
+	}
+
+	return nil
+}
+
+func (p *Proxy) match(path string) *Route {
+	for _, r := range p.routes {
+		if r.matches(path) {
+			return r
+		}
+	}
+
+	return nil
+}
+
+func (p *Proxy) Handle(c echo.Context) error {
+	req := c.Request()
+
+	route := p.match(req.URL.Path)
+	if route == nil {
+		return echo.ErrNotFound
+	}
+
+	// Only the gateway may set the identity header; never pass on one sent by
+	// the client.
+	req.Header.Del(auth.IdentityHeader)
+
+	claims := p.claims(c)
+	if claims == nil && route.requiresAuth(req.URL.Path) {
+		return echo.ErrUnauthorized
+	}
+
+	if claims != nil {
+		identity, err := p.auth.SignIdentity(claims)
+		if err != nil {
+			return err
+		}
+
+		req.Header.Set(auth.IdentityHeader, identity)
+	}
+
+	route.proxy.ServeHTTP(c.Response(), req)
+
+	return nil
+}
+
+// claims returns the claims of a valid bearer token, or nil when the request
+// has no token or an invalid one. The result is kept on the context so the
+// rate limiter and the proxy parse the token only once.
+func (p *Proxy) claims(c echo.Context) *auth.Claims {
+	if claims, ok := c.Get("claims").(*auth.Claims); ok {
+		return claims
+	}
+
+	token, ok := strings.CutPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+	if !ok {
+		return nil
+	}
+
+	claims, err := p.auth.ParseToken(token)
+	if err != nil {
+		return nil
+	}
+
+	c.Set("claims", claims)
+	c.Set(apikit.UserIDKey, claims.UserID)
+
+	return claims
+}
+
+// RateLimitKey limits authenticated callers per user and everyone else per
+// client IP.
+func (p *Proxy) RateLimitKey(c echo.Context) string {
+	if claims := p.claims(c); claims != nil {
+		return fmt.Sprintf("user:%d", claims.UserID)
+	}
+
+	return apikit.RateLimitKeyByIP(c)
+}
@@ -0,0 +1,157 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"ticket/config"
+	"ticket/pkg/apikit"
+	"ticket/pkg/apikit/apitest"
+	"ticket/pkg/auth"
+	"ticket/pkg/wire"
+)
+
+// identityBackend records the identity header of the last request it got.
+type identityBackend struct {
+	*httptest.Server
+	mu       sync.Mutex
+	identity string
+	called   bool
+}
+
+func newIdentityBackend(t *testing.T) *identityBackend {
+	t.Helper()
+
+	b := &identityBackend{}
+	b.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		b.identity = r.Header.Get(auth.IdentityHeader)
+		b.called = true
+	}))
+	t.Cleanup(b.Close)
+
+	return b
+}
+
+func (b *identityBackend) last() (identity string, called bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.identity, b.called
+}
+
+// newGateway mounts the gateway with routes pointing at upstream.
+func newGateway(t *testing.T, routes ...config.GatewayRoute) *apitest.Server {
+	t.Helper()
+
+	cf := apitest.Config()
+	cf.IdentitySecret = "identity-secret"
+	cf.Services.Gateway.Routes = routes
+
+	return apitest.New(t, []apikit.Router{Router}, apikit.WithGlobal(cf))
+}
+
+func TestIdentityHeader(t *testing.T) {
+	b := newIdentityBackend(t)
+	s := newGateway(t, config.GatewayRoute{Prefix: "/svc", Upstreams: []string{b.URL}, StripPrefix: true})
+
+	send := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/svc/boards", nil)
+		req.Header.Set(auth.IdentityHeader, "spoofed")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		return s.Serve(req)
+	}
+
+	rec := send(s.Token(t, 7, auth.ScopePasswordReset))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+
+	identity, _ := b.last()
+	claims, err := s.Auth.ParseIdentity(identity)
+	if err != nil {
+		t.Fatalf("forwarded identity %q: %v", identity, err)
+	}
+	if claims.UserID != 7 || !claims.HasScope(auth.ScopePasswordReset) {
+		t.Errorf("identity = %+v, want user 7 with the token's scopes", claims)
+	}
+
+	for name, token := range map[string]string{"anonymous": "", "invalid token": "not-a-token"} {
+		t.Run(name, func(t *testing.T) {
+			rec := send(token)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+			}
+
+			if identity, _ := b.last(); identity != "" {
+				t.Errorf("upstream got identity %q, want the spoofed header stripped", identity)
+			}
+		})
+	}
+}
+
+func TestAnonymousPaths(t *testing.T) {
+	tests := []struct {
+		name  string
+		path  string
+		token bool
+		want  int
+	}{
+		{name: "protected without token", path: "/authen-service/users/me", want: http.StatusUnauthorized},
+		{name: "protected with token", path: "/authen-service/users/me", token: true, want: http.StatusOK},
+		{name: "route root without token", path: "/authen-service", want: http.StatusUnauthorized},
+		{name: "allowlisted path", path: "/authen-service/sign-in", want: http.StatusOK},
+		{name: "below an exact allowlisted path", path: "/authen-service/sign-in/other", want: http.StatusUnauthorized},
+		{name: "allowlisted wildcard", path: "/authen-service/oidc/company/login", want: http.StatusOK},
+		{name: "wildcard root", path: "/authen-service/oidc", want: http.StatusOK},
+		{name: "wildcard lookalike", path: "/authen-service/oidcx", want: http.StatusUnauthorized},
+		{name: "route without auth", path: "/public/anything", want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newIdentityBackend(t)
+			s := newGateway(t,
+				config.GatewayRoute{
+					Prefix:         "/authen-service",
+					Upstreams:      []string{b.URL},
+					StripPrefix:    true,
+					AuthRequired:   true,
+					AnonymousPaths: []string{"/sign-in", "/oidc/*"},
+				},
+				config.GatewayRoute{Prefix: "/public", Upstreams: []string{b.URL}},
+			)
+
+			var token string
+			if tt.token {
+				token = s.Token(t, 1)
+			}
+
+			rec := s.Do(t, http.MethodGet, tt.path, token, nil)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.want, rec.Body)
+			}
+
+			identity, called := b.last()
+			if called != (tt.want == http.StatusOK) {
+				t.Errorf("upstream called = %t, want it called only when the gateway lets the request through", called)
+			}
+
+			if tt.want == http.StatusUnauthorized {
+				if code := apitest.Code(t, rec); code != wire.CodeUnauthorized {
+					t.Errorf("code = %q, want %q", code, wire.CodeUnauthorized)
+				}
+			}
+
+			if tt.token && identity == "" {
+				t.Error("upstream got no identity for an authenticated request")
+			}
+		})
+	}
+}
@@ -0,0 +1,262 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"ticket/config"
+	"ticket/pkg/wire"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+var errNoUpstream = errors.New("no healthy upstream")
+
+// UpstreamError is the data of the gateway's own error responses, which add
+// the route to the usual error envelope.
+type UpstreamError struct {
+	wire.ErrorResponse
+	Route string `json:"route"`
+}
+
+type Route struct {
+	config.GatewayRoute
+	upstreams []*Upstream
+	next      atomic.Uint64
+	proxy     *httputil.ReverseProxy
+	transport http.RoundTripper
+	logger    *slog.Logger
+}
+
+// newRoute builds the reverse proxy for one route. httputil.ReverseProxy
+// streams both bodies and drops hop-by-hop headers; the route itself is the
+// proxy's transport so it can choose, retry and trip upstreams per attempt.
+func newRoute(rc config.GatewayRoute, cb config.CircuitBreaker, logger *slog.Logger) (*Route, error) {
+	r := &Route{GatewayRoute: rc, logger: logger.With("route", rc.Prefix)}
+
+	for _, rawURL := range rc.Upstreams {
+		u, err := newUpstream(rawURL, cb)
+		if err != nil {
+			return nil, err
+		}
+
+		r.upstreams = append(r.upstreams, u)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if rc.ConnectTimeout > 0 {
+		transport.DialContext = (&net.Dialer{
+			Timeout:   rc.ConnectTimeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext
+	}
+
+	// otelhttp injects traceparent so upstream spans join the gateway's trace.
+	r.transport = otelhttp.NewTransport(transport)
+	r.proxy = &httputil.ReverseProxy{
+		Rewrite:      r.rewrite,
+		Transport:    r,
+		ErrorHandler: r.handleError,
+	}
+
+	return r, nil
+}
+
+func (r *Route) rewrite(pr *httputil.ProxyRequest) {
+	if r.StripPrefix {
+		pr.Out.URL.Path = strings.TrimPrefix(pr.In.URL.Path, r.Prefix)
+		pr.Out.URL.RawPath = strings.TrimPrefix(pr.In.URL.RawPath, r.Prefix)
+	}
+
+	pr.SetXForwarded()
+}
+
+// pick returns the next usable upstream in round-robin order, or nil when
+// every upstream is failing its health check or has an open circuit.
+func (r *Route) pick() *Upstream {
+	n := uint64(len(r.upstreams))
+	start := r.next.Add(1)
+	for i := uint64(0); i < n; i++ {
+		u := r.upstreams[(start+i)%n]
+		if u.Healthy() && u.breaker.Allow() {
+			return u
+		}
+	}
+
+	return nil
+}
+
+// RoundTrip sends req to an upstream. Idempotent requests without a body are
+// retried on the next upstream when the connection itself failed, since the
+// upstream cannot have seen them.
+func (r *Route) RoundTrip(req *http.Request) (*http.Response, error) {
+	attempts := 1
+	if isIdempotent(req.Method) && (req.Body == nil || req.Body == http.NoBody) {
+		attempts += r.Retries
+	}
+
+	var err error
+	for i := 0; i < attempts; i++ {
+		u := r.pick()
+		if u == nil {
+			break
+		}
+
+		var res *http.Response
+		res, err = r.attempt(req, u)
+		if err == nil {
+			return res, nil
+		}
+
+		if !isConnectionError(err) || req.Context().Err() != nil {
+			return nil, err
+		}
+	}
+
+	if err == nil {
+		err = errNoUpstream
+	}
+
+	return nil, err
+}
+
+func (r *Route) attempt(req *http.Request, u *Upstream) (*http.Response, error) {
+	ctx, cancel := req.Context(), context.CancelFunc(func() {})
+	if r.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+	}
+
+	out := req.Clone(ctx)
+	u.target(out.URL)
+	// Send the upstream's own Host, as SetURL would, rather than the gateway's.
+	out.Host = ""
+
+	upstream := u.URL.String()
+	start := time.Now()
+	res, err := r.transport.RoundTrip(out)
+	upstreamDuration.WithLabelValues(r.Prefix, upstream).Observe(time.Since(start).Seconds())
+	if err != nil {
+		cancel()
+		u.breaker.Failure()
+		u.observeBreaker()
+		upstreamRequests.WithLabelValues(r.Prefix, upstream, "error").Inc()
+
+		return nil, err
+	}
+
+	switch res.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		u.breaker.Failure()
+	default:
+		u.breaker.Success()
+	}
+	u.observeBreaker()
+	upstreamRequests.WithLabelValues(r.Prefix, upstream, strconv.Itoa(res.StatusCode)).Inc()
+
+	res.Body = &cancelOnClose{ReadCloser: res.Body, cancel: cancel}
+
+	return res, nil
+}
+
+func (r *Route) handleError(w http.ResponseWriter, req *http.Request, err error) {
+	status, message := http.StatusBadGateway, "upstream request failed"
+	switch {
+	case errors.Is(err, errNoUpstream):
+		status, message = http.StatusServiceUnavailable, "upstream unavailable"
+	case isTimeout(err):
+		status, message = http.StatusGatewayTimeout, "upstream timed out"
+	}
+
+	requestID := req.Header.Get(echo.HeaderXRequestID)
+	r.logger.Error("proxy request failed", "path", req.URL.Path, "request_id", requestID, "error", err)
+
+	w.Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(wire.GenericResponse[UpstreamError]{
+		Error:   true,
+		Message: message,
+		Data: UpstreamError{
+			ErrorResponse: wire.ErrorResponse{Code: wire.CodeFor(status), RequestID: requestID},
+			Route:         r.Prefix,
+		},
+	})
+}
+
+func (r *Route) matches(path string) bool {
+	if !strings.HasPrefix(path, r.Prefix) {
+		return false
+	}
+
+	rest := path[len(r.Prefix):]
+
+	return rest == "" || rest[0] == '/' || strings.HasSuffix(r.Prefix, "/")
+}
+
+func (r *Route) requiresAuth(path string) bool {
+	if !r.AuthRequired {
+		return false
+	}
+
+	rest := strings.TrimPrefix(path, r.Prefix)
+	for _, anonymous := range r.AnonymousPaths {
+		if prefix, ok := strings.CutSuffix(anonymous, "/*"); ok {
+			if rest == prefix || strings.HasPrefix(rest, prefix+"/") {
+				return false
+			}
+		} else if rest == anonymous {
+			return false
+		}
+	}
+
+	return true
+}
+
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	defer c.cancel()
+
+	return c.ReadCloser.Close()
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+
+	return false
+}
+
+func isConnectionError(err error) bool {
+	var op *net.OpError
+	if errors.As(err, &op) && op.Op == "dial" {
+		return true
+	}
+
+	return errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET)
+}
+
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var ne net.Error
+
+	return errors.As(err, &ne) && ne.Timeout()
+}
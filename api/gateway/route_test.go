@@ -0,0 +1,242 @@
+package gateway
+
+import (
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"ticket/config"
+	"ticket/pkg/apikit/apitest"
+	"ticket/pkg/wire"
+	"time"
+)
+
+// backend is an upstream that counts its requests and answers with its name
+// and the path and query it received.
+type backend struct {
+	*httptest.Server
+	hits atomic.Int32
+}
+
+func newBackend(t *testing.T, name string, status int) *backend {
+	t.Helper()
+
+	u := &backend{}
+	u.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u.hits.Add(1)
+		w.WriteHeader(status)
+		io.WriteString(w, name+" "+r.URL.EscapedPath()+"?"+r.URL.RawQuery)
+	}))
+	t.Cleanup(u.Close)
+
+	return u
+}
+
+// deadURL is the address of a closed listener, so dialing it is refused.
+func deadURL(t *testing.T) string {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln.Close()
+
+	return "http://" + ln.Addr().String()
+}
+
+func testRoute(t *testing.T, rc config.GatewayRoute, cb config.CircuitBreaker) *Route {
+	t.Helper()
+
+	r, err := newRoute(rc, cb, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return r
+}
+
+func serve(r *Route, method, target string, body io.Reader) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	r.proxy.ServeHTTP(rec, httptest.NewRequest(method, target, body))
+
+	return rec
+}
+
+func TestRouteRewrite(t *testing.T) {
+	srv := newBackend(t, "backend", http.StatusOK)
+
+	tests := []struct {
+		name  string
+		route config.GatewayRoute
+		path  string
+		want  string
+	}{
+		{
+			name:  "strip prefix",
+			route: config.GatewayRoute{Prefix: "/ticket-service", StripPrefix: true},
+			path:  "/ticket-service/boards/1?sort=asc",
+			want:  "/boards/1?sort=asc",
+		},
+		{
+			name:  "strip the whole path",
+			route: config.GatewayRoute{Prefix: "/ticket-service", StripPrefix: true},
+			path:  "/ticket-service",
+			want:  "/?",
+		},
+		{
+			name:  "keep prefix",
+			route: config.GatewayRoute{Prefix: "/api"},
+			path:  "/api/things",
+			want:  "/api/things?",
+		},
+		{
+			name:  "keep escaped slashes",
+			route: config.GatewayRoute{Prefix: "/ticket-service", StripPrefix: true},
+			path:  "/ticket-service/files/a%2Fb",
+			want:  "/files/a%2Fb?",
+		},
+		{
+			name:  "upstream base path",
+			route: config.GatewayRoute{Prefix: "/ticket-service", StripPrefix: true, Upstreams: []string{srv.URL + "/v1"}},
+			path:  "/ticket-service/boards",
+			want:  "/v1/boards?",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.route.Upstreams == nil {
+				tt.route.Upstreams = []string{srv.URL}
+			}
+
+			rec := serve(testRoute(t, tt.route, config.CircuitBreaker{}), http.MethodGet, tt.path, nil)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+			}
+
+			if got := strings.TrimPrefix(rec.Body.String(), "backend "); got != tt.want {
+				t.Errorf("upstream got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRouteRoundRobin(t *testing.T) {
+	a := newBackend(t, "a", http.StatusOK)
+	b := newBackend(t, "b", http.StatusOK)
+	c := newBackend(t, "c", http.StatusOK)
+	r := testRoute(t, config.GatewayRoute{Prefix: "/svc", Upstreams: []string{a.URL, b.URL, c.URL}}, config.CircuitBreaker{})
+
+	hits := func() []int32 {
+		return []int32{a.hits.Load(), b.hits.Load(), c.hits.Load()}
+	}
+
+	for range 6 {
+		serve(r, http.MethodGet, "/svc", nil)
+	}
+	if got := hits(); !slices.Equal(got, []int32{2, 2, 2}) {
+		t.Errorf("hits = %v, want each upstream twice", got)
+	}
+
+	r.upstreams[1].healthy.Store(false)
+	for range 4 {
+		serve(r, http.MethodGet, "/svc", nil)
+	}
+	if got := hits(); got[1] != 2 || got[0]+got[2] != 8 {
+		t.Errorf("hits = %v, want the unhealthy upstream skipped", got)
+	}
+
+	r.upstreams[0].healthy.Store(false)
+	r.upstreams[2].healthy.Store(false)
+	rec := serve(r, http.MethodGet, "/svc", nil)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503 with every upstream unhealthy", rec.Code)
+	}
+
+	res := apitest.Decode[wire.GenericResponse[UpstreamError]](t, rec)
+	if res.Data.Code != wire.CodeUnavailable || res.Data.Route != "/svc" {
+		t.Errorf("error = %+v, want unavailable for /svc", res.Data)
+	}
+}
+
+func TestRouteRetries(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		body    string
+		retries int
+		want    []int
+	}{
+		{name: "idempotent", method: http.MethodGet, retries: 1, want: []int{http.StatusOK, http.StatusOK}},
+		{name: "idempotent with a body", method: http.MethodPut, body: "{}", retries: 1, want: []int{http.StatusOK, http.StatusBadGateway}},
+		{name: "not idempotent", method: http.MethodPost, retries: 1, want: []int{http.StatusOK, http.StatusBadGateway}},
+		{name: "no retries", method: http.MethodGet, want: []int{http.StatusOK, http.StatusBadGateway}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			good := newBackend(t, "good", http.StatusOK)
+			r := testRoute(t, config.GatewayRoute{Prefix: "/svc", Upstreams: []string{deadURL(t), good.URL}, Retries: tt.retries}, config.CircuitBreaker{})
+
+			// Round robin starts one request on each upstream.
+			var got []int
+			for range 2 {
+				var body io.Reader
+				if tt.body != "" {
+					body = strings.NewReader(tt.body)
+				}
+
+				got = append(got, serve(r, tt.method, "/svc", body).Code)
+			}
+			slices.Sort(got)
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("statuses = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRouteBreakerFailsFast(t *testing.T) {
+	failing := newBackend(t, "failing", http.StatusServiceUnavailable)
+	r := testRoute(t, config.GatewayRoute{Prefix: "/svc", Upstreams: []string{failing.URL}}, config.CircuitBreaker{FailureThreshold: 2, OpenTimeout: time.Minute})
+
+	for range 2 {
+		rec := serve(r, http.MethodGet, "/svc", nil)
+		if rec.Code != http.StatusServiceUnavailable || !strings.HasPrefix(rec.Body.String(), "failing") {
+			t.Fatalf("status = %d, body = %s, want the upstream's own 503", rec.Code, rec.Body)
+		}
+	}
+
+	rec := serve(r, http.MethodGet, "/svc", nil)
+	if code := apitest.Code(t, rec); rec.Code != http.StatusServiceUnavailable || code != wire.CodeUnavailable {
+		t.Fatalf("status = %d, code = %q, want the gateway's 503 once the breaker opens", rec.Code, code)
+	}
+
+	if hits := failing.hits.Load(); hits != 2 {
+		t.Errorf("upstream hit %d times, want 2", hits)
+	}
+}
+
+func TestRouteTimeout(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer slow.Close()
+
+	r := testRoute(t, config.GatewayRoute{Prefix: "/svc", Upstreams: []string{slow.URL}, Timeout: 20 * time.Millisecond}, config.CircuitBreaker{})
+
+	rec := serve(r, http.MethodGet, "/svc", nil)
+	if code := apitest.Code(t, rec); rec.Code != http.StatusGatewayTimeout || code != wire.CodeGatewayTimeout {
+		t.Errorf("status = %d, code = %q, want 504", rec.Code, code)
+	}
+}
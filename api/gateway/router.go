@@ -0,0 +1,52 @@
+package gateway
+
+import (
+	"context"
+	"os"
+	"ticket/config"
+	"ticket/pkg/apikit"
+	"ticket/pkg/auth"
+	"ticket/pkg/openapi"
+)
+
+func Router(api *apikit.API) {
+	cf := api.Config.GLobal().Services.Gateway
+
+	p, err := NewProxy(cf.Routes, cf.HealthCheck, cf.CircuitBreaker, auth.New(api.Config), api.Logger)
+	if err != nil {
+		api.Logger.Error("invalid gateway route", "error", err)
+		os.Exit(1)
+	}
+
+	p.StartHealthChecks(context.Background())
+	api.AddReadyCheck("upstreams", p.Ready)
+
+	api.Use(apikit.RateLimiter(newRateLimitConfig(cf.RateLimit, p)))
+
+	api.App.GET("/openapi.json", p.Spec)
+	api.App.GET("/docs", openapi.Docs)
+	api.App.Any("/*", p.Handle)
+}
+
+func newRateLimitConfig(cf config.RateLimit, p *Proxy) apikit.RateLimitConfig {
+	rl := apikit.RateLimitConfig{
+		Limit: apikit.Limit{
+			RequestsPerMinute: cf.RequestsPerMinute,
+			Burst:             cf.Burst,
+		},
+		KeyFunc: p.RateLimitKey,
+	}
+
+	for _, r := range cf.Routes {
+		rl.Routes = append(rl.Routes, apikit.RouteLimit{
+			Method: r.Method,
+			Path:   r.Path,
+			Limit: apikit.Limit{
+				RequestsPerMinute: r.RequestsPerMinute,
+				Burst:             r.Burst,
+			},
+		})
+	}
+
+	return rl
+}
@@ -0,0 +1,101 @@
+package gateway
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"ticket/config"
+	"time"
+)
+
+type Upstream struct {
+	URL     *url.URL
+	healthy atomic.Bool
+	breaker *breaker
+}
+
+func newUpstream(rawURL string, cb config.CircuitBreaker) (*Upstream, error) {
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	u := &Upstream{
+		URL:     target,
+		breaker: newBreaker(cb),
+	}
+	u.healthy.Store(true)
+	upstreamHealthy.WithLabelValues(target.String()).Set(1)
+	u.observeBreaker()
+
+	return u, nil
+}
+
+func (u *Upstream) Healthy() bool {
+	return u.healthy.Load()
+}
+
+// target points out at this upstream, keeping the already rewritten path.
+func (u *Upstream) target(out *url.URL) {
+	out.Scheme = u.URL.Scheme
+	out.Host = u.URL.Host
+	if u.URL.Path != "" && u.URL.Path != "/" {
+		out.Path = u.URL.Path + out.Path
+		if out.RawPath != "" {
+			out.RawPath = u.URL.EscapedPath() + out.RawPath
+		}
+	}
+}
+
+func (u *Upstream) check(ctx context.Context, client *http.Client, hc config.HealthCheck, logger *slog.Logger) {
+	ctx, cancel := context.WithTimeout(ctx, hc.Timeout)
+	defer cancel()
+
+	healthy := false
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.URL.JoinPath(hc.Path).String(), nil)
+	if err == nil {
+		var res *http.Response
+		res, err = client.Do(req)
+		if err == nil {
+			res.Body.Close()
+			healthy = res.StatusCode == http.StatusOK
+		}
+	}
+
+	upstreamHealthy.WithLabelValues(u.URL.String()).Set(boolToFloat(healthy))
+	if was := u.healthy.Swap(healthy); was != healthy {
+		logger.Warn("upstream health changed", "upstream", u.URL.String(), "healthy", healthy, "error", err)
+	}
+}
+
+func checkHealthEvery(ctx context.Context, upstreams []*Upstream, hc config.HealthCheck, logger *slog.Logger) {
+	client := &http.Client{Timeout: hc.Timeout}
+	ticker := time.NewTicker(hc.Interval)
+	defer ticker.Stop()
+
+	for {
+		for _, u := range upstreams {
+			go u.check(ctx, client, hc, logger)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (u *Upstream) observeBreaker() {
+	upstreamBreakerOpen.WithLabelValues(u.URL.String()).Set(boolToFloat(u.breaker.Open()))
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+
+	return 0
+}
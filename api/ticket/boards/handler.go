@@ -3,34 +3,32 @@ package boards
 import (
 	"database/sql"
 	"net/http"
-	"strconv"
 	"ticket/pkg/apikit"
 	"ticket/pkg/auth"
 	"ticket/pkg/db"
+	"ticket/pkg/store"
 
 	"github.com/guregu/null/v5"
 	"github.com/labstack/echo/v4"
 )
 
 type Handler struct {
-	DB      *sql.DB
-	Queries *db.Queries
-	Auth    *auth.Auth
+	Store store.Store
+	Auth  *auth.Auth
 }
 
 func New(api *apikit.API) *Handler {
 	return &Handler{
-		DB:      api.DB,
-		Queries: db.New(api.DB),
-		Auth:    auth.New(api.Config),
+		Store: api.Store,
+		Auth:  auth.New(api.Config),
 	}
 }
 
 func (h *Handler) GetBoards(c echo.Context) error {
 	claims := c.Get("claims").(*auth.Claims)
-	boards, err := h.Queries.GetBoardsByUserID(c.Request().Context(), claims.UserID)
+	boards, err := h.Store.GetBoardsByUserID(c.Request().Context(), claims.UserID)
 	if err != nil && err != sql.ErrNoRows {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	return c.JSON(http.StatusOK, boards)
@@ -39,14 +37,14 @@ func (h *Handler) GetBoards(c echo.Context) error {
 func (h *Handler) GetBoardByID(c echo.Context) error {
 	claims := c.Get("claims").(*auth.Claims)
 
-	boardID, err := strconv.ParseUint(c.Param("board_id"), 10, 32)
+	boardID, err := apikit.ParamID(c, "board_id", 32)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	ctx := c.Request().Context()
 
-	board, err := h.Queries.GetBoard(ctx, db.GetBoardParams{
+	board, err := h.Store.GetBoard(ctx, db.GetBoardParams{
 		ID:     uint32(boardID),
 		UserID: claims.UserID,
 	})
@@ -55,15 +53,15 @@ func (h *Handler) GetBoardByID(c echo.Context) error {
 			return echo.NewHTTPError(http.StatusNotFound, "board not found")
 		}
 
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
-	statuses, err := h.Queries.GetStatuses(ctx, db.GetStatusesParams{
+	statuses, err := h.Store.GetStatuses(ctx, db.GetStatusesParams{
 		BoardID:            sql.NullInt32{Int32: int32(board.ID), Valid: true},
 		SortOrderDirection: null.StringFrom("asc"),
 	})
 	if err != nil && err != sql.ErrNoRows {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	var statusIDs []uint32
@@ -71,74 +69,81 @@ func (h *Handler) GetBoardByID(c echo.Context) error {
 		statusIDs = append(statusIDs, uint32(s.ID))
 	}
 
-	tickets, err := h.Queries.GetTickets(ctx, db.GetTicketsParams{
+	tickets, err := h.Store.GetTickets(ctx, db.GetTicketsParams{
 		StatusIds:          statusIDs,
 		SortOrderDirection: null.StringFrom("asc"),
 	})
 	if err != nil && err != sql.ErrNoRows {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	return c.JSON(http.StatusOK, db.NewBoardWithRelated(board, statuses, tickets))
 }
 
+// BoardRequest is the body of both CreateBoard and UpdateBoardByID.
+type BoardRequest struct {
+	Title string `json:"title" validate:"required,min=3,max=100"`
+}
+
 func (h *Handler) CreateBoard(c echo.Context) error {
 	claims := c.Get("claims").(*auth.Claims)
 
-	var body struct {
-		Title string `json:"title" validate:"required,min=3,max=100"`
-	}
+	var body BoardRequest
 
 	err := c.Bind(&body)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	err = c.Validate(&body)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	ctx := c.Request().Context()
 
-	user, err := h.Queries.FindUserByID(ctx, claims.UserID)
+	user, err := h.Store.FindUserByID(ctx, claims.UserID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return echo.NewHTTPError(http.StatusNotFound, "user not found")
 		}
 
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
-	count, err := h.Queries.CountBoardByUserID(ctx, user.ID)
+	count, err := h.Store.CountBoardByUserID(ctx, user.ID)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
-	tx, err := h.DB.Begin()
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-	}
-	defer tx.Rollback()
-	qtx := h.Queries.WithTx(tx)
+	var board db.Board
+	err = store.WithTx(ctx, h.Store, func(qtx store.Querier) error {
+		res, err := qtx.CreateBoard(ctx, db.CreateBoardParams{
+			UserID:    user.ID,
+			Title:     null.NewString(body.Title, true),
+			SortOrder: uint32(count + 1),
+		})
+		if err != nil {
+			return err
+		}
 
-	err = qtx.CreateBoard(ctx, db.CreateBoardParams{
-		UserID:    user.ID,
-		Title:     null.NewString(body.Title, true),
-		SortOrder: uint32(count + 1),
-	})
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-	}
+		boardID, err := res.LastInsertId()
+		if err != nil {
+			return err
+		}
 
-	board, err := qtx.GetLastInsertBoard(ctx)
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-	}
+		board, err = qtx.GetBoard(ctx, db.GetBoardParams{
+			ID:     uint32(boardID),
+			UserID: user.ID,
+		})
+		if err != nil {
+			return err
+		}
 
-	err = tx.Commit()
+		return nil
+	})
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	return c.JSON(http.StatusCreated, db.NewBoardWithRelated(board, []db.Status{}, []db.Ticket{}))
@@ -147,69 +152,66 @@ func (h *Handler) CreateBoard(c echo.Context) error {
 func (h *Handler) UpdateBoardByID(c echo.Context) error {
 	claims := c.Get("claims").(*auth.Claims)
 
-	boardID, err := strconv.ParseUint(c.Param("board_id"), 10, 32)
+	boardID, err := apikit.ParamID(c, "board_id", 32)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
-	var body struct {
-		Title string `json:"title" validate:"required,min=3,max=100"`
-	}
+	var body BoardRequest
 
 	err = c.Bind(&body)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	err = c.Validate(&body)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	ctx := c.Request().Context()
 
-	tx, err := h.DB.Begin()
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-	}
-	defer tx.Rollback()
-	qtx := h.Queries.WithTx(tx)
+	err = store.WithTx(ctx, h.Store, func(qtx store.Querier) error {
+		board, err := qtx.GetBoard(ctx, db.GetBoardParams{
+			ID:     uint32(boardID),
+			UserID: claims.UserID,
+		})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return echo.NewHTTPError(http.StatusNotFound, "board not found")
+			}
+
+			return err
+		}
 
-	board, err := h.Queries.GetBoard(ctx, db.GetBoardParams{
-		ID:     uint32(boardID),
-		UserID: claims.UserID,
-	})
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-	}
+		err = qtx.UpdateBoard(ctx, db.UpdateBoardParams{
+			ID:    board.ID,
+			Title: null.NewString(body.Title, true),
+		})
+		if err != nil {
+			return err
+		}
 
-	err = qtx.UpdateBoard(ctx, db.UpdateBoardParams{
-		ID:    board.ID,
-		Title: null.NewString(body.Title, true),
+		return nil
 	})
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
-	err = tx.Commit()
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-	}
-
-	board, err = h.Queries.GetBoard(ctx, db.GetBoardParams{
+	board, err := h.Store.GetBoard(ctx, db.GetBoardParams{
 		ID:     uint32(boardID),
 		UserID: claims.UserID,
 	})
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
-	statuses, err := h.Queries.GetStatuses(ctx, db.GetStatusesParams{
+	statuses, err := h.Store.GetStatuses(ctx, db.GetStatusesParams{
 		BoardID:            sql.NullInt32{Int32: int32(board.ID), Valid: true},
 		SortOrderDirection: null.StringFrom("asc"),
 	})
 	if err != nil && err != sql.ErrNoRows {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	var statusIDs []uint32
@@ -217,13 +219,13 @@ func (h *Handler) UpdateBoardByID(c echo.Context) error {
 		statusIDs = append(statusIDs, uint32(s.ID))
 	}
 
-	tickets, err := h.Queries.GetTickets(ctx, db.GetTicketsParams{
+	tickets, err := h.Store.GetTickets(ctx, db.GetTicketsParams{
 		StatusIds:          statusIDs,
 		SortOrderDirection: null.StringFrom("asc"),
 	})
 	if err != nil && err != sql.ErrNoRows {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
-	return c.JSON(http.StatusCreated, db.NewBoardWithRelated(board, statuses, tickets))
+	return c.JSON(http.StatusOK, db.NewBoardWithRelated(board, statuses, tickets))
 }
@@ -0,0 +1,142 @@
+package ticket
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"ticket/pkg/apikit"
+	"ticket/pkg/apikit/apitest"
+	"ticket/pkg/db"
+	"ticket/pkg/store"
+
+	"github.com/guregu/null/v5"
+)
+
+// TestConcurrentCreatesMySQL runs testConcurrentCreates on MySQL, where
+// LAST_INSERT_ID is kept per connection and the creates run on many
+// connections at once. It needs apitest.MySQLEnv.
+func TestConcurrentCreatesMySQL(t *testing.T) {
+	conn := apitest.MySQL(t)
+	conn.SetMaxIdleConns(callers)
+
+	testConcurrentCreates(t, apitest.New(t, []apikit.Router{Router}, apikit.WithStore(store.NewSQL(conn))))
+
+	if open := conn.Stats().OpenConnections; open < 2 {
+		t.Errorf("creates ran on %d connection, want several at once", open)
+	}
+}
+
+// TestConcurrentCreatesSQLite runs testConcurrentCreates on SQLite. SQLite
+// serializes writers, so this only checks that each create reads back its own
+// row; the MySQL test is the one that exercises concurrent inserts.
+func TestConcurrentCreatesSQLite(t *testing.T) {
+	testConcurrentCreates(t, apitest.New(t, []apikit.Router{Router}, apikit.WithStore(store.NewSQLite(apitest.SQLite(t)))))
+}
+
+// callers is how many requests of each kind testConcurrentCreates sends at once.
+const callers = 20
+
+// testConcurrentCreates creates boards, statuses and tickets from many
+// goroutines at once. Each handler reads its new row back by LastInsertId, so
+// every caller must get the row it created and no other.
+func testConcurrentCreates(t *testing.T, s *apitest.Server) {
+	ctx := context.Background()
+
+	res, err := s.Store.CreateUser(ctx, db.CreateUserParams{Email: null.StringFrom("owner@example.com")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	userID, err := res.LastInsertId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := s.Token(t, uint64(userID))
+
+	// create sends the same kind of request from every caller at once, and
+	// returns the id each one got back after checking its title.
+	create := func(kind, path string, body func(i int) map[string]any) []uint64 {
+		t.Helper()
+
+		ids := make([]uint64, callers)
+		var wg sync.WaitGroup
+		for i := 0; i < callers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				rec := s.Do(t, http.MethodPost, path, token, body(i))
+				if rec.Code != http.StatusCreated {
+					t.Errorf("%s %d: status = %d: %s", kind, i, rec.Code, rec.Body)
+
+					return
+				}
+
+				var row struct {
+					ID    uint64      `json:"id"`
+					Title null.String `json:"title"`
+				}
+				err := json.Unmarshal(rec.Body.Bytes(), &row)
+				if err != nil {
+					t.Errorf("%s %d: %v", kind, i, err)
+
+					return
+				}
+
+				if want := fmt.Sprintf("%s %d", kind, i); row.Title.String != want {
+					t.Errorf("%s %d: got back %q (id %d), want %q", kind, i, row.Title.String, row.ID, want)
+				}
+				ids[i] = row.ID
+			}()
+		}
+		wg.Wait()
+
+		seen := map[uint64]int{}
+		for i, id := range ids {
+			if other, ok := seen[id]; ok && id != 0 {
+				t.Errorf("%s %d and %d both got id %d", kind, other, i, id)
+			}
+			seen[id] = i
+		}
+
+		return ids
+	}
+
+	titled := func(kind string) func(i int) map[string]any {
+		return func(i int) map[string]any {
+			return map[string]any{"title": fmt.Sprintf("%s %d", kind, i)}
+		}
+	}
+
+	boards := create("board", "/boards", titled("board"))
+	statuses := create("status", fmt.Sprintf("/boards/%d/statuses", boards[0]), titled("status"))
+	tickets := create("ticket", fmt.Sprintf("/boards/%d/statuses/%d/tickets", boards[0], statuses[0]), func(i int) map[string]any {
+		return map[string]any{"title": fmt.Sprintf("ticket %d", i), "description": "description", "contact": "contact"}
+	})
+
+	// The ids must also point at those rows in the database.
+	for i, id := range boards {
+		b, err := s.Store.GetBoard(ctx, db.GetBoardParams{ID: uint32(id), UserID: uint64(userID)})
+		if err != nil || b.Title.String != fmt.Sprintf("board %d", i) {
+			t.Errorf("board %d is %+v, %v", id, b, err)
+		}
+	}
+
+	for i, id := range statuses {
+		st, err := s.Store.GetStatus(ctx, db.GetStatusParams{ID: sql.NullInt32{Int32: int32(id), Valid: true}})
+		if err != nil || st.Title.String != fmt.Sprintf("status %d", i) {
+			t.Errorf("status %d is %+v, %v", id, st, err)
+		}
+	}
+
+	for i, id := range tickets {
+		tk, err := s.Store.GetTicketByID(ctx, id)
+		if err != nil || tk.Title.String != fmt.Sprintf("ticket %d", i) {
+			t.Errorf("ticket %d is %+v, %v", id, tk, err)
+		}
+	}
+}
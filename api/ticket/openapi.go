@@ -0,0 +1,59 @@
+package ticket
+
+import (
+	"net/http"
+	"ticket/api/ticket/boards"
+	"ticket/api/ticket/statuses"
+	"ticket/api/ticket/tickets"
+	"ticket/pkg/apikit"
+	"ticket/pkg/db"
+	"ticket/pkg/openapi"
+)
+
+// Spec describes the routes of Router. Keep the two in step: the service
+// warns on startup, and cmd/openapi -check fails, when a route is missing.
+func Spec() *openapi.Document {
+	doc := apikit.NewSpec("Ticket service").
+		PathParam("board_id", uint32(0)).
+		PathParam("status_id", uint32(0)).
+		PathParam("ticket_id", uint64(0))
+
+	bg := doc.Group("/boards").Tag("boards").Secured()
+	bg.GET("", "getBoards").Summarize("List the current user's boards").
+		Returns(http.StatusOK, []db.Board{})
+	bg.GET("/:board_id", "getBoard").Summarize("Get a board with its statuses and tickets").
+		Returns(http.StatusOK, db.BoardWithRelated{})
+	bg.POST("", "createBoard").Summarize("Create a board").
+		Body(boards.BoardRequest{}).
+		Returns(http.StatusCreated, db.BoardWithRelated{})
+	bg.PUT("/:board_id", "updateBoard").Summarize("Rename a board").
+		Body(boards.BoardRequest{}).
+		Returns(http.StatusOK, db.BoardWithRelated{})
+
+	sg := bg.Group("/:board_id/statuses").Tag("statuses")
+	sg.POST("", "createStatus").Summarize("Add a status to a board").
+		Body(statuses.CreateStatusRequest{}).
+		Returns(http.StatusCreated, db.StatusWithRelated{})
+	sg.PUT("/sort-orders", "sortStatuses").Summarize("Reorder the statuses of a board").
+		Body(statuses.SortStatusesRequest{}).
+		Returns(http.StatusOK, []db.StatusWithRelated{})
+	sg.PATCH("/:status_id", "updateStatus").Summarize("Rename a status").
+		Body(statuses.UpdateStatusRequest{}).
+		Returns(http.StatusOK, db.StatusWithRelated{})
+	sg.PUT("/tickets/bulk-reorder", "bulkReorderTickets").Summarize("Move and reorder tickets across statuses").
+		Body(statuses.BulkReorderRequest{}).
+		Returns(http.StatusOK, []db.StatusWithRelated{})
+
+	tg := sg.Group("/:status_id/tickets").Tag("tickets")
+	tg.POST("", "createTicket").Summarize("Add a ticket to a status").
+		Body(tickets.CreateTicketRequest{}).
+		Returns(http.StatusCreated, db.Ticket{})
+	tg.PUT("/sort-orders", "sortTickets").Summarize("Reorder the tickets of a status").
+		Body(tickets.SortTicketsRequest{}).
+		Returns(http.StatusOK, []db.Ticket{})
+	tg.PATCH("/:ticket_id", "updateTicket").Summarize("Change a ticket or move it to another status").
+		Body(tickets.UpdateTicketRequest{}).
+		Returns(http.StatusOK, db.Ticket{})
+
+	return doc
+}
@@ -10,7 +10,7 @@ import (
 
 func Router(api *apikit.API) {
 	b := boards.New(api)
-	guard := auth.Middleware(api.Config)
+	guard := auth.Middleware(api.Config, api.Store)
 
 	bg := api.App.Group("/boards", guard)
 	bg.GET("", b.GetBoards)
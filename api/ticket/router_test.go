@@ -0,0 +1,320 @@
+package ticket
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"ticket/pkg/apikit"
+	"ticket/pkg/apikit/apitest"
+	"ticket/pkg/auth"
+	"ticket/pkg/db"
+	"ticket/pkg/wire"
+	"time"
+
+	"github.com/guregu/null/v5"
+)
+
+// fixture ids. Memory hands them out in order, so every fixture has the same.
+const (
+	ownerID    = 1
+	otherID    = 2
+	disabledID = 3
+
+	boardID      = 1
+	otherBoardID = 2
+
+	todoID        = 1
+	doneID        = 2
+	otherStatusID = 3
+
+	firstTicketID  = 1
+	secondTicketID = 2
+	otherTicketID  = 3
+)
+
+// newFixture gives the owner a board with the statuses Todo, holding two
+// tickets, and Done. The other user has a board, a status and a ticket of
+// their own.
+func newFixture(t *testing.T) *apitest.Server {
+	t.Helper()
+
+	s := apitest.New(t, []apikit.Router{Router})
+	s.Spec = Spec()
+	ctx := context.Background()
+
+	s.AddUser(t, db.User{Email: null.StringFrom("owner@example.com")})
+	s.AddUser(t, db.User{Email: null.StringFrom("other@example.com")})
+	s.AddUser(t, db.User{Email: null.StringFrom("disabled@example.com"), DisabledAt: null.TimeFrom(time.Now())})
+
+	must := func(_ any, err error) {
+		t.Helper()
+
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	must(s.Store.CreateBoard(ctx, db.CreateBoardParams{UserID: ownerID, Title: null.StringFrom("Roadmap"), SortOrder: 1}))
+	must(s.Store.CreateBoard(ctx, db.CreateBoardParams{UserID: otherID, Title: null.StringFrom("Private"), SortOrder: 1}))
+
+	must(s.Store.CreateStatus(ctx, db.CreateStatusParams{BoardID: boardID, Title: null.StringFrom("Todo"), SortOrder: 1}))
+	must(s.Store.CreateStatus(ctx, db.CreateStatusParams{BoardID: boardID, Title: null.StringFrom("Done"), SortOrder: 2}))
+	must(s.Store.CreateStatus(ctx, db.CreateStatusParams{BoardID: otherBoardID, Title: null.StringFrom("Todo"), SortOrder: 1}))
+
+	ticket := func(statusID uint32, title string, sortOrder uint32) db.CreateTicketParams {
+		return db.CreateTicketParams{
+			StatusID:    statusID,
+			Title:       null.StringFrom(title),
+			Description: null.StringFrom("description"),
+			Contact:     null.StringFrom("contact"),
+			SortOrder:   sortOrder,
+		}
+	}
+	must(s.Store.CreateTicket(ctx, ticket(todoID, "First", 0)))
+	must(s.Store.CreateTicket(ctx, ticket(todoID, "Second", 1)))
+	must(s.Store.CreateTicket(ctx, ticket(otherStatusID, "Other", 0)))
+
+	return s
+}
+
+func ticketOrder(t *testing.T, s *apitest.Server, statusID uint32) []uint64 {
+	t.Helper()
+
+	tickets, err := s.Store.GetTickets(context.Background(), db.GetTicketsParams{
+		StatusIds:          []uint32{statusID},
+		SortOrderDirection: null.StringFrom("asc"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []uint64
+	for _, tk := range tickets {
+		ids = append(ids, tk.ID)
+	}
+
+	return ids
+}
+
+func TestGuard(t *testing.T) {
+	s := newFixture(t)
+	owner := s.Token(t, ownerID)
+
+	apitest.Run(t, newFixture, []apitest.Case{
+		{Name: "no token", Method: http.MethodGet, Path: "/boards", Want: http.StatusUnauthorized, WantCode: wire.CodeUnauthorized},
+		{Name: "bad token", Method: http.MethodGet, Path: "/boards", Token: "not-a-token", Want: http.StatusUnauthorized, WantCode: wire.CodeUnauthorized},
+		{Name: "unknown user", Method: http.MethodGet, Path: "/boards", Token: s.Token(t, 99), Want: http.StatusUnauthorized, WantCode: wire.CodeUnauthorized},
+		{Name: "disabled user", Method: http.MethodGet, Path: "/boards", Token: s.Token(t, disabledID), Want: http.StatusForbidden, WantCode: wire.CodeAccountDisabled},
+		{Name: "password reset session", Method: http.MethodGet, Path: "/boards", Token: s.Token(t, ownerID, auth.ScopePasswordReset), Want: http.StatusForbidden, WantCode: wire.CodePasswordResetRequired},
+		{Name: "valid token", Method: http.MethodGet, Path: "/boards", Token: owner, Want: http.StatusOK},
+	})
+}
+
+func TestBoardRoutes(t *testing.T) {
+	s := newFixture(t)
+	owner := s.Token(t, ownerID)
+
+	apitest.Run(t, newFixture, []apitest.Case{
+		{
+			Name: "list boards", Method: http.MethodGet, Path: "/boards", Token: owner, Want: http.StatusOK,
+			Check: func(t *testing.T, s *apitest.Server, rec *httptest.ResponseRecorder) {
+				boards := apitest.Decode[[]db.Board](t, rec)
+				if len(boards) != 1 || boards[0].ID != boardID {
+					t.Errorf("boards = %+v, want only board %d", boards, boardID)
+				}
+			},
+		},
+		{Name: "list boards unauthenticated", Method: http.MethodGet, Path: "/boards", Want: http.StatusUnauthorized},
+
+		{
+			Name: "get board", Method: http.MethodGet, Path: "/boards/1", Token: owner, Want: http.StatusOK,
+			Check: func(t *testing.T, s *apitest.Server, rec *httptest.ResponseRecorder) {
+				board := apitest.Decode[db.BoardWithRelated](t, rec)
+				if len(board.Statuses) != 2 || len(board.Statuses[0].Tickets) != 2 {
+					t.Errorf("board = %+v, want 2 statuses and 2 tickets in the first", board)
+				}
+			},
+		},
+		{Name: "get board with invalid id", Method: http.MethodGet, Path: "/boards/abc", Token: owner, Want: http.StatusBadRequest, WantCode: wire.CodeBadRequest},
+		{Name: "get another user's board", Method: http.MethodGet, Path: "/boards/2", Token: owner, Want: http.StatusNotFound, WantCode: wire.CodeNotFound},
+		{Name: "get missing board", Method: http.MethodGet, Path: "/boards/99", Token: owner, Want: http.StatusNotFound, WantCode: wire.CodeNotFound},
+		{Name: "get board unauthenticated", Method: http.MethodGet, Path: "/boards/1", Want: http.StatusUnauthorized},
+
+		{
+			Name: "create board", Method: http.MethodPost, Path: "/boards", Token: owner, Body: map[string]any{"title": "Backlog"}, Want: http.StatusCreated,
+			Check: func(t *testing.T, s *apitest.Server, rec *httptest.ResponseRecorder) {
+				board := apitest.Decode[db.BoardWithRelated](t, rec)
+				if board.Title.String != "Backlog" || board.UserID != ownerID || board.SortOrder != 2 {
+					t.Errorf("board = %+v, want the owner's second board Backlog", board)
+				}
+			},
+		},
+		{Name: "create board with short title", Method: http.MethodPost, Path: "/boards", Token: owner, Body: map[string]any{"title": "ab"}, Want: http.StatusBadRequest, WantCode: wire.CodeValidation},
+		{Name: "create board without body", Method: http.MethodPost, Path: "/boards", Token: owner, Want: http.StatusBadRequest, WantCode: wire.CodeValidation},
+		{Name: "create board unauthenticated", Method: http.MethodPost, Path: "/boards", Body: map[string]any{"title": "Backlog"}, Want: http.StatusUnauthorized},
+
+		{
+			Name: "update board", Method: http.MethodPut, Path: "/boards/1", Token: owner, Body: map[string]any{"title": "Renamed"}, Want: http.StatusOK,
+			Check: func(t *testing.T, s *apitest.Server, rec *httptest.ResponseRecorder) {
+				board := apitest.Decode[db.BoardWithRelated](t, rec)
+				if board.Title.String != "Renamed" || len(board.Statuses) != 2 {
+					t.Errorf("board = %+v, want Renamed with its 2 statuses", board)
+				}
+			},
+		},
+		{Name: "update board with short title", Method: http.MethodPut, Path: "/boards/1", Token: owner, Body: map[string]any{"title": "ab"}, Want: http.StatusBadRequest, WantCode: wire.CodeValidation},
+		{Name: "update another user's board", Method: http.MethodPut, Path: "/boards/2", Token: owner, Body: map[string]any{"title": "Renamed"}, Want: http.StatusNotFound, WantCode: wire.CodeNotFound},
+		{Name: "update board unauthenticated", Method: http.MethodPut, Path: "/boards/1", Body: map[string]any{"title": "Renamed"}, Want: http.StatusUnauthorized},
+	})
+}
+
+func TestStatusRoutes(t *testing.T) {
+	s := newFixture(t)
+	owner := s.Token(t, ownerID)
+
+	apitest.Run(t, newFixture, []apitest.Case{
+		{
+			Name: "create status", Method: http.MethodPost, Path: "/boards/1/statuses", Token: owner, Body: map[string]any{"title": "Review"}, Want: http.StatusCreated,
+			Check: func(t *testing.T, s *apitest.Server, rec *httptest.ResponseRecorder) {
+				status := apitest.Decode[db.StatusWithRelated](t, rec)
+				if status.Title.String != "Review" || status.BoardID != boardID || status.SortOrder != 3 {
+					t.Errorf("status = %+v, want Review last on board %d", status, boardID)
+				}
+			},
+		},
+		{Name: "create status with short title", Method: http.MethodPost, Path: "/boards/1/statuses", Token: owner, Body: map[string]any{"title": "ab"}, Want: http.StatusBadRequest, WantCode: wire.CodeValidation},
+		{Name: "create status on another user's board", Method: http.MethodPost, Path: "/boards/2/statuses", Token: owner, Body: map[string]any{"title": "Review"}, Want: http.StatusNotFound, WantCode: wire.CodeNotFound},
+		{Name: "create status unauthenticated", Method: http.MethodPost, Path: "/boards/1/statuses", Body: map[string]any{"title": "Review"}, Want: http.StatusUnauthorized},
+
+		{
+			Name: "sort statuses", Method: http.MethodPut, Path: "/boards/1/statuses/sort-orders", Token: owner, Body: map[string]any{"status_ids": []int{doneID, todoID}}, Want: http.StatusOK,
+			Check: func(t *testing.T, s *apitest.Server, rec *httptest.ResponseRecorder) {
+				statuses := apitest.Decode[[]db.StatusWithRelated](t, rec)
+				if len(statuses) != 2 || statuses[0].ID != doneID || statuses[1].ID != todoID {
+					t.Errorf("statuses = %+v, want Done then Todo", statuses)
+				}
+			},
+		},
+		{Name: "sort statuses with a duplicate", Method: http.MethodPut, Path: "/boards/1/statuses/sort-orders", Token: owner, Body: map[string]any{"status_ids": []int{todoID, todoID}}, Want: http.StatusBadRequest, WantCode: wire.CodeBadRequest},
+		{Name: "sort statuses leaving one out", Method: http.MethodPut, Path: "/boards/1/statuses/sort-orders", Token: owner, Body: map[string]any{"status_ids": []int{todoID}}, Want: http.StatusBadRequest, WantCode: wire.CodeBadRequest},
+		{Name: "sort statuses without ids", Method: http.MethodPut, Path: "/boards/1/statuses/sort-orders", Token: owner, Body: map[string]any{}, Want: http.StatusBadRequest, WantCode: wire.CodeValidation},
+		{Name: "sort statuses of another board", Method: http.MethodPut, Path: "/boards/1/statuses/sort-orders", Token: owner, Body: map[string]any{"status_ids": []int{todoID, doneID, otherStatusID}}, Want: http.StatusNotFound, WantCode: wire.CodeNotFound},
+		{Name: "sort statuses unauthenticated", Method: http.MethodPut, Path: "/boards/1/statuses/sort-orders", Body: map[string]any{"status_ids": []int{doneID, todoID}}, Want: http.StatusUnauthorized},
+
+		{
+			Name: "update status", Method: http.MethodPatch, Path: "/boards/1/statuses/1", Token: owner, Body: map[string]any{"title": "Doing"}, Want: http.StatusOK,
+			Check: func(t *testing.T, s *apitest.Server, rec *httptest.ResponseRecorder) {
+				status := apitest.Decode[db.StatusWithRelated](t, rec)
+				if status.Title.String != "Doing" || len(status.Tickets) != 2 {
+					t.Errorf("status = %+v, want Doing with its 2 tickets", status)
+				}
+			},
+		},
+		{Name: "update status with short title", Method: http.MethodPatch, Path: "/boards/1/statuses/1", Token: owner, Body: map[string]any{"title": "ab"}, Want: http.StatusBadRequest, WantCode: wire.CodeValidation},
+		{Name: "update status with invalid id", Method: http.MethodPatch, Path: "/boards/1/statuses/abc", Token: owner, Body: map[string]any{"title": "Doing"}, Want: http.StatusBadRequest, WantCode: wire.CodeBadRequest},
+		{Name: "update another user's status", Method: http.MethodPatch, Path: "/boards/1/statuses/3", Token: owner, Body: map[string]any{"title": "Doing"}, Want: http.StatusNotFound, WantCode: wire.CodeNotFound},
+		{Name: "update status unauthenticated", Method: http.MethodPatch, Path: "/boards/1/statuses/1", Body: map[string]any{"title": "Doing"}, Want: http.StatusUnauthorized},
+
+		{
+			Name: "bulk reorder", Method: http.MethodPut, Path: "/boards/1/statuses/tickets/bulk-reorder", Token: owner,
+			Body: map[string]any{"statuses": []map[string]any{
+				{"id": todoID, "ticket_ids": []int{secondTicketID}},
+				{"id": doneID, "ticket_ids": []int{firstTicketID}},
+			}},
+			Want: http.StatusOK,
+			Check: func(t *testing.T, s *apitest.Server, rec *httptest.ResponseRecorder) {
+				if got := ticketOrder(t, s, todoID); !slices.Equal(got, []uint64{secondTicketID}) {
+					t.Errorf("Todo holds %v, want [%d]", got, secondTicketID)
+				}
+
+				if got := ticketOrder(t, s, doneID); !slices.Equal(got, []uint64{firstTicketID}) {
+					t.Errorf("Done holds %v, want [%d]", got, firstTicketID)
+				}
+			},
+		},
+		{
+			Name: "bulk reorder losing a ticket", Method: http.MethodPut, Path: "/boards/1/statuses/tickets/bulk-reorder", Token: owner,
+			Body: map[string]any{"statuses": []map[string]any{{"id": todoID, "ticket_ids": []int{firstTicketID}}}},
+			Want: http.StatusBadRequest, WantCode: wire.CodeInvalidLayout,
+		},
+		{
+			Name: "bulk reorder without statuses", Method: http.MethodPut, Path: "/boards/1/statuses/tickets/bulk-reorder", Token: owner,
+			Body: map[string]any{}, Want: http.StatusBadRequest, WantCode: wire.CodeValidation,
+		},
+		{
+			Name: "bulk reorder another user's status", Method: http.MethodPut, Path: "/boards/1/statuses/tickets/bulk-reorder", Token: owner,
+			Body: map[string]any{"statuses": []map[string]any{{"id": otherStatusID, "ticket_ids": []int{otherTicketID}}}},
+			Want: http.StatusNotFound, WantCode: wire.CodeNotFound,
+		},
+		{
+			Name: "bulk reorder unauthenticated", Method: http.MethodPut, Path: "/boards/1/statuses/tickets/bulk-reorder",
+			Body: map[string]any{"statuses": []map[string]any{{"id": todoID, "ticket_ids": []int{firstTicketID, secondTicketID}}}},
+			Want: http.StatusUnauthorized,
+		},
+	})
+}
+
+func TestTicketRoutes(t *testing.T) {
+	s := newFixture(t)
+	owner := s.Token(t, ownerID)
+	newTicket := map[string]any{"title": "Third", "description": "Users get a 500", "contact": "you@example.com"}
+
+	apitest.Run(t, newFixture, []apitest.Case{
+		{
+			Name: "create ticket", Method: http.MethodPost, Path: "/boards/1/statuses/1/tickets", Token: owner, Body: newTicket, Want: http.StatusCreated,
+			Check: func(t *testing.T, s *apitest.Server, rec *httptest.ResponseRecorder) {
+				ticket := apitest.Decode[db.Ticket](t, rec)
+				if ticket.Title.String != "Third" || ticket.StatusID != todoID || ticket.SortOrder != 2 {
+					t.Errorf("ticket = %+v, want Third last in Todo", ticket)
+				}
+			},
+		},
+		{Name: "create ticket without contact", Method: http.MethodPost, Path: "/boards/1/statuses/1/tickets", Token: owner, Body: map[string]any{"title": "Third", "description": "Users get a 500"}, Want: http.StatusBadRequest, WantCode: wire.CodeValidation},
+		{Name: "create ticket in another user's status", Method: http.MethodPost, Path: "/boards/1/statuses/3/tickets", Token: owner, Body: newTicket, Want: http.StatusNotFound, WantCode: wire.CodeNotFound},
+		{Name: "create ticket on another user's board", Method: http.MethodPost, Path: "/boards/2/statuses/3/tickets", Token: owner, Body: newTicket, Want: http.StatusNotFound, WantCode: wire.CodeNotFound},
+		{Name: "create ticket unauthenticated", Method: http.MethodPost, Path: "/boards/1/statuses/1/tickets", Body: newTicket, Want: http.StatusUnauthorized},
+
+		{
+			Name: "sort tickets", Method: http.MethodPut, Path: "/boards/1/statuses/1/tickets/sort-orders", Token: owner,
+			Body: map[string]any{"tickets": []map[string]any{{"id": secondTicketID}, {"id": firstTicketID}}}, Want: http.StatusOK,
+			Check: func(t *testing.T, s *apitest.Server, rec *httptest.ResponseRecorder) {
+				if got := ticketOrder(t, s, todoID); !slices.Equal(got, []uint64{secondTicketID, firstTicketID}) {
+					t.Errorf("Todo holds %v, want [%d %d]", got, secondTicketID, firstTicketID)
+				}
+			},
+		},
+		{
+			Name: "sort tickets leaving one out", Method: http.MethodPut, Path: "/boards/1/statuses/1/tickets/sort-orders", Token: owner,
+			Body: map[string]any{"tickets": []map[string]any{{"id": firstTicketID}}}, Want: http.StatusBadRequest, WantCode: wire.CodeInvalidLayout,
+		},
+		{
+			Name: "sort tickets without tickets", Method: http.MethodPut, Path: "/boards/1/statuses/1/tickets/sort-orders", Token: owner,
+			Body: map[string]any{}, Want: http.StatusBadRequest, WantCode: wire.CodeValidation,
+		},
+		{
+			Name: "sort another user's tickets", Method: http.MethodPut, Path: "/boards/1/statuses/3/tickets/sort-orders", Token: owner,
+			Body: map[string]any{"tickets": []map[string]any{{"id": otherTicketID}}}, Want: http.StatusNotFound, WantCode: wire.CodeNotFound,
+		},
+		{
+			Name: "sort tickets unauthenticated", Method: http.MethodPut, Path: "/boards/1/statuses/1/tickets/sort-orders",
+			Body: map[string]any{"tickets": []map[string]any{{"id": secondTicketID}, {"id": firstTicketID}}}, Want: http.StatusUnauthorized,
+		},
+
+		{
+			Name: "update ticket", Method: http.MethodPatch, Path: "/boards/1/statuses/1/tickets/1", Token: owner, Body: map[string]any{"title": "Renamed"}, Want: http.StatusOK,
+			Check: func(t *testing.T, s *apitest.Server, rec *httptest.ResponseRecorder) {
+				ticket := apitest.Decode[db.Ticket](t, rec)
+				if ticket.Title.String != "Renamed" || ticket.Description.String != "description" {
+					t.Errorf("ticket = %+v, want only the title changed", ticket)
+				}
+			},
+		},
+		{Name: "update ticket with short title", Method: http.MethodPatch, Path: "/boards/1/statuses/1/tickets/1", Token: owner, Body: map[string]any{"title": "ab"}, Want: http.StatusBadRequest, WantCode: wire.CodeValidation},
+		{Name: "update ticket in the wrong status", Method: http.MethodPatch, Path: "/boards/1/statuses/2/tickets/1", Token: owner, Body: map[string]any{"title": "Renamed"}, Want: http.StatusBadRequest, WantCode: wire.CodeBadRequest},
+		{Name: "update ticket with invalid id", Method: http.MethodPatch, Path: "/boards/1/statuses/1/tickets/abc", Token: owner, Body: map[string]any{"title": "Renamed"}, Want: http.StatusBadRequest, WantCode: wire.CodeBadRequest},
+		{Name: "update another user's ticket", Method: http.MethodPatch, Path: "/boards/1/statuses/1/tickets/3", Token: owner, Body: map[string]any{"title": "Renamed"}, Want: http.StatusNotFound, WantCode: wire.CodeNotFound},
+		{Name: "update ticket unauthenticated", Method: http.MethodPatch, Path: "/boards/1/statuses/1/tickets/1", Body: map[string]any{"title": "Renamed"}, Want: http.StatusUnauthorized},
+	})
+}
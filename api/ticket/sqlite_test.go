@@ -0,0 +1,126 @@
+package ticket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+	"ticket/api/authen"
+	"ticket/migration"
+	"ticket/pkg/apikit"
+	"ticket/pkg/apikit/apitest"
+	"ticket/pkg/db"
+	"ticket/pkg/migrate"
+	"ticket/pkg/store"
+	"ticket/pkg/wire"
+)
+
+// TestSQLiteEndToEnd runs a user's whole life on a migrated SQLite database:
+// signing up, building a board, ordering its tickets and deleting the
+// account, then migrates the database back down.
+func TestSQLiteEndToEnd(t *testing.T) {
+	conn := apitest.SQLite(t)
+	s := apitest.New(t, []apikit.Router{authen.Router, Router}, apikit.WithStore(store.NewSQLite(conn)))
+	ctx := context.Background()
+
+	var token string
+	do := func(method, path string, body any, want int, out any) {
+		t.Helper()
+
+		rec := s.Do(t, method, path, token, body)
+		if rec.Code != want {
+			t.Fatalf("%s %s: status = %d, want %d: %s", method, path, rec.Code, want, rec.Body)
+		}
+
+		if out != nil {
+			err := json.Unmarshal(rec.Body.Bytes(), out)
+			if err != nil {
+				t.Fatalf("%s %s: %v", method, path, err)
+			}
+		}
+	}
+
+	do(http.MethodPost, "/sign-up", map[string]any{"name": "Grace", "lastname": "Hopper", "email": "grace@example.com", "password": "password123"}, http.StatusCreated, nil)
+
+	var tokens wire.Tokens
+	do(http.MethodPost, "/sign-in", map[string]any{"email": "grace@example.com", "password": "password123"}, http.StatusOK, &tokens)
+	token = tokens.AccessToken
+
+	var boards []db.Board
+	do(http.MethodGet, "/boards", nil, http.StatusOK, &boards)
+	if len(boards) != 1 {
+		t.Fatalf("boards after sign-up = %+v, want the first board", boards)
+	}
+
+	var board db.BoardWithRelated
+	do(http.MethodPost, "/boards", map[string]any{"title": "Release"}, http.StatusCreated, &board)
+	boardPath := fmt.Sprintf("/boards/%d", board.ID)
+
+	var todo, done db.StatusWithRelated
+	do(http.MethodPost, boardPath+"/statuses", map[string]any{"title": "Todo"}, http.StatusCreated, &todo)
+	do(http.MethodPost, boardPath+"/statuses", map[string]any{"title": "Done"}, http.StatusCreated, &done)
+	todoPath := fmt.Sprintf("%s/statuses/%d", boardPath, todo.ID)
+
+	var ids []uint64
+	for _, title := range []string{"Write", "Review", "Ship"} {
+		var tk db.Ticket
+		do(http.MethodPost, todoPath+"/tickets", map[string]any{"title": title, "description": "description", "contact": "contact"}, http.StatusCreated, &tk)
+		ids = append(ids, tk.ID)
+	}
+	write, review, ship := ids[0], ids[1], ids[2]
+
+	var sorted []db.Ticket
+	do(http.MethodPut, todoPath+"/tickets/sort-orders", map[string]any{"tickets": []map[string]any{{"id": ship}, {"id": write}, {"id": review}}}, http.StatusOK, &sorted)
+	if got := ticketIDs(sorted); !slices.Equal(got, []uint64{ship, write, review}) {
+		t.Errorf("sorted tickets = %v, want %v", got, []uint64{ship, write, review})
+	}
+
+	do(http.MethodPut, boardPath+"/statuses/tickets/bulk-reorder", map[string]any{"statuses": []map[string]any{
+		{"id": todo.ID, "ticket_ids": []uint64{write}},
+		{"id": done.ID, "ticket_ids": []uint64{review, ship}},
+	}}, http.StatusOK, nil)
+
+	do(http.MethodGet, boardPath, nil, http.StatusOK, &board)
+	if len(board.Statuses) != 2 ||
+		!slices.Equal(ticketIDs(board.Statuses[0].Tickets), []uint64{write}) ||
+		!slices.Equal(ticketIDs(board.Statuses[1].Tickets), []uint64{review, ship}) {
+		t.Errorf("board after reorder = %+v, want Todo [%d] and Done [%d %d]", board, write, review, ship)
+	}
+
+	do(http.MethodDelete, "/users/me", map[string]any{"password": "password123"}, http.StatusNoContent, nil)
+
+	for _, table := range []string{"tickets", "statuses", "boards", "users"} {
+		var n int
+		err := conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+table).Scan(&n)
+		if err != nil || n != 0 {
+			t.Errorf("%s has %d rows after deleting the account, %v", table, n, err)
+		}
+	}
+
+	m, err := migrate.New(conn, "sqlite", migration.Schema, migration.Dir("sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = m.To(ctx, 0)
+	if err != nil {
+		t.Fatalf("migrating down: %v", err)
+	}
+
+	var tables int
+	err = conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name IN ('users', 'boards', 'statuses', 'tickets', 'user_identities')").Scan(&tables)
+	if err != nil || tables != 0 {
+		t.Errorf("%d tables left after migrating down, %v", tables, err)
+	}
+}
+
+func ticketIDs(tickets []db.Ticket) []uint64 {
+	var ids []uint64
+	for _, tk := range tickets {
+		ids = append(ids, tk.ID)
+	}
+
+	return ids
+}
@@ -3,12 +3,14 @@ package statuses
 import (
 	"context"
 	"database/sql"
-	"fmt"
+	"errors"
 	"net/http"
-	"strconv"
 	"ticket/pkg/apikit"
 	"ticket/pkg/auth"
 	"ticket/pkg/db"
+	"ticket/pkg/metrics"
+	"ticket/pkg/store"
+	"ticket/pkg/wire"
 
 	"github.com/guregu/null/v5"
 	"github.com/labstack/echo/v4"
@@ -16,44 +18,44 @@ import (
 )
 
 type Handler struct {
-	DB      *sql.DB
-	Queries *db.Queries
-	Auth    *auth.Auth
+	Store store.Store
+	Auth  *auth.Auth
 }
 
 func New(api *apikit.API) *Handler {
 	return &Handler{
-		DB:      api.DB,
-		Queries: db.New(api.DB),
-		Auth:    auth.New(api.Config),
+		Store: api.Store,
+		Auth:  auth.New(api.Config),
 	}
 }
 
+type CreateStatusRequest struct {
+	Title string `json:"title" validate:"required,min=3,max=50"`
+}
+
 func (h *Handler) CreateStatus(c echo.Context) error {
 	claims := c.Get("claims").(*auth.Claims)
 
-	boardID, err := strconv.ParseUint(c.Param("board_id"), 10, 32)
+	boardID, err := apikit.ParamID(c, "board_id", 32)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
-	var body struct {
-		Title string `json:"title" validate:"required,min=3,max=50"`
-	}
+	var body CreateStatusRequest
 
 	err = c.Bind(&body)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	err = c.Validate(&body)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	ctx := c.Request().Context()
 
-	board, err := h.Queries.GetBoard(ctx, db.GetBoardParams{
+	board, err := h.Store.GetBoard(ctx, db.GetBoardParams{
 		ID:     uint32(boardID),
 		UserID: claims.UserID,
 	})
@@ -62,153 +64,156 @@ func (h *Handler) CreateStatus(c echo.Context) error {
 			return echo.NewHTTPError(http.StatusNotFound, "board not found")
 		}
 
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
-	count, err := h.Queries.CountStatusByBoardID(ctx, board.ID)
+	count, err := h.Store.CountStatusByBoardID(ctx, board.ID)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
-	tx, err := h.DB.Begin()
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-	}
-	defer tx.Rollback()
-	qtx := h.Queries.WithTx(tx)
+	var status db.Status
+	err = store.WithTx(ctx, h.Store, func(qtx store.Querier) error {
+		res, err := qtx.CreateStatus(ctx, db.CreateStatusParams{
+			BoardID:   board.ID,
+			Title:     null.NewString(body.Title, true),
+			SortOrder: uint32(count + 1),
+		})
+		if err != nil {
+			return err
+		}
 
-	err = qtx.CreateStatus(ctx, db.CreateStatusParams{
-		BoardID:   board.ID,
-		Title:     null.NewString(body.Title, true),
-		SortOrder: uint32(count + 1),
-	})
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-	}
+		statusID, err := res.LastInsertId()
+		if err != nil {
+			return err
+		}
 
-	status, err := qtx.GetLastInsertStatus(ctx)
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-	}
+		status, err = qtx.GetStatus(ctx, db.GetStatusParams{
+			ID:      sql.NullInt32{Int32: int32(statusID), Valid: true},
+			BoardID: sql.NullInt32{Int32: int32(board.ID), Valid: true},
+		})
+		if err != nil {
+			return err
+		}
 
-	err = tx.Commit()
+		return nil
+	})
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	return c.JSON(http.StatusCreated, db.NewStatusWithRelated(status, nil))
 }
 
+type UpdateStatusRequest struct {
+	Title *string `json:"title" validate:"omitempty,min=3,max=50"`
+}
+
 func (h *Handler) UpdateStatusPartial(c echo.Context) error {
 	claims := c.Get("claims").(*auth.Claims)
 
-	boardID, err := strconv.ParseUint(c.Param("board_id"), 10, 32)
+	boardID, err := apikit.ParamID(c, "board_id", 32)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
-	statusID, err := strconv.ParseUint(c.Param("status_id"), 10, 32)
+	statusID, err := apikit.ParamID(c, "status_id", 32)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
-	var body struct {
-		Title *string `json:"title" validate:"omitempty,min=3,max=50"`
-	}
+	var body UpdateStatusRequest
 
 	err = c.Bind(&body)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	err = c.Validate(&body)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	ctx := c.Request().Context()
-	tx, err := h.DB.Begin()
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-	}
-	defer tx.Rollback()
-	qtx := h.Queries.WithTx(tx)
+	err = store.WithTx(ctx, h.Store, func(qtx store.Querier) error {
+		statusWithBoard, err := qtx.GetStatusWithBoard(ctx, db.GetStatusWithBoardParams{
+			ID:      uint32(statusID),
+			BoardID: uint32(boardID),
+			UserID:  claims.UserID,
+		})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return echo.NewHTTPError(http.StatusNotFound, "status not found")
+			}
 
-	statusWithBoard, err := qtx.GetStatusWithBoard(ctx, db.GetStatusWithBoardParams{
-		ID:      uint32(statusID),
-		BoardID: uint32(boardID),
-		UserID:  claims.UserID,
-	})
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return echo.NewHTTPError(http.StatusNotFound, "status not found")
+			return err
 		}
 
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-	}
-
-	isChanged := false
-	statusParams := db.UpdateStatusParams{
-		ID:        statusWithBoard.Status.ID,
-		Title:     statusWithBoard.Status.Title,
-		SortOrder: statusWithBoard.Status.SortOrder,
-	}
-
-	if body.Title != nil {
-		isChanged = true
-		statusParams.Title = null.NewString(*body.Title, true)
-	}
+		isChanged := false
+		statusParams := db.UpdateStatusParams{
+			ID:        statusWithBoard.Status.ID,
+			Title:     statusWithBoard.Status.Title,
+			SortOrder: statusWithBoard.Status.SortOrder,
+		}
 
-	if isChanged {
-		err = qtx.UpdateStatus(ctx, statusParams)
-		if err != nil {
-			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		if body.Title != nil {
+			isChanged = true
+			statusParams.Title = null.NewString(*body.Title, true)
 		}
 
-		err = tx.Commit()
-		if err != nil {
-			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		if isChanged {
+			err = qtx.UpdateStatus(ctx, statusParams)
+			if err != nil {
+				return err
+			}
 		}
+
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	status, err := h.Queries.GetStatus(ctx, db.GetStatusParams{
+	status, err := h.Store.GetStatus(ctx, db.GetStatusParams{
 		ID: sql.NullInt32{Int32: int32(statusID), Valid: true},
 	})
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
-	tickets, err := h.Queries.GetTickets(ctx, db.GetTicketsParams{
+	tickets, err := h.Store.GetTickets(ctx, db.GetTicketsParams{
 		StatusIds:          []uint32{status.ID},
 		SortOrderDirection: null.StringFrom("asc"),
 	})
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	return c.JSON(http.StatusOK, db.NewStatusWithRelated(status, tickets))
 }
 
+type SortStatusesRequest struct {
+	StatuseIDs []uint64 `json:"status_ids" validate:"required,dive"`
+}
+
 func (h *Handler) SortStatusesOrder(c echo.Context) error {
 	claims := c.Get("claims").(*auth.Claims)
 
-	boardID, err := strconv.ParseUint(c.Param("board_id"), 10, 32)
+	boardID, err := apikit.ParamID(c, "board_id", 32)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
-	var body struct {
-		StatuseIDs []uint64 `json:"status_ids" validate:"required,dive"`
-	}
+	var body SortStatusesRequest
 
 	err = c.Bind(&body)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	err = c.Validate(&body)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	var statusIDs []uint32
@@ -224,76 +229,56 @@ func (h *Handler) SortStatusesOrder(c echo.Context) error {
 
 	ctx := c.Request().Context()
 
-	count, err := h.Queries.CountStatusWithBoard(ctx, db.CountStatusWithBoardParams{
+	count, err := h.Store.CountStatusWithBoard(ctx, db.CountStatusWithBoardParams{
 		Ids:     statusIDs,
 		BoardID: uint32(boardID),
 		UserID:  claims.UserID,
 	})
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	if count != int64(len(statusIDs)) {
 		return echo.NewHTTPError(http.StatusNotFound, "some status id not exist")
 	}
 
-	count, err = h.Queries.CountStatusWithBoardExclude(ctx, db.CountStatusWithBoardExcludeParams{
+	count, err = h.Store.CountStatusWithBoardExclude(ctx, db.CountStatusWithBoardExcludeParams{
 		Ids:     statusIDs,
 		BoardID: uint32(boardID),
 		UserID:  claims.UserID,
 	})
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	if count > 0 {
 		return echo.NewHTTPError(http.StatusBadRequest, "some status id is missing")
 	}
 
-	tx, err := h.DB.Begin()
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-	}
-	defer tx.Rollback()
-	qtx := h.Queries.WithTx(tx)
-
-	subctx1, cancel := context.WithCancel(ctx)
-	g, subctx1 := errgroup.WithContext(subctx1)
-	defer cancel()
-
-	for i, statusID := range body.StatuseIDs {
-		i := i
-		s := statusID
-		g.Go(func() error {
-			err = qtx.UpdateStatusSortOrder(subctx1, db.UpdateStatusSortOrderParams{
+	err = store.WithTx(ctx, h.Store, func(qtx store.Querier) error {
+		for i, statusID := range body.StatuseIDs {
+			err := qtx.UpdateStatusSortOrder(ctx, db.UpdateStatusSortOrderParams{
 				SortOrder: uint32(i + 1),
-				ID:        uint32(s),
+				ID:        uint32(statusID),
 			})
 			if err != nil {
 				return err
 			}
+		}
 
-			return nil
-		})
-	}
-
-	err = g.Wait()
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-	}
-
-	err = tx.Commit()
+		return nil
+	})
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	subctx2, cancel := context.WithCancel(ctx)
-	g, subctx2 = errgroup.WithContext(subctx2)
+	g, subctx2 := errgroup.WithContext(subctx2)
 	defer cancel()
 	chtickets := make(chan []db.Ticket)
 
 	g.Go(func() error {
-		tickets, err := h.Queries.GetTickets(subctx2, db.GetTicketsParams{
+		tickets, err := h.Store.GetTickets(subctx2, db.GetTicketsParams{
 			StatusIds:          statusIDs,
 			SortOrderDirection: null.StringFrom("asc"),
 		})
@@ -308,24 +293,24 @@ func (h *Handler) SortStatusesOrder(c echo.Context) error {
 		return nil
 	})
 
-	statuses, err := h.Queries.GetStatuses(ctx, db.GetStatusesParams{
+	statuses, err := h.Store.GetStatuses(ctx, db.GetStatusesParams{
 		BoardID:            sql.NullInt32{Int32: int32(boardID), Valid: true},
 		SortOrderDirection: null.StringFrom("asc"),
 	})
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	var tickets []db.Ticket
 	select {
 	case <-subctx2.Done():
-		return echo.NewHTTPError(http.StatusInternalServerError, subctx2.Err().Error())
+		return subctx2.Err()
 	case tickets = <-chtickets:
 	}
 
 	err = g.Wait()
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	var statusesWithRelated []db.StatusWithRelated
@@ -336,153 +321,89 @@ func (h *Handler) SortStatusesOrder(c echo.Context) error {
 	return c.JSON(http.StatusOK, statusesWithRelated)
 }
 
+// StatusTickets lists the tickets of a status in their new order.
+type StatusTickets struct {
+	ID        uint64   `json:"id" validate:"required"`
+	TicketIDs []uint64 `json:"ticket_ids" validate:"required,dive"`
+}
+
+type BulkReorderRequest struct {
+	Statuses []StatusTickets `json:"statuses" validate:"required,dive"`
+}
+
 func (h *Handler) BulkUpdateTicketOrderInStatuses(c echo.Context) error {
 	claims := c.Get("claims").(*auth.Claims)
 
-	boardID, err := strconv.ParseUint(c.Param("board_id"), 10, 32)
+	boardID, err := apikit.ParamID(c, "board_id", 32)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
-	var body struct {
-		Statuses []struct {
-			ID        uint64   `json:"id" validate:"required"`
-			TicketIDs []uint64 `json:"ticket_ids" validate:"required,dive"`
-		} `json:"statuses" validate:"required,dive"`
-	}
+	var body BulkReorderRequest
 
 	err = c.Bind(&body)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	err = c.Validate(&body)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
+	var orders []db.StatusOrder
 	var statusIDs []uint32
-	var ticketIDs []uint64
-	statusIDMap := make(map[uint64]bool)
-	ticketIDMap := make(map[uint64]bool)
+	listed := make(map[uint32]bool)
 	for _, status := range body.Statuses {
-		for _, ID := range ticketIDs {
-			if _, exists := ticketIDMap[ID]; exists {
-				return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("ticket id %d must be unique", ID))
-			}
-
-			ticketIDMap[ID] = true
-		}
-
-		if _, exists := statusIDMap[status.ID]; exists {
-			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("status id %d must be unique", status.ID))
+		orders = append(orders, db.StatusOrder{StatusID: uint32(status.ID), TicketIDs: status.TicketIDs})
+		if !listed[uint32(status.ID)] {
+			listed[uint32(status.ID)] = true
+			statusIDs = append(statusIDs, uint32(status.ID))
 		}
-
-		statusIDs = append(statusIDs, uint32(status.ID))
-		ticketIDs = append(ticketIDs, status.TicketIDs...)
 	}
 
 	ctx := c.Request().Context()
-	prectx, cancel := context.WithCancel(ctx)
-	g, prectx := errgroup.WithContext(prectx)
-	defer cancel()
-
-	chtotal := make(chan int64)
-
-	g.Go(func() error {
-		total, err := h.Queries.CountTicketWithBoard(ctx, db.CountTicketWithBoardParams{
-			Ids:     ticketIDs,
+	moved := 0
+	err = store.WithTx(ctx, h.Store, func(qtx store.Querier) error {
+		total, err := qtx.CountStatusWithBoard(ctx, db.CountStatusWithBoardParams{
+			Ids:     statusIDs,
 			BoardID: uint32(boardID),
 			UserID:  claims.UserID,
 		})
 		if err != nil {
-			cancel()
-
 			return err
 		}
 
-		chtotal <- total
-
-		return nil
-	})
-
-	total, err := h.Queries.CountStatusWithBoard(ctx, db.CountStatusWithBoardParams{
-		Ids:     statusIDs,
-		BoardID: uint32(boardID),
-		UserID:  claims.UserID,
-	})
-	if err != nil {
-		return err
-	}
-
-	if total != int64(len(statusIDs)) {
-		return fmt.Errorf(fmt.Sprintf("ticket id %d not found", ticketIDs))
-	}
-
-	select {
-	case <-prectx.Done():
-		return echo.NewHTTPError(http.StatusInternalServerError, prectx.Err().Error())
-	case total := <-chtotal:
-		if total != int64(len(ticketIDs)) {
-			return echo.NewHTTPError(http.StatusBadRequest, "some ticket's id does exist")
+		if total != int64(len(statusIDs)) {
+			return echo.NewHTTPError(http.StatusNotFound, "some status id not exist")
 		}
-	}
 
-	err = g.Wait()
-	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
-	}
-
-	tx, err := h.DB.Begin()
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-	}
-	defer tx.Rollback()
-	qtx := h.Queries.WithTx(tx)
-
-	subctx, cancel := context.WithCancel(ctx)
-	g, subctx = errgroup.WithContext(subctx)
-	defer cancel()
+		positions, n, err := store.PlanReorder(ctx, qtx, uint32(boardID), claims.UserID, orders...)
+		if errors.Is(err, db.ErrLayout) {
+			return apikit.NewError(http.StatusBadRequest, wire.CodeInvalidLayout, err.Error())
+		}
 
-	for _, s := range body.Statuses {
-		statusID := s.ID
-
-		for i, ID := range s.TicketIDs {
-			i := i
-			ID := ID
-			g.Go(func() error {
-				err = qtx.UpdateTicketSortOrderAndStatusID(subctx, db.UpdateTicketSortOrderAndStatusIDParams{
-					StatusID:  uint32(statusID),
-					SortOrder: uint32(i + 1),
-					ID:        ID,
-				})
-				if err != nil {
-					return err
-				}
-
-				return nil
-			})
+		if err != nil {
+			return err
 		}
-	}
+		moved = n
 
-	err = g.Wait()
+		return qtx.ReorderTickets(ctx, positions)
+	})
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
-	err = tx.Commit()
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-	}
+	metrics.TicketsMoved.Add(float64(moved))
 
 	posctx, cancel := context.WithCancel(ctx)
-	g, posctx = errgroup.WithContext(posctx)
+	g, posctx := errgroup.WithContext(posctx)
 	defer cancel()
 
 	chtickets := make(chan []db.Ticket)
 
 	g.Go(func() error {
-		tickets, err := h.Queries.GetTickets(posctx, db.GetTicketsParams{
+		tickets, err := h.Store.GetTickets(posctx, db.GetTicketsParams{
 			StatusIds:          statusIDs,
 			SortOrderDirection: null.StringFrom("asc"),
 		})
@@ -497,24 +418,24 @@ func (h *Handler) BulkUpdateTicketOrderInStatuses(c echo.Context) error {
 		return nil
 	})
 
-	statuses, err := h.Queries.GetStatuses(ctx, db.GetStatusesParams{
+	statuses, err := h.Store.GetStatuses(ctx, db.GetStatusesParams{
 		Ids:                statusIDs,
 		SortOrderDirection: null.StringFrom("asc"),
 	})
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	var tickets []db.Ticket
 	select {
 	case <-posctx.Done():
-		return echo.NewHTTPError(http.StatusInternalServerError, posctx.Err().Error())
+		return posctx.Err()
 	case tickets = <-chtickets:
 	}
 
 	err = g.Wait()
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	return c.JSON(http.StatusOK, db.NewStatusesWithRelated(statuses, tickets))
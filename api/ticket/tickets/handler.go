@@ -1,68 +1,69 @@
 package tickets
 
 import (
-	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"net/http"
-	"strconv"
 	"ticket/pkg/apikit"
 	"ticket/pkg/auth"
 	"ticket/pkg/db"
+	"ticket/pkg/metrics"
+	"ticket/pkg/store"
+	"ticket/pkg/wire"
 
 	"github.com/guregu/null/v5"
 	"github.com/labstack/echo/v4"
-	"golang.org/x/sync/errgroup"
 )
 
 type Handler struct {
-	DB       *sql.DB
+	Store    store.Store
 	DBConfig apikit.DBConfig
-	Queries  *db.Queries
 	Auth     *auth.Auth
 }
 
 func New(api *apikit.API) *Handler {
 	return &Handler{
-		DB:       api.DB,
+		Store:    api.Store,
 		DBConfig: api.Config.DB(),
-		Queries:  db.New(api.DB),
 		Auth:     auth.New(api.Config),
 	}
 }
 
+type CreateTicketRequest struct {
+	Title       string `json:"title" validate:"required,min=3,max=100"`
+	Description string `json:"description" validate:"required,min=3,max=500"`
+	Contact     string `json:"contact" validate:"required,min=3,max=100"`
+}
+
 func (h *Handler) CreateTicket(c echo.Context) error {
 	claims := c.Get("claims").(*auth.Claims)
 
-	boardID, err := strconv.ParseUint(c.Param("board_id"), 10, 32)
+	boardID, err := apikit.ParamID(c, "board_id", 32)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
-	statusID, err := strconv.ParseUint(c.Param("status_id"), 10, 32)
+	statusID, err := apikit.ParamID(c, "status_id", 32)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
-	var body struct {
-		Title       string `json:"title" validate:"required,min=3,max=100"`
-		Description string `json:"description" validate:"required,min=3,max=500"`
-		Contact     string `json:"contact" validate:"required,min=3,max=100"`
-	}
+	var body CreateTicketRequest
 
 	err = c.Bind(&body)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	err = c.Validate(&body)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	ctx := c.Request().Context()
 
-	status, err := h.Queries.GetStatusWithBoard(ctx, db.GetStatusWithBoardParams{
+	status, err := h.Store.GetStatusWithBoard(ctx, db.GetStatusWithBoardParams{
 		ID:      uint32(statusID),
 		BoardID: uint32(boardID),
 		UserID:  claims.UserID,
@@ -72,288 +73,233 @@ func (h *Handler) CreateTicket(c echo.Context) error {
 			return echo.NewHTTPError(http.StatusNotFound, "status not found")
 		}
 
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
-	count, err := h.Queries.CountTicketByStatusID(ctx, uint32(status.Status.ID))
+	count, err := h.Store.CountTicketByStatusID(ctx, uint32(status.Status.ID))
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
-	tx, err := h.DB.Begin()
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-	}
-	defer tx.Rollback()
-	qtx := h.Queries.WithTx(tx)
-
-	err = qtx.CreateTicket(ctx, db.CreateTicketParams{
-		StatusID:    uint32(status.Status.ID),
-		Title:       null.NewString(body.Title, true),
-		Description: null.NewString(body.Description, true),
-		Contact:     null.NewString(body.Contact, true),
-		SortOrder:   uint32(count),
-	})
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-	}
+	var ticket db.Ticket
+	err = store.WithTx(ctx, h.Store, func(qtx store.Querier) error {
+		res, err := qtx.CreateTicket(ctx, db.CreateTicketParams{
+			StatusID:    uint32(status.Status.ID),
+			Title:       null.NewString(body.Title, true),
+			Description: null.NewString(body.Description, true),
+			Contact:     null.NewString(body.Contact, true),
+			SortOrder:   uint32(count),
+		})
+		if err != nil {
+			return err
+		}
 
-	ticket, err := qtx.GetLastInsertTicket(ctx)
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-	}
+		ticketID, err := res.LastInsertId()
+		if err != nil {
+			return err
+		}
 
-	err = tx.Commit()
+		ticket, err = qtx.GetTicketByID(ctx, uint64(ticketID))
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
+	metrics.TicketsCreated.Inc()
+
 	return c.JSON(http.StatusCreated, ticket)
 }
 
+// UpdateTicketRequest changes only the fields that are set. Tickets are
+// reordered through the sort-orders and bulk-reorder routes instead.
+type UpdateTicketRequest struct {
+	Title       *string `json:"title" validate:"omitempty,min=3,max=100"`
+	Description *string `json:"description" validate:"omitempty,min=3,max=500"`
+	Contact     *string `json:"contact" validate:"omitempty,min=3,max=100"`
+	StatusID    *uint32 `json:"status_id" validate:"omitempty,min=0"`
+}
+
 func (h *Handler) UpdateTicketPartial(c echo.Context) error {
 	claims := c.Get("claims").(*auth.Claims)
 
-	boardID, err := strconv.ParseUint(c.Param("board_id"), 10, 32)
+	boardID, err := apikit.ParamID(c, "board_id", 32)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
-	statusID, err := strconv.ParseUint(c.Param("status_id"), 10, 32)
+	statusID, err := apikit.ParamID(c, "status_id", 32)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
-	ticketID, err := strconv.ParseUint(c.Param("ticket_id"), 10, 64)
+	ticketID, err := apikit.ParamID(c, "ticket_id", 64)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
-	var body struct {
-		Title       *string `json:"title" validate:"omitempty,min=3,max=100"`
-		Description *string `json:"description" validate:"omitempty,min=3,max=500"`
-		Contact     *string `json:"contact" validate:"omitempty,min=3,max=100"`
-		SortOrder   *uint32 `json:"sort_order" validte:"omitempty,min=0"`
-		StatusID    *uint32 `json:"status_id" validate:"omitempty,min=0"`
-	}
+	var body UpdateTicketRequest
 
 	err = c.Bind(&body)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	err = c.Validate(&body)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	ctx := c.Request().Context()
-	tx, err := h.DB.Begin()
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-	}
-	defer tx.Rollback()
-	qtx := h.Queries.WithTx(tx)
+	err = store.WithTx(ctx, h.Store, func(qtx store.Querier) error {
+		ticket, err := qtx.GetTicketWithBoard(ctx, db.GetTicketWithBoardParams{
+			ID:      ticketID,
+			BoardID: uint32(boardID),
+			UserID:  claims.UserID,
+		})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return echo.NewHTTPError(http.StatusNotFound, "ticket not found")
+			}
 
-	ticket, err := qtx.GetTicketWithBoard(ctx, db.GetTicketWithBoardParams{
-		ID:      ticketID,
-		BoardID: uint32(boardID),
-		UserID:  claims.UserID,
-	})
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return echo.NewHTTPError(http.StatusNotFound, "ticket not found")
+			return err
 		}
 
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-	}
-
-	if statusID != uint64(ticket.Ticket.StatusID) {
-		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("status_id is not match, expected: %d", ticket.Ticket.StatusID))
-	}
-
-	isChanged := false
-	ticketParam := db.UpdateTicketParams{
-		StatusID:    ticket.Ticket.StatusID,
-		Title:       ticket.Ticket.Title,
-		Description: ticket.Ticket.Description,
-		Contact:     ticket.Ticket.Contact,
-		SortOrder:   ticket.Ticket.SortOrder,
-		ID:          ticket.Ticket.ID,
-	}
+		if statusID != uint64(ticket.Ticket.StatusID) {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("status_id is not match, expected: %d", ticket.Ticket.StatusID))
+		}
 
-	if body.Title != nil {
-		isChanged = true
-		ticketParam.Title = null.NewString(*body.Title, true)
-	}
+		isChanged := false
+		ticketParam := db.UpdateTicketParams{
+			StatusID:    ticket.Ticket.StatusID,
+			Title:       ticket.Ticket.Title,
+			Description: ticket.Ticket.Description,
+			Contact:     ticket.Ticket.Contact,
+			SortOrder:   ticket.Ticket.SortOrder,
+			ID:          ticket.Ticket.ID,
+		}
 
-	if body.Description != nil {
-		isChanged = true
-		ticketParam.Description = null.NewString(*body.Description, true)
-	}
+		if body.Title != nil {
+			isChanged = true
+			ticketParam.Title = null.NewString(*body.Title, true)
+		}
 
-	if body.Contact != nil {
-		isChanged = true
-		ticketParam.Contact = null.NewString(*body.Contact, true)
-	}
+		if body.Description != nil {
+			isChanged = true
+			ticketParam.Description = null.NewString(*body.Description, true)
+		}
 
-	if isChanged {
-		err = qtx.UpdateTicket(ctx, ticketParam)
-		if err != nil {
-			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		if body.Contact != nil {
+			isChanged = true
+			ticketParam.Contact = null.NewString(*body.Contact, true)
 		}
 
-		err = tx.Commit()
-		if err != nil {
-			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		if isChanged {
+			err = qtx.UpdateTicket(ctx, ticketParam)
+			if err != nil {
+				return err
+			}
 		}
+
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	t, err := h.Queries.GetTicketByID(ctx, ticketID)
+	t, err := h.Store.GetTicketByID(ctx, ticketID)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	return c.JSON(http.StatusOK, t)
 }
 
+type TicketRef struct {
+	ID uint64 `json:"id" validate:"required"`
+}
+
+// SortTicketsRequest lists every ticket of the status in its new order.
+type SortTicketsRequest struct {
+	Tickets []TicketRef `json:"tickets" validate:"required,dive"`
+}
+
 func (h *Handler) SortTicketsOrder(c echo.Context) error {
 	claims := c.Get("claims").(*auth.Claims)
 
-	boardID, err := strconv.ParseUint(c.Param("board_id"), 10, 32)
+	boardID, err := apikit.ParamID(c, "board_id", 32)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
-	statusID, err := strconv.ParseUint(c.Param("status_id"), 10, 32)
+	statusID, err := apikit.ParamID(c, "status_id", 32)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
-	var body struct {
-		Tickets []struct {
-			ID uint64 `json:"id" validate:"required"`
-		} `json:"tickets" validate:"required,dive"`
-	}
+	var body SortTicketsRequest
 
 	err = c.Bind(&body)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	err = c.Validate(&body)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return err
 	}
 
-	var ticketIDs []uint64
-	ticketIDMap := make(map[uint64]bool)
+	order := db.StatusOrder{StatusID: uint32(statusID)}
 	for _, ticket := range body.Tickets {
-		if _, exists := ticketIDMap[ticket.ID]; exists {
-			return echo.NewHTTPError(http.StatusBadRequest, "ticket id must be unique")
-		}
-
-		ticketIDMap[ticket.ID] = true
-		ticketIDs = append(ticketIDs, ticket.ID)
+		order.TicketIDs = append(order.TicketIDs, ticket.ID)
 	}
 
 	ctx := c.Request().Context()
-
-	prectx, cancel := context.WithCancel(ctx)
-	g, prectx := errgroup.WithContext(prectx)
-	defer cancel()
-
-	chtotal := make(chan int64)
-
-	g.Go(func() error {
-		total, err := h.Queries.CountTicketWithBoard(ctx, db.CountTicketWithBoardParams{
-			Ids:     ticketIDs,
+	moved := 0
+	err = store.WithTx(ctx, h.Store, func(qtx store.Querier) error {
+		_, err := qtx.GetStatusWithBoard(ctx, db.GetStatusWithBoardParams{
+			ID:      uint32(statusID),
 			BoardID: uint32(boardID),
 			UserID:  claims.UserID,
 		})
 		if err != nil {
-			cancel()
+			if err == sql.ErrNoRows {
+				return echo.NewHTTPError(http.StatusNotFound, "status not found")
+			}
 
 			return err
 		}
 
-		chtotal <- total
-
-		return nil
-	})
-
-	statusWithBoard, err := h.Queries.GetStatusWithBoard(ctx, db.GetStatusWithBoardParams{
-		ID:      uint32(statusID),
-		BoardID: uint32(boardID),
-		UserID:  claims.UserID,
-	})
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return echo.NewHTTPError(http.StatusNotFound, "status not found")
+		positions, n, err := store.PlanReorder(ctx, qtx, uint32(boardID), claims.UserID, order)
+		if errors.Is(err, db.ErrLayout) {
+			return apikit.NewError(http.StatusBadRequest, wire.CodeInvalidLayout, err.Error())
 		}
 
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-	}
-
-	select {
-	case <-prectx.Done():
-		return echo.NewHTTPError(http.StatusInternalServerError, prectx.Err().Error())
-	case total := <-chtotal:
-		if total != int64(len(ticketIDs)) {
-			return echo.NewHTTPError(http.StatusBadRequest, "some ticket's id does exist")
+		if err != nil {
+			return err
 		}
-	}
-
-	err = g.Wait()
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-	}
+		moved = n
 
-	tx, err := h.DB.Begin()
+		return qtx.ReorderTickets(ctx, positions)
+	})
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-	}
-	defer tx.Rollback()
-	qtx := h.Queries.WithTx(tx)
-
-	subctx, cancel := context.WithCancel(ctx)
-	g, subctx = errgroup.WithContext(subctx)
-	defer cancel()
-	for i, t := range body.Tickets {
-		i := i
-		t := t
-		g.Go(func() error {
-			err = qtx.UpdateTicketSortOrderAndStatusID(subctx, db.UpdateTicketSortOrderAndStatusIDParams{
-				StatusID:  uint32(statusWithBoard.Status.ID),
-				SortOrder: uint32(i + 1),
-				ID:        t.ID,
-			})
-			if err != nil {
-				return err
-			}
-
-			return nil
-		})
+		return err
 	}
 
 	statusIds := []uint32{uint32(statusID)}
 
-	err = g.Wait()
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-	}
-
-	err = tx.Commit()
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-	}
+	metrics.TicketsMoved.Add(float64(moved))
 
-	tickets, err := h.Queries.GetTickets(ctx, db.GetTicketsParams{
+	tickets, err := h.Store.GetTickets(ctx, db.GetTicketsParams{
 		StatusIds:          statusIds,
 		SortOrderDirection: null.StringFrom("asc"),
 	})
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	return c.JSON(http.StatusOK, tickets)
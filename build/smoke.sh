@@ -0,0 +1,70 @@
+#!/bin/sh
+# Builds the production image of each service and checks that it starts and
+# answers /health. The services run on the local profile, so authen and ticket
+# use SQLite inside the container and need no MySQL.
+#
+#	build/smoke.sh                  gateway, authen and ticket
+#	build/smoke.sh gateway          one service
+set -eu
+
+cd "$(dirname "$0")/.."
+
+if [ ! -f certs/private_key.pem ] || [ ! -f certs/public_key.pem ]; then
+	echo "certs/private_key.pem and certs/public_key.pem are required, see README.md" >&2
+	exit 1
+fi
+
+port() {
+	case "$1" in
+	gateway) echo 3999 ;;
+	authen) echo 4000 ;;
+	ticket) echo 4001 ;;
+	*)
+		echo "unknown service $1" >&2
+		exit 1
+		;;
+	esac
+}
+
+smoke() {
+	service=$1
+	image="ticket-$service:smoke"
+
+	docker build -q -f "build/$service/Dockerfile" -t "$image" . >/dev/null
+
+	container=$(docker run -d -p "127.0.0.1::$(port "$service")" \
+		-e TICKET_PROFILE=local \
+		-e TICKET_INTERNAL_IDENTITY_SECRET=smoke \
+		"$image")
+	trap 'docker rm -f "$container" >/dev/null' EXIT
+
+	addr=$(docker port "$container" "$(port "$service")" | head -n 1)
+	for _ in $(seq 30); do
+		if curl -fsS "http://$addr/health" >/dev/null 2>&1; then
+			echo "$service: ok"
+			docker rm -f "$container" >/dev/null
+			trap - EXIT
+
+			return
+		fi
+
+		if [ "$(docker inspect -f '{{.State.Running}}' "$container")" != true ]; then
+			break
+		fi
+
+		sleep 1
+	done
+
+	echo "$service: did not answer /health" >&2
+	docker logs "$container" >&2
+
+	exit 1
+}
+
+if [ $# -eq 0 ]; then
+	set -- gateway authen ticket
+fi
+
+for service in "$@"; do
+	smoke "$service"
+done
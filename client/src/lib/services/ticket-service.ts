@@ -119,7 +119,6 @@ function updateTicketPartial(
 		description?: string;
 		contact?: string;
 		status_id?: number;
-		sort_order?: number;
 	}
 ) {
 	return http().patch<TicketService.Ticket>(
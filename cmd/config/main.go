@@ -0,0 +1,23 @@
+// Command config prints the effective configuration, after the profile file
+// and environment overrides are applied, with secrets masked.
+package main
+
+import (
+	"fmt"
+	"os"
+	"ticket/config"
+)
+
+func main() {
+	err := config.PrintEffective(os.Stdout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	_, err = config.ReadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
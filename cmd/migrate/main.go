@@ -0,0 +1,116 @@
+// Command migrate manages the database schema.
+//
+//	migrate up        apply every pending migration
+//	migrate down      revert the latest applied migration
+//	migrate to N      apply or revert until N is the latest applied version
+//	migrate status    list migrations and when they were applied
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"ticket/config"
+	"ticket/migration"
+	"ticket/pkg/apikit"
+	"ticket/pkg/migrate"
+	"ticket/pkg/util"
+
+	_ "github.com/go-sql-driver/mysql"
+	_ "modernc.org/sqlite"
+)
+
+func main() {
+	err := run(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: migrate up|down|status|to <version>")
+	}
+
+	cf, err := config.ReadConfig()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	db, err := apikit.ConnectDBContext(ctx, apikit.NewDBConfig(cf))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	driver := cf.Services.Database.Driver
+	if driver == "" {
+		driver = "mysql"
+	}
+
+	m, err := migrate.New(db, driver, migration.Schema, migration.Dir(driver))
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		err = m.Up(ctx)
+	case "down":
+		err = m.Down(ctx)
+	case "to":
+		if len(args) != 2 {
+			return errors.New("usage: migrate to <version>")
+		}
+
+		var version int64
+		version, err = strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return err
+		}
+
+		err = m.To(ctx, version)
+	case "status":
+		return printStatus(ctx, m)
+	default:
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+
+	if errors.Is(err, migrate.ErrNoChange) {
+		fmt.Println("no change")
+
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return printStatus(ctx, m)
+}
+
+func printStatus(ctx context.Context, m *migrate.Migrator) error {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+	for _, s := range statuses {
+		appliedAt := "pending"
+		if s.AppliedAt != nil {
+			appliedAt = s.AppliedAt.Format(util.TimeFormat)
+		}
+
+		fmt.Fprintf(w, "%d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+	}
+
+	return w.Flush()
+}
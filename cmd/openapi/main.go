@@ -0,0 +1,85 @@
+// Command openapi prints the OpenAPI document of a service, or checks that the
+// document describes every route the service registers.
+//
+//	openapi authen|ticket          print the service's openapi.json
+//	openapi -check authen|ticket   exit 1 listing the routes it is missing
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"ticket/api/authen"
+	"ticket/api/ticket"
+	"ticket/pkg/apikit"
+	"ticket/pkg/openapi"
+)
+
+type service struct {
+	router apikit.Router
+	spec   func() *openapi.Document
+}
+
+var services = map[string]service{
+	"authen": {router: authen.Router, spec: authen.Spec},
+	"ticket": {router: ticket.Router, spec: ticket.Spec},
+}
+
+func main() {
+	err := run(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	flags := flag.NewFlagSet("openapi", flag.ContinueOnError)
+	check := flags.Bool("check", false, "fail when a registered route is missing from the document")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if flags.NArg() == 0 {
+		return errors.New("usage: openapi [-check] authen|ticket...")
+	}
+
+	var failed bool
+	for _, name := range flags.Args() {
+		s, ok := services[name]
+		if !ok {
+			return fmt.Errorf("unknown service %q", name)
+		}
+
+		// Mount registers the routes without a database or a listener; the
+		// handlers are never called.
+		doc := s.spec()
+		api := apikit.NewAPI(apikit.WithLog(apikit.LogConfig{Level: "error"})).UseRouter(s.router).UseSpec(doc)
+		api.Mount()
+
+		if !*check {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			err = enc.Encode(doc)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		for _, route := range api.Missing() {
+			fmt.Fprintf(os.Stderr, "%s: %s is missing from the OpenAPI document\n", name, route)
+			failed = true
+		}
+	}
+
+	if failed {
+		return errors.New("the OpenAPI documents are out of date; add the routes above to Spec next to their router")
+	}
+
+	return nil
+}
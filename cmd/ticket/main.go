@@ -3,10 +3,11 @@ package main
 import (
 	"ticket/api/ticket"
 	"ticket/config"
+	"ticket/migration"
 	"ticket/pkg/apikit"
-	"time"
 
 	_ "github.com/go-sql-driver/mysql"
+	_ "modernc.org/sqlite"
 )
 
 func main() {
@@ -26,17 +27,26 @@ func main() {
 	}
 
 	apikit.NewAPI(apikit.WithAPI(apikit.APIConfig{
-		Label: "Ticket",
-		Host:  cf.Services.Ticket.Host,
-		Port:  cf.Services.Ticket.Port,
-	}), apikit.WithDB(apikit.DBConfig{
-		Host:     cf.Services.Database.Host,
-		Name:     cf.Services.Database.Dbname,
-		User:     cf.Services.Database.User,
-		Password: cf.Services.Database.Password,
-		TimeOut:  5 * time.Second,
-	}), apikit.WithGlobal(cf), apikit.WithCerts(apikit.Certs{
+		Label:           "Ticket",
+		Host:            cf.Services.Ticket.Host,
+		Port:            cf.Services.Ticket.Port,
+		ShutdownTimeout: cf.ShutdownTimeout,
+		DrainPeriod:     cf.DrainPeriod,
+		TrustedProxies:  cf.TrustedProxies,
+	}), apikit.WithDB(apikit.NewDBConfig(cf)), apikit.WithMigrations(apikit.MigrationConfig{
+		Source: migration.Schema,
+		Dir:    migration.Dir(cf.Services.Database.Driver),
+		Auto:   cf.Services.Database.AutoMigrate,
+	}), apikit.WithGlobal(cf), apikit.WithTracing(apikit.TracingConfig{
+		Exporter:    cf.Tracing.Exporter,
+		Endpoint:    cf.Tracing.Endpoint,
+		Insecure:    cf.Tracing.Insecure,
+		SampleRatio: cf.Tracing.SampleRatio,
+	}), apikit.WithLog(apikit.LogConfig{
+		Level:  cf.Log.Level,
+		Format: cf.Log.Format,
+	}), apikit.WithCerts(apikit.Certs{
 		PrivateKey: pri,
 		PublicKey:  pub,
-	})).UseRouter(ticket.Router).Start()
+	})).UseRouter(ticket.Router).UseSpec(ticket.Spec()).Start()
 }
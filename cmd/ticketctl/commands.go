@@ -0,0 +1,466 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"ticket/pkg/client"
+	"ticket/pkg/db"
+	"ticket/pkg/wire"
+)
+
+type cli struct {
+	path     string
+	settings settings
+	client   *client.Client
+	json     bool
+	out      io.Writer
+	in       *bufio.Reader
+}
+
+func newCLI(path, gateway string, asJSON bool) (*cli, error) {
+	var err error
+	if path == "" {
+		path, err = defaultSettingsPath()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	s, err := loadSettings(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	switch {
+	case gateway != "":
+		s.Gateway = gateway
+	case s.Gateway == "":
+		s.Gateway = defaultGateway
+	}
+
+	c := &cli{
+		path:     path,
+		settings: s,
+		json:     asJSON,
+		out:      os.Stdout,
+		in:       bufio.NewReader(os.Stdin),
+	}
+
+	c.client, err = client.New(s.Gateway, client.WithTokens(s.Tokens), client.OnTokens(c.saveTokens))
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// saveTokens keeps refreshed tokens for the next run. Failing to save them
+// does not fail the command, which already has them.
+func (c *cli) saveTokens(t wire.Tokens) {
+	c.settings.Tokens = t
+
+	err := saveSettings(c.path, c.settings)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ticketctl: failed to save tokens to %s: %v\n", c.path, err)
+	}
+}
+
+func (c *cli) requireLogin() error {
+	if c.settings.Tokens.RefreshToken == "" {
+		return errors.New("not logged in; run ticketctl login")
+	}
+
+	return nil
+}
+
+func (c *cli) login(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("login", flag.ContinueOnError)
+	email := fs.String("email", c.settings.Email, "email to sign in with")
+	passwordStdin := fs.Bool("password-stdin", false, "read the password from stdin, for scripts")
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if *email == "" {
+		*email, err = c.prompt("Email: ")
+		if err != nil {
+			return err
+		}
+	}
+
+	var password string
+	if *passwordStdin {
+		password, err = c.readLine()
+	} else {
+		err = withoutEcho(int(os.Stdin.Fd()), func() error {
+			password, err = c.prompt("Password: ")
+
+			return err
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = c.client.SignIn(ctx, *email, password)
+	if err != nil {
+		return err
+	}
+
+	c.settings.Email = *email
+	err = saveSettings(c.path, c.settings)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Logged in to %s as %s\n", c.settings.Gateway, *email)
+
+	return nil
+}
+
+func (c *cli) logout() error {
+	c.settings.Tokens = wire.Tokens{}
+
+	return saveSettings(c.path, c.settings)
+}
+
+func (c *cli) boards(ctx context.Context, args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+
+	err := c.requireLogin()
+	if err != nil {
+		return err
+	}
+
+	boards, err := c.client.Boards(ctx)
+	if err != nil {
+		return err
+	}
+
+	return c.print(boards, func(w io.Writer) error {
+		return boardsTable(w, boards)
+	})
+}
+
+func (c *cli) board(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+
+	boardID, err := parseID32("board id", args[0])
+	if err != nil {
+		return err
+	}
+
+	err = c.requireLogin()
+	if err != nil {
+		return err
+	}
+
+	board, err := c.client.Board(ctx, boardID)
+	if err != nil {
+		return err
+	}
+
+	return c.print(board, func(w io.Writer) error {
+		fmt.Fprintf(w, "%s (#%d)\n\n", board.Title.String, board.ID)
+
+		return columns(w, board.Statuses)
+	})
+}
+
+// resource runs the subcommands of ticket and status.
+func (c *cli) resource(ctx context.Context, resource, action string, args []string) error {
+	err := c.requireLogin()
+	if err != nil {
+		return err
+	}
+
+	switch resource + " " + action {
+	case "ticket create":
+		return c.createTicket(ctx, args)
+	case "ticket edit":
+		return c.editTicket(ctx, args)
+	case "ticket move":
+		return c.moveTicket(ctx, args)
+	case "ticket reorder":
+		return c.reorderTickets(ctx, args)
+	case "status reorder":
+		return c.reorderStatuses(ctx, args)
+	}
+
+	return fmt.Errorf("unknown command %q\n\n%s", resource+" "+action, usage)
+}
+
+func (c *cli) createTicket(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("ticket create", flag.ContinueOnError)
+	var t client.NewTicket
+	fs.StringVar(&t.Title, "title", "", "title")
+	fs.StringVar(&t.Description, "description", "", "description")
+	fs.StringVar(&t.Contact, "contact", "", "contact")
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(pos) != 2 {
+		return errUsage
+	}
+
+	boardID, err := parseID32("board id", pos[0])
+	if err != nil {
+		return err
+	}
+
+	statusID, err := parseID32("status id", pos[1])
+	if err != nil {
+		return err
+	}
+
+	ticket, err := c.client.CreateTicket(ctx, boardID, statusID, t)
+	if err != nil {
+		return err
+	}
+
+	return c.print(ticket, func(w io.Writer) error {
+		return ticketsTable(w, []db.Ticket{ticket})
+	})
+}
+
+func (c *cli) editTicket(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("ticket edit", flag.ContinueOnError)
+	title := fs.String("title", "", "new title")
+	description := fs.String("description", "", "new description")
+	contact := fs.String("contact", "", "new contact")
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(pos) != 2 {
+		return errUsage
+	}
+
+	boardID, err := parseID32("board id", pos[0])
+	if err != nil {
+		return err
+	}
+
+	ticketID, err := parseID64("ticket id", pos[1])
+	if err != nil {
+		return err
+	}
+
+	// Only the fields whose flags were passed change.
+	var u client.TicketUpdate
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "title":
+			u.Title = title
+		case "description":
+			u.Description = description
+		case "contact":
+			u.Contact = contact
+		}
+	})
+
+	if u == (client.TicketUpdate{}) {
+		return errors.New("nothing to change; pass -title, -description or -contact")
+	}
+
+	statusID, err := c.statusOf(ctx, boardID, ticketID)
+	if err != nil {
+		return err
+	}
+
+	ticket, err := c.client.UpdateTicket(ctx, boardID, statusID, ticketID, u)
+	if err != nil {
+		return err
+	}
+
+	return c.print(ticket, func(w io.Writer) error {
+		return ticketsTable(w, []db.Ticket{ticket})
+	})
+}
+
+func (c *cli) moveTicket(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("ticket move", flag.ContinueOnError)
+	position := fs.Int("position", 0, "position in the status, from 1; 0 puts it last")
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(pos) != 3 {
+		return errUsage
+	}
+
+	boardID, err := parseID32("board id", pos[0])
+	if err != nil {
+		return err
+	}
+
+	ticketID, err := parseID64("ticket id", pos[1])
+	if err != nil {
+		return err
+	}
+
+	statusID, err := parseID32("status id", pos[2])
+	if err != nil {
+		return err
+	}
+
+	statuses, err := c.client.MoveTicket(ctx, boardID, ticketID, statusID, *position-1)
+	if err != nil {
+		return err
+	}
+
+	return c.print(statuses, func(w io.Writer) error {
+		return columns(w, statuses)
+	})
+}
+
+func (c *cli) reorderTickets(ctx context.Context, args []string) error {
+	if len(args) < 2 {
+		return errUsage
+	}
+
+	boardID, err := parseID32("board id", args[0])
+	if err != nil {
+		return err
+	}
+
+	statusID, err := parseID32("status id", args[1])
+	if err != nil {
+		return err
+	}
+
+	ticketIDs := make([]uint64, 0, len(args)-2)
+	for _, arg := range args[2:] {
+		id, err := parseID64("ticket id", arg)
+		if err != nil {
+			return err
+		}
+
+		ticketIDs = append(ticketIDs, id)
+	}
+
+	tickets, err := c.client.SortTickets(ctx, boardID, statusID, ticketIDs...)
+	if err != nil {
+		return err
+	}
+
+	return c.print(tickets, func(w io.Writer) error {
+		return ticketsTable(w, tickets)
+	})
+}
+
+func (c *cli) reorderStatuses(ctx context.Context, args []string) error {
+	if len(args) < 2 {
+		return errUsage
+	}
+
+	boardID, err := parseID32("board id", args[0])
+	if err != nil {
+		return err
+	}
+
+	statusIDs := make([]uint32, 0, len(args)-1)
+	for _, arg := range args[1:] {
+		id, err := parseID32("status id", arg)
+		if err != nil {
+			return err
+		}
+
+		statusIDs = append(statusIDs, id)
+	}
+
+	statuses, err := c.client.SortStatuses(ctx, boardID, statusIDs...)
+	if err != nil {
+		return err
+	}
+
+	return c.print(statuses, func(w io.Writer) error {
+		return columns(w, statuses)
+	})
+}
+
+// statusOf finds the status a ticket is in, which the API wants in the path.
+func (c *cli) statusOf(ctx context.Context, boardID uint32, ticketID uint64) (uint32, error) {
+	board, err := c.client.Board(ctx, boardID)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, s := range board.Statuses {
+		for _, t := range s.Tickets {
+			if t.ID == ticketID {
+				return s.ID, nil
+			}
+		}
+	}
+
+	return 0, fmt.Errorf("ticket %d is not on board %d", ticketID, boardID)
+}
+
+func (c *cli) prompt(label string) (string, error) {
+	fmt.Fprint(os.Stderr, label)
+
+	return c.readLine()
+}
+
+func (c *cli) readLine() (string, error) {
+	line, err := c.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// parseArgs parses fs from args with flags before, between or after the
+// positional arguments, and returns the positional ones.
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var pos []string
+	for {
+		err := fs.Parse(args)
+		if err != nil {
+			return nil, err
+		}
+
+		if fs.NArg() == 0 {
+			return pos, nil
+		}
+
+		pos = append(pos, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+func parseID32(name, s string) (uint32, error) {
+	id, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q", name, s)
+	}
+
+	return uint32(id), nil
+}
+
+func parseID64(name, s string) (uint64, error) {
+	id, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q", name, s)
+	}
+
+	return id, nil
+}
@@ -0,0 +1,97 @@
+// Command ticketctl works with boards through the gateway from a terminal.
+//
+//	ticketctl login [-email E] [-password-stdin]
+//	ticketctl logout
+//	ticketctl boards
+//	ticketctl board <board-id>
+//	ticketctl ticket create <board-id> <status-id> -title T -description D -contact C
+//	ticketctl ticket edit <board-id> <ticket-id> [-title T] [-description D] [-contact C]
+//	ticketctl ticket move <board-id> <ticket-id> <status-id> [-position N]
+//	ticketctl ticket reorder <board-id> <status-id> <ticket-id>...
+//	ticketctl status reorder <board-id> <status-id>...
+//
+// Global flags go before the command: -o json prints what the API returns
+// instead of tables, -gateway sets the gateway's URL and -config the file
+// login saves it and the tokens to.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+)
+
+const usage = `usage: ticketctl [-o table|json] [-gateway URL] [-config FILE] <command>
+
+commands:
+  login [-email E] [-password-stdin]   sign in and save the tokens
+  logout                               forget the tokens
+  boards                               list your boards
+  board <board-id>                     print a board as columns
+  ticket create <board-id> <status-id> -title T -description D -contact C
+  ticket edit <board-id> <ticket-id> [-title T] [-description D] [-contact C]
+  ticket move <board-id> <ticket-id> <status-id> [-position N]
+  ticket reorder <board-id> <status-id> <ticket-id>...
+  status reorder <board-id> <status-id>...`
+
+// errUsage makes main print the usage.
+var errUsage = errors.New(usage)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	err := run(ctx, os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("ticketctl", flag.ContinueOnError)
+	flags.Usage = func() { fmt.Fprintln(flags.Output(), usage) }
+	output := flags.String("o", "table", "output format, table or json")
+	gateway := flags.String("gateway", "", "gateway URL, saved by login (default http://localhost:3999)")
+	configPath := flags.String("config", os.Getenv("TICKETCTL_CONFIG"), "settings file (default in the user config directory)")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if *output != "table" && *output != "json" {
+		return fmt.Errorf("unknown output format %q", *output)
+	}
+
+	if flags.NArg() == 0 {
+		return errUsage
+	}
+
+	cli, err := newCLI(*configPath, *gateway, *output == "json")
+	if err != nil {
+		return err
+	}
+
+	cmd, rest := flags.Arg(0), flags.Args()[1:]
+	switch cmd {
+	case "login":
+		return cli.login(ctx, rest)
+	case "logout":
+		return cli.logout()
+	case "boards":
+		return cli.boards(ctx, rest)
+	case "board":
+		return cli.board(ctx, rest)
+	case "ticket", "status":
+		if len(rest) == 0 {
+			return errUsage
+		}
+
+		return cli.resource(ctx, cmd, rest[0], rest[1:])
+	}
+
+	return fmt.Errorf("unknown command %q\n\n%s", cmd, usage)
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"ticket/pkg/db"
+	"ticket/pkg/util"
+	"unicode"
+
+	null "github.com/guregu/null/v5"
+)
+
+// maxColumnWidth keeps a board with a few statuses within a terminal.
+const maxColumnWidth = 32
+
+// print writes v as JSON with -o json, and with table otherwise.
+func (c *cli) print(v any, table func(w io.Writer) error) error {
+	if c.json {
+		enc := json.NewEncoder(c.out)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(v)
+	}
+
+	return table(c.out)
+}
+
+func boardsTable(out io.Writer, boards []db.Board) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTITLE\tUPDATED AT")
+	for _, b := range boards {
+		fmt.Fprintf(w, "%d\t%s\t%s\n", b.ID, b.Title.String, formatTime(b.UpdatedAt, b.CreatedAt))
+	}
+
+	return w.Flush()
+}
+
+func ticketsTable(out io.Writer, tickets []db.Ticket) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSTATUS\tTITLE\tCONTACT\tUPDATED AT")
+	for _, t := range tickets {
+		fmt.Fprintf(w, "%d\t%d\t%s\t%s\t%s\n", t.ID, t.StatusID, t.Title.String, t.Contact.String, formatTime(t.UpdatedAt, t.CreatedAt))
+	}
+
+	return w.Flush()
+}
+
+// columns prints statuses side by side, with their tickets in order below.
+// Long titles are cut to fit maxColumnWidth.
+func columns(out io.Writer, statuses []db.StatusWithRelated) error {
+	cells := make([][]string, len(statuses))
+	widths := make([]int, len(statuses))
+	rows := 0
+	for i, s := range statuses {
+		cells[i] = append(cells[i], fmt.Sprintf("%s (#%d)", s.Title.String, s.ID))
+		for _, t := range s.Tickets {
+			cells[i] = append(cells[i], fmt.Sprintf("#%d %s", t.ID, t.Title.String))
+		}
+
+		for j, cell := range cells[i] {
+			cells[i][j] = truncate(cell, maxColumnWidth)
+			widths[i] = max(widths[i], width(cells[i][j]))
+		}
+
+		rows = max(rows, len(cells[i]))
+	}
+
+	var b strings.Builder
+	for row := 0; row < rows; row++ {
+		var line strings.Builder
+		for i := range statuses {
+			cell := ""
+			if row < len(cells[i]) {
+				cell = cells[i][row]
+			}
+
+			if row == 1 && len(cells[i]) == 1 {
+				cell = "-"
+			}
+
+			line.WriteString(cell)
+			if i < len(statuses)-1 {
+				line.WriteString(strings.Repeat(" ", widths[i]-width(cell)+3))
+			}
+		}
+
+		b.WriteString(strings.TrimRight(line.String(), " ") + "\n")
+		if row == 0 {
+			for i := range statuses {
+				b.WriteString(strings.Repeat("─", widths[i]))
+				if i < len(statuses)-1 {
+					b.WriteString("   ")
+				}
+			}
+
+			b.WriteString("\n")
+		}
+	}
+
+	_, err := io.WriteString(out, b.String())
+
+	return err
+}
+
+// truncate cuts s to max columns, keeping combining marks with their base.
+func truncate(s string, max int) string {
+	if width(s) <= max {
+		return s
+	}
+
+	n := 0
+	for i, r := range s {
+		if !isMark(r) {
+			n++
+		}
+
+		if n == max {
+			return s[:i] + "…"
+		}
+	}
+
+	return s
+}
+
+// width counts the columns s takes in a terminal. Combining marks, like most
+// Thai vowels and tone marks, share the column of the letter before them.
+func width(s string) int {
+	n := 0
+	for _, r := range s {
+		if !isMark(r) {
+			n++
+		}
+	}
+
+	return n
+}
+
+func isMark(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf)
+}
+
+// formatTime shows the first time that is set, as the services store them.
+func formatTime(times ...null.Time) string {
+	for _, t := range times {
+		if t.Valid {
+			return t.Time.Format(util.TimeFormat)
+		}
+	}
+
+	return "-"
+}
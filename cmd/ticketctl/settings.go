@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"ticket/pkg/wire"
+)
+
+const defaultGateway = "http://localhost:3999"
+
+// settings is what login saves between runs. The file holds tokens, so it is
+// only readable by its owner.
+type settings struct {
+	Gateway string      `json:"gateway"`
+	Email   string      `json:"email,omitempty"`
+	Tokens  wire.Tokens `json:"tokens"`
+}
+
+func defaultSettingsPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "ticketctl", "config.json"), nil
+}
+
+// loadSettings reads path, or returns empty settings when it does not exist.
+func loadSettings(path string) (settings, error) {
+	var s settings
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+
+	if err != nil {
+		return s, err
+	}
+
+	err = json.Unmarshal(b, &s)
+
+	return s, err
+}
+
+func saveSettings(path string, s settings) error {
+	err := os.MkdirAll(filepath.Dir(path), 0o700)
+	if err != nil {
+		return err
+	}
+
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write then rename, so a failed write does not lose the saved tokens.
+	tmp := path + ".tmp"
+	err = os.WriteFile(tmp, append(b, '\n'), 0o600)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// withoutEcho runs fn with echo turned off on the terminal fd, so a typed
+// password is not shown. When fd is not a terminal fn runs as is.
+func withoutEcho(fd int, fn func() error) error {
+	state, err := unix.IoctlGetTermios(fd, unix.TCGETS)
+	if err != nil {
+		return fn()
+	}
+
+	quiet := *state
+	quiet.Lflag &^= unix.ECHO
+	err = unix.IoctlSetTermios(fd, unix.TCSETS, &quiet)
+	if err != nil {
+		return fn()
+	}
+
+	defer func() {
+		unix.IoctlSetTermios(fd, unix.TCSETS, state)
+		fmt.Fprintln(os.Stderr)
+	}()
+
+	return fn()
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package main
+
+// withoutEcho runs fn as is: outside Linux the typed password is shown. Use
+// login -password-stdin to keep it off the screen.
+func withoutEcho(_ int, fn func() error) error {
+	return fn()
+}
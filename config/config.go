@@ -3,16 +3,19 @@ package config
 import (
 	"fmt"
 	"os"
-
-	"github.com/spf13/viper"
+	"time"
 )
 
 type Config struct {
 	Services struct {
 		Gateway struct {
-			Host string `mapstructure:"host"`
-			Port int    `mapstructure:"port"`
-			URL  string `mapstructure:"url"`
+			Host           string         `mapstructure:"host"`
+			Port           int            `mapstructure:"port"`
+			URL            string         `mapstructure:"url"`
+			Routes         []GatewayRoute `mapstructure:"routes"`
+			HealthCheck    HealthCheck    `mapstructure:"health_check"`
+			RateLimit      RateLimit      `mapstructure:"rate_limit"`
+			CircuitBreaker CircuitBreaker `mapstructure:"circuit_breaker"`
 		} `mapstructure:"gateway"`
 		Authen struct {
 			Host string `mapstructure:"host"`
@@ -25,33 +28,143 @@ type Config struct {
 			URL  string `mapstructure:"url"`
 		} `mapstructure:"ticket"`
 		Database struct {
+			// Driver is "mysql", the default, or "sqlite". SQLite keeps the
+			// whole database in the file at Path and ignores the connection
+			// settings below.
+			Driver   string `mapstructure:"driver"`
+			Path     string `mapstructure:"path"`
 			Host     string `mapstructure:"host"`
+			Port     int    `mapstructure:"port"`
 			Dbname   string `mapstructure:"dbname"`
 			User     string `mapstructure:"user"`
 			Password string `mapstructure:"password"`
+			// TLS is "false", "true", "skip-verify" or "preferred".
+			TLS string `mapstructure:"tls"`
+			// Timeout bounds single database calls made by handlers.
+			Timeout         time.Duration `mapstructure:"timeout"`
+			DialTimeout     time.Duration `mapstructure:"dial_timeout"`
+			ReadTimeout     time.Duration `mapstructure:"read_timeout"`
+			WriteTimeout    time.Duration `mapstructure:"write_timeout"`
+			MaxOpenConns    int           `mapstructure:"max_open_conns"`
+			MaxIdleConns    int           `mapstructure:"max_idle_conns"`
+			ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime"`
+			ConnMaxIdleTime time.Duration `mapstructure:"conn_max_idle_time"`
+			Charset         string        `mapstructure:"charset"`
+			Collation       string        `mapstructure:"collation"`
+			// TimeZone is an IANA name used to read and write DATETIME values.
+			TimeZone string `mapstructure:"time_zone"`
+			// ConnectAttempts bounds the startup connection attempts. The wait
+			// between them starts at ConnectBackoff and doubles up to
+			// ConnectMaxBackoff.
+			ConnectAttempts   int           `mapstructure:"connect_attempts"`
+			ConnectBackoff    time.Duration `mapstructure:"connect_backoff"`
+			ConnectMaxBackoff time.Duration `mapstructure:"connect_max_backoff"`
+			// AutoMigrate applies pending migrations when a service starts.
+			AutoMigrate bool `mapstructure:"auto_migrate"`
 		}
 	} `mapstructure:"services"`
-	PrivateKey         string `mapstructure:"private_key"`
-	PublicKey          string `mapstructure:"public_key"`
-	AccessTokenExpire  int    `mapstructure:"access_token_expire"`
-	RefreshTokenExpire int    `mapstructure:"refresh_token_expire"`
+	// ShutdownTimeout is how long in-flight requests get to finish after
+	// SIGTERM before the server closes them.
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+	// DrainPeriod is how long a service keeps serving after SIGTERM with
+	// /ready failing, so load balancers stop sending it requests first.
+	DrainPeriod time.Duration `mapstructure:"drain_period"`
+	// TrustedProxies are the CIDR ranges whose X-Forwarded-For is believed
+	// when finding the client IP. Empty means the connection's own address.
+	TrustedProxies     []string `mapstructure:"trusted_proxies"`
+	PrivateKey         string   `mapstructure:"private_key"`
+	PublicKey          string   `mapstructure:"public_key"`
+	AccessTokenExpire  int      `mapstructure:"access_token_expire"`
+	RefreshTokenExpire int      `mapstructure:"refresh_token_expire"`
+	IdentitySecret     string   `mapstructure:"internal_identity_secret"`
+	Tracing            struct {
+		// Exporter is "otlp", "stdout" or empty to disable tracing.
+		Exporter    string  `mapstructure:"exporter"`
+		Endpoint    string  `mapstructure:"endpoint"`
+		Insecure    bool    `mapstructure:"insecure"`
+		SampleRatio float64 `mapstructure:"sample_ratio"`
+	} `mapstructure:"tracing"`
+	Log struct {
+		// Level is "debug", "info", "warn" or "error".
+		Level string `mapstructure:"level"`
+		// Format is "json" or "text".
+		Format string `mapstructure:"format"`
+	} `mapstructure:"log"`
+	OIDC struct {
+		Providers map[string]OIDCProvider `mapstructure:"providers"`
+	} `mapstructure:"oidc"`
 }
 
-func ReadConfig() (Config, error) {
-	viper.SetConfigType("yaml")
-	viper.SetConfigName("config")
-	viper.AddConfigPath("./config")
+type GatewayRoute struct {
+	Prefix      string   `mapstructure:"prefix"`
+	Upstreams   []string `mapstructure:"upstreams"`
+	StripPrefix bool     `mapstructure:"strip_prefix"`
+	// Timeout bounds each attempt against an upstream, including reading the
+	// response body.
+	Timeout        time.Duration `mapstructure:"timeout"`
+	ConnectTimeout time.Duration `mapstructure:"connect_timeout"`
+	// Retries is how many more upstreams a bodiless idempotent request may try
+	// after a connection error.
+	Retries      int  `mapstructure:"retries"`
+	AuthRequired bool `mapstructure:"auth_required"`
+	// AnonymousPaths are relative to Prefix and skip AuthRequired. A trailing
+	// "/*" matches everything below that path.
+	AnonymousPaths []string `mapstructure:"anonymous_paths"`
+}
 
-	err := viper.ReadInConfig()
+type HealthCheck struct {
+	Path     string        `mapstructure:"path"`
+	Interval time.Duration `mapstructure:"interval"`
+	Timeout  time.Duration `mapstructure:"timeout"`
+}
+
+type CircuitBreaker struct {
+	FailureThreshold int           `mapstructure:"failure_threshold"`
+	OpenTimeout      time.Duration `mapstructure:"open_timeout"`
+}
+
+type RateLimit struct {
+	RequestsPerMinute int              `mapstructure:"requests_per_minute"`
+	Burst             int              `mapstructure:"burst"`
+	Routes            []RateLimitRoute `mapstructure:"routes"`
+}
+
+type RateLimitRoute struct {
+	Method            string `mapstructure:"method"`
+	Path              string `mapstructure:"path"`
+	RequestsPerMinute int    `mapstructure:"requests_per_minute"`
+	Burst             int    `mapstructure:"burst"`
+}
+
+type OIDCProvider struct {
+	Issuer            string   `mapstructure:"issuer"`
+	ClientID          string   `mapstructure:"client_id"`
+	ClientSecret      string   `mapstructure:"client_secret"`
+	RedirectURL       string   `mapstructure:"redirect_url"`
+	ClientRedirectURL string   `mapstructure:"client_redirect_url"`
+	Scopes            []string `mapstructure:"scopes"`
+}
+
+// ReadConfig loads ./config/config.yaml, then the profile file named by
+// TICKET_PROFILE (e.g. config.prod.yaml) over it, then environment overrides,
+// and validates the result.
+func ReadConfig() (Config, error) {
+	v, err := load()
 	if err != nil {
 		return Config{}, err
 	}
+
 	var config Config
-	err = viper.Unmarshal(&config)
+	err = v.Unmarshal(&config)
 	if err != nil {
 		return Config{}, fmt.Errorf("\nunable to decode into struct: %s", err)
 	}
 
+	err = config.Validate()
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid config:\n%w", err)
+	}
+
 	return config, nil
 }
 
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	envPrefix  = "TICKET"
+	profileEnv = envPrefix + "_PROFILE"
+	// fileSuffix marks an environment variable that holds the path of a file
+	// with the value, e.g. TICKET_SERVICES_DATABASE_PASSWORD_FILE.
+	fileSuffix = "_FILE"
+)
+
+func load() (*viper.Viper, error) {
+	v := viper.New()
+	v.SetConfigType("yaml")
+	v.SetConfigName("config")
+	v.AddConfigPath("./config")
+
+	err := v.ReadInConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if profile := os.Getenv(profileEnv); profile != "" {
+		v.SetConfigName("config." + profile)
+
+		err = v.MergeInConfig()
+		if err != nil {
+			return nil, fmt.Errorf("profile %s: %w", profile, err)
+		}
+	}
+
+	v.SetEnvPrefix(envPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+	bindEnvs(v, reflect.TypeOf(Config{}), "")
+
+	err = readSecretFiles(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// bindEnvs registers every key of t with viper. AutomaticEnv alone only
+// overrides keys that already appear in a config file.
+func bindEnvs(v *viper.Viper, t reflect.Type, prefix string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		key := f.Tag.Get("mapstructure")
+		if key == "" {
+			key = strings.ToLower(f.Name)
+		}
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		if f.Type.Kind() == reflect.Struct && f.Type.PkgPath() != "time" {
+			bindEnvs(v, f.Type, key)
+
+			continue
+		}
+
+		v.BindEnv(key)
+	}
+}
+
+func envName(key string) string {
+	return envPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+func readSecretFiles(v *viper.Viper) error {
+	for _, key := range v.AllKeys() {
+		path := os.Getenv(envName(key) + fileSuffix)
+		if path == "" {
+			continue
+		}
+
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", envName(key)+fileSuffix, err)
+		}
+
+		v.Set(key, strings.TrimSpace(string(b)))
+	}
+
+	return nil
+}
+
+// PrintEffective writes the merged configuration as YAML with secrets masked.
+func PrintEffective(w io.Writer) error {
+	v, err := load()
+	if err != nil {
+		return err
+	}
+
+	settings := v.AllSettings()
+	mask(settings)
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	defer enc.Close()
+
+	return enc.Encode(settings)
+}
+
+func isSecretKey(key string) bool {
+	key = strings.ToLower(key)
+
+	return strings.Contains(key, "password") || strings.Contains(key, "secret")
+}
+
+func mask(settings map[string]any) {
+	for k, v := range settings {
+		switch val := v.(type) {
+		case map[string]any:
+			mask(val)
+		case []any:
+			for _, item := range val {
+				if m, ok := item.(map[string]any); ok {
+					mask(m)
+				}
+			}
+		default:
+			if isSecretKey(k) && fmt.Sprint(val) != "" {
+				settings[k] = "********"
+			}
+		}
+	}
+}
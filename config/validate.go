@@ -0,0 +1,109 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Validate reports every problem in c at once, one per line, so a broken
+// deployment can be fixed in a single pass.
+func (c Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	s := c.Services
+	for name, port := range map[string]int{
+		"gateway": s.Gateway.Port,
+		"authen":  s.Authen.Port,
+		"ticket":  s.Ticket.Port,
+	} {
+		check(port > 0 && port < 65536, "services.%s.port: must be between 1 and 65535, got %d", name, port)
+	}
+
+	mysql := false
+	switch s.Database.Driver {
+	case "", "mysql":
+		mysql = true
+		check(s.Database.Host != "", "services.database.host: required")
+		check(s.Database.Dbname != "", "services.database.dbname: required")
+		check(s.Database.User != "", "services.database.user: required")
+		check(s.Database.Password != "", "services.database.password: required, e.g. from TICKET_SERVICES_DATABASE_PASSWORD")
+	case "sqlite":
+		check(s.Database.Path != "", "services.database.path: required with the sqlite driver")
+	default:
+		check(false, "services.database.driver: must be mysql or sqlite, got %q", s.Database.Driver)
+	}
+	check(s.Database.ConnectAttempts >= 0, "services.database.connect_attempts: must not be negative")
+	check(s.Database.MaxOpenConns >= 0, "services.database.max_open_conns: must not be negative")
+
+	switch s.Database.TLS {
+	case "", "false", "true", "skip-verify", "preferred":
+	default:
+		check(false, "services.database.tls: must be false, true, skip-verify or preferred, got %q", s.Database.TLS)
+	}
+
+	if s.Database.TimeZone != "" {
+		_, err := time.LoadLocation(s.Database.TimeZone)
+		check(err == nil, "services.database.time_zone: %v", err)
+	}
+
+	authRequired := false
+	for i, r := range s.Gateway.Routes {
+		check(strings.HasPrefix(r.Prefix, "/"), "services.gateway.routes[%d].prefix: must start with /", i)
+		check(len(r.Upstreams) > 0, "services.gateway.routes[%d].upstreams: at least one is required", i)
+		for _, raw := range r.Upstreams {
+			u, err := url.Parse(raw)
+			check(err == nil && u.Scheme != "" && u.Host != "", "services.gateway.routes[%d].upstreams: invalid URL %q", i, raw)
+		}
+
+		authRequired = authRequired || r.AuthRequired
+	}
+
+	check(!(mysql || authRequired) || c.IdentitySecret != "", "internal_identity_secret: required with the mysql driver or when a gateway route has auth_required, e.g. from TICKET_INTERNAL_IDENTITY_SECRET")
+
+	check(c.DrainPeriod >= 0, "drain_period: must not be negative")
+
+	for _, cidr := range c.TrustedProxies {
+		_, _, err := net.ParseCIDR(cidr)
+		check(err == nil, "trusted_proxies: invalid CIDR %q", cidr)
+	}
+
+	check(c.PrivateKey != "", "private_key: required")
+	check(c.PublicKey != "", "public_key: required")
+	check(c.AccessTokenExpire > 0, "access_token_expire: must be positive")
+	check(c.RefreshTokenExpire > 0, "refresh_token_expire: must be positive")
+
+	switch c.Tracing.Exporter {
+	case "", "stdout", "otlp":
+	default:
+		check(false, "tracing.exporter: must be otlp, stdout or empty, got %q", c.Tracing.Exporter)
+	}
+
+	switch c.Log.Format {
+	case "", "json", "text":
+	default:
+		check(false, "log.format: must be json or text, got %q", c.Log.Format)
+	}
+
+	if c.Log.Level != "" {
+		var level slog.Level
+		check(level.UnmarshalText([]byte(c.Log.Level)) == nil, "log.level: unknown level %q", c.Log.Level)
+	}
+
+	for name, p := range c.OIDC.Providers {
+		check(p.Issuer != "", "oidc.providers.%s.issuer: required", name)
+		check(p.ClientID != "", "oidc.providers.%s.client_id: required", name)
+		check(p.RedirectURL != "", "oidc.providers.%s.redirect_url: required", name)
+	}
+
+	return errors.Join(errs...)
+}
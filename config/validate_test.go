@@ -0,0 +1,84 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func validConfig() Config {
+	var c Config
+	c.Services.Gateway.Port = 3999
+	c.Services.Authen.Port = 4000
+	c.Services.Ticket.Port = 4001
+	c.Services.Database.Driver = "mysql"
+	c.Services.Database.Host = "mysql"
+	c.Services.Database.Dbname = "ticket"
+	c.Services.Database.User = "root"
+	c.Services.Database.Password = "password"
+	c.IdentitySecret = "secret"
+	c.PrivateKey = "private_key.pem"
+	c.PublicKey = "public_key.pem"
+	c.AccessTokenExpire = 3600
+	c.RefreshTokenExpire = 7200
+
+	return c
+}
+
+func TestValidateSecrets(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+		want   string
+	}{
+		{name: "valid", modify: func(c *Config) {}},
+		{
+			name:   "mysql without password",
+			modify: func(c *Config) { c.Services.Database.Password = "" },
+			want:   "services.database.password: required",
+		},
+		{
+			name:   "mysql without identity secret",
+			modify: func(c *Config) { c.IdentitySecret = "" },
+			want:   "internal_identity_secret: required",
+		},
+		{
+			name: "sqlite without secrets",
+			modify: func(c *Config) {
+				c.Services.Database.Driver = "sqlite"
+				c.Services.Database.Path = "ticket.db"
+				c.Services.Database.Password = ""
+				c.IdentitySecret = ""
+			},
+		},
+		{
+			name: "sqlite with a protected route",
+			modify: func(c *Config) {
+				c.Services.Database.Driver = "sqlite"
+				c.Services.Database.Path = "ticket.db"
+				c.IdentitySecret = ""
+				c.Services.Gateway.Routes = []GatewayRoute{{Prefix: "/ticket-service", Upstreams: []string{"http://ticket:4001"}, AuthRequired: true}}
+			},
+			want: "internal_identity_secret: required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := validConfig()
+			tt.modify(&c)
+
+			err := c.Validate()
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Validate() = %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+}
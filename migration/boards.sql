@@ -15,7 +15,7 @@ WHERE
   id = ?
   AND user_id = ?;
 
--- name: CreateBoard :exec
+-- name: CreateBoard :execresult
 INSERT INTO
   boards (user_id, title, sort_order, created_at)
 VALUES
@@ -44,25 +44,8 @@ FROM
 WHERE
   user_id = ?;
 
--- name: GetLastInsertBoard :one
-SELECT
-  *
-FROM
+-- name: DeleteBoardsByUserID :exec
+DELETE FROM
   boards
 WHERE
-  id = (
-    SELECT
-      LAST_INSERT_ID()
-    FROM
-      boards AS b
-    LIMIT
-      1
-  );
-
--- name: GetLastInsertBoardID :one
-SELECT
-  LAST_INSERT_ID()
-FROM
-  boards
-LIMIT
-  1;
\ No newline at end of file
+  user_id = ?;
\ No newline at end of file
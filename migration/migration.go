@@ -0,0 +1,18 @@
+// Package migration embeds the SQL files: numbered schema migrations under
+// schema/, read by both pkg/migrate and sqlc, and the sqlc query files. The
+// SQLite versions live under sqlite/.
+package migration
+
+import "embed"
+
+//go:embed schema/*.sql sqlite/schema/*.sql
+var Schema embed.FS
+
+// Dir is the directory in Schema holding the migrations for a database driver.
+func Dir(driver string) string {
+	if driver == "sqlite" {
+		return "sqlite/schema"
+	}
+
+	return "schema"
+}
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS tickets;
+DROP TABLE IF EXISTS statuses;
+DROP TABLE IF EXISTS boards;
+DROP TABLE IF EXISTS users;
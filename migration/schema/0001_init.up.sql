@@ -1,3 +1,5 @@
+-- The schema as it was before migrations, so databases created from the old
+-- schema.sql adopt it as version 1 without changes.
 CREATE TABLE IF NOT EXISTS users (
   id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
   name VARCHAR(50),
@@ -38,4 +40,4 @@ CREATE TABLE IF NOT EXISTS tickets (
   created_at DATETIME,
   updated_at DATETIME,
   FOREIGN KEY (status_id) REFERENCES statuses(id)
-);
\ No newline at end of file
+);
@@ -0,0 +1,6 @@
+DROP TABLE user_identities;
+
+ALTER TABLE users
+  DROP COLUMN password_reset_required,
+  DROP COLUMN disabled_at,
+  DROP COLUMN is_admin;
@@ -0,0 +1,16 @@
+ALTER TABLE users
+  ADD COLUMN is_admin BOOLEAN NOT NULL DEFAULT FALSE,
+  ADD COLUMN disabled_at DATETIME,
+  ADD COLUMN password_reset_required BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE user_identities (
+  id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  user_id BIGINT UNSIGNED NOT NULL,
+  provider VARCHAR(50) NOT NULL,
+  subject VARCHAR(255) NOT NULL,
+  email VARCHAR(255),
+  created_at DATETIME,
+  updated_at DATETIME,
+  UNIQUE KEY (provider, subject),
+  FOREIGN KEY (user_id) REFERENCES users(id)
+);
@@ -0,0 +1,45 @@
+-- name: GetBoardsByUserID :many
+SELECT
+  *
+FROM
+  boards
+WHERE
+  user_id = ?;
+
+-- name: GetBoard :one
+SELECT
+  *
+FROM
+  boards
+WHERE
+  id = ?
+  AND user_id = ?;
+
+-- name: CreateBoard :execresult
+INSERT INTO
+  boards (user_id, title, sort_order, created_at)
+VALUES
+  (?, ?, ?, CURRENT_TIMESTAMP);
+
+-- name: UpdateBoard :exec
+UPDATE
+  boards
+SET
+  title = ?,
+  updated_at = CURRENT_TIMESTAMP
+WHERE
+  id = ?;
+
+-- name: CountBoardByUserID :one
+SELECT
+  COUNT(*)
+FROM
+  boards
+WHERE
+  user_id = ?;
+
+-- name: DeleteBoardsByUserID :exec
+DELETE FROM
+  boards
+WHERE
+  user_id = ?;
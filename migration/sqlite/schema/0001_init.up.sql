@@ -0,0 +1,38 @@
+CREATE TABLE IF NOT EXISTS users (
+  id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+  name TEXT COLLATE NOCASE,
+  lastname TEXT COLLATE NOCASE,
+  email TEXT COLLATE NOCASE,
+  password TEXT,
+  created_at DATETIME,
+  updated_at DATETIME
+);
+
+CREATE TABLE IF NOT EXISTS boards (
+  id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+  user_id INTEGER NOT NULL REFERENCES users(id),
+  title TEXT,
+  sort_order INTEGER NOT NULL,
+  created_at DATETIME,
+  updated_at DATETIME
+);
+
+CREATE TABLE IF NOT EXISTS statuses (
+  id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+  board_id INTEGER NOT NULL REFERENCES boards(id),
+  title TEXT,
+  sort_order INTEGER NOT NULL,
+  created_at DATETIME,
+  updated_at DATETIME
+);
+
+CREATE TABLE IF NOT EXISTS tickets (
+  id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+  status_id INTEGER NOT NULL REFERENCES statuses(id),
+  title TEXT,
+  description TEXT,
+  contact TEXT,
+  sort_order INTEGER NOT NULL,
+  created_at DATETIME,
+  updated_at DATETIME
+);
@@ -0,0 +1,111 @@
+-- name: GetStatusWithBoard :one
+SELECT
+  sqlc.embed(statuses),
+  sqlc.embed(boards)
+FROM
+  statuses
+  JOIN boards ON statuses.board_id = boards.id
+WHERE
+  statuses.id = ?
+  AND statuses.board_id = ?
+  AND boards.user_id = ?;
+
+-- name: GetStatus :one
+SELECT
+  *
+FROM
+  statuses
+WHERE
+  id = coalesce(sqlc.narg('id'), id)
+  AND board_id = coalesce(sqlc.narg('board_id'), board_id);
+
+-- name: GetStatuses :many
+SELECT
+  *
+FROM
+  statuses
+WHERE
+  board_id = coalesce(sqlc.narg('board_id'), board_id)
+  AND (
+    id = coalesce(sqlc.slice('ids'), id)
+    OR id IN (sqlc.slice('ids'))
+  )
+ORDER BY
+  board_id ASC,
+  (
+    CASE
+      WHEN sqlc.arg('sort_order_direction') = 'asc' THEN sort_order
+    END
+  ) ASC,
+  (
+    CASE
+      WHEN sqlc.arg('sort_order_direction') = 'desc' THEN sort_order
+    END
+  ) DESC;
+
+-- name: CreateStatus :execresult
+INSERT INTO
+  statuses (board_id, title, sort_order, created_at)
+VALUES
+  (?, ?, ?, CURRENT_TIMESTAMP);
+
+-- name: UpdateStatus :exec
+UPDATE
+  statuses
+SET
+  title = ?,
+  sort_order = ?,
+  updated_at = CURRENT_TIMESTAMP
+WHERE
+  id = ?;
+
+-- name: UpdateStatusSortOrder :exec
+UPDATE
+  statuses
+SET
+  sort_order = ?
+WHERE
+  id = ?;
+
+-- name: CountStatusByBoardID :one
+SELECT
+  COUNT(*)
+FROM
+  statuses
+WHERE
+  board_id = ?;
+
+-- name: CountStatusWithBoard :one
+SELECT
+  COUNT(statuses.id)
+FROM
+  statuses
+  JOIN boards ON statuses.board_id = boards.id
+WHERE
+  statuses.id IN (sqlc.slice('ids'))
+  AND statuses.board_id = ?
+  AND boards.user_id = ?;
+
+-- name: CountStatusWithBoardExclude :one
+SELECT
+  COUNT(statuses.id)
+FROM
+  statuses
+  JOIN boards ON statuses.board_id = boards.id
+WHERE
+  statuses.id NOT IN (sqlc.slice('ids'))
+  AND statuses.board_id = ?
+  AND boards.user_id = ?;
+
+-- name: DeleteStatusesByUserID :exec
+DELETE FROM
+  statuses
+WHERE
+  board_id IN (
+    SELECT
+      id
+    FROM
+      boards
+    WHERE
+      user_id = ?
+  );
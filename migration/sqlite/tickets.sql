@@ -0,0 +1,102 @@
+-- name: GetTicketByID :one
+SELECT
+  *
+FROM
+  tickets
+WHERE
+  id = ?;
+
+-- name: GetTicketWithBoard :one
+SELECT
+  sqlc.embed(tickets),
+  sqlc.embed(boards)
+FROM
+  tickets
+  JOIN statuses ON tickets.status_id = statuses.id
+  JOIN boards ON statuses.board_id = boards.id
+WHERE
+  tickets.id = ?
+  AND statuses.board_id = ?
+  AND boards.user_id = ?;
+
+-- name: GetTicketsWithBoard :many
+SELECT
+  sqlc.embed(tickets),
+  sqlc.embed(boards)
+FROM
+  tickets
+  JOIN statuses ON tickets.status_id = statuses.id
+  JOIN boards ON statuses.board_id = boards.id
+WHERE
+  tickets.id IN (sqlc.slice('ids'))
+  AND statuses.board_id = ?
+  AND boards.user_id = ?;
+
+-- name: GetTickets :many
+SELECT
+  *
+FROM
+  tickets
+WHERE
+  status_id = coalesce(sqlc.slice('status_ids'), status_id)
+  OR status_id IN (sqlc.slice('status_ids'))
+ORDER BY
+  status_id ASC,
+  (
+    CASE
+      WHEN sqlc.arg('sort_order_direction') = 'asc' THEN sort_order
+    END
+  ) ASC,
+  (
+    CASE
+      WHEN sqlc.arg('sort_order_direction') = 'desc' THEN sort_order
+    END
+  ) DESC;
+
+-- name: CreateTicket :execresult
+INSERT INTO
+  tickets (
+    status_id,
+    title,
+    description,
+    contact,
+    sort_order,
+    created_at
+  )
+VALUES
+  (?, ?, ?, ?, ?, CURRENT_TIMESTAMP);
+
+-- name: UpdateTicket :exec
+UPDATE
+  tickets
+SET
+  status_id = ?,
+  title = ?,
+  description = ?,
+  contact = ?,
+  sort_order = ?,
+  updated_at = CURRENT_TIMESTAMP
+WHERE
+  id = ?;
+
+-- name: CountTicketByStatusID :one
+SELECT
+  COUNT(*)
+FROM
+  tickets
+WHERE
+  status_id = ?;
+
+-- name: DeleteTicketsByUserID :exec
+DELETE FROM
+  tickets
+WHERE
+  status_id IN (
+    SELECT
+      statuses.id
+    FROM
+      statuses
+      JOIN boards ON statuses.board_id = boards.id
+    WHERE
+      boards.user_id = ?
+  );
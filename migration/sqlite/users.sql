@@ -0,0 +1,86 @@
+-- name: FindUserByEmail :one
+SELECT
+  *
+FROM
+  users
+WHERE
+  email = ?
+LIMIT
+  1;
+
+-- name: FindUserByID :one
+SELECT
+  *
+FROM
+  users
+WHERE
+  id = ?;
+
+-- name: CreateUser :execresult
+INSERT INTO
+  users (name, lastname, email, password, created_at)
+VALUES
+  (?, ?, ?, ?, CURRENT_TIMESTAMP);
+
+-- name: UpdateUser :exec
+UPDATE
+  users
+SET
+  name = ?,
+  lastname = ?,
+  email = ?,
+  password = ?,
+  updated_at = CURRENT_TIMESTAMP
+WHERE
+  id = ?;
+
+-- name: DeleteUser :exec
+DELETE FROM
+  users
+WHERE
+  id = ?;
+
+-- name: UpdateUserPassword :exec
+UPDATE
+  users
+SET
+  password = ?,
+  password_reset_required = ?,
+  updated_at = CURRENT_TIMESTAMP
+WHERE
+  id = ?;
+
+-- name: UpdateUserDisabledAt :exec
+UPDATE
+  users
+SET
+  disabled_at = ?,
+  updated_at = CURRENT_TIMESTAMP
+WHERE
+  id = ?;
+
+-- name: SearchUsers :many
+SELECT
+  *
+FROM
+  users
+WHERE
+  sqlc.arg('query') = ''
+  OR email LIKE '%' || sqlc.arg('query') || '%'
+  OR name LIKE '%' || sqlc.arg('query') || '%'
+  OR lastname LIKE '%' || sqlc.arg('query') || '%'
+ORDER BY
+  id ASC
+LIMIT
+  ? OFFSET ?;
+
+-- name: CountSearchUsers :one
+SELECT
+  COUNT(*)
+FROM
+  users
+WHERE
+  sqlc.arg('query') = ''
+  OR email LIKE '%' || sqlc.arg('query') || '%'
+  OR name LIKE '%' || sqlc.arg('query') || '%'
+  OR lastname LIKE '%' || sqlc.arg('query') || '%';
@@ -74,7 +74,7 @@ ORDER BY
     END
   ) DESC;
 
--- name: CreateStatus :exec
+-- name: CreateStatus :execresult
 INSERT INTO
   statuses (board_id, title, sort_order, created_at)
 VALUES
@@ -134,17 +134,10 @@ WHERE
   AND statuses.board_id = ?
   AND boards.user_id = ?;
 
--- name: GetLastInsertStatus :one
-SELECT
-  *
+-- name: DeleteStatusesByUserID :exec
+DELETE statuses
 FROM
   statuses
+  JOIN boards ON statuses.board_id = boards.id
 WHERE
-  id = (
-    SELECT
-      LAST_INSERT_ID()
-    FROM
-      statuses AS s
-    LIMIT
-      1
-  );
\ No newline at end of file
+  boards.user_id = ?;
\ No newline at end of file
@@ -113,7 +113,7 @@ ORDER BY
     END
   ) DESC;
 
--- name: CreateTicket :exec
+-- name: CreateTicket :execresult
 INSERT INTO
   tickets (
     status_id,
@@ -181,17 +181,11 @@ WHERE
   AND statuses.board_id = ?
   AND boards.user_id = ?;
 
--- name: GetLastInsertTicket :one
-SELECT
-  *
+-- name: DeleteTicketsByUserID :exec
+DELETE tickets
 FROM
   tickets
+  JOIN statuses ON tickets.status_id = statuses.id
+  JOIN boards ON statuses.board_id = boards.id
 WHERE
-  id = (
-    SELECT
-      LAST_INSERT_ID()
-    FROM
-      tickets AS t
-    LIMIT
-      1
-  );
\ No newline at end of file
+  boards.user_id = ?;
\ No newline at end of file
@@ -0,0 +1,20 @@
+-- name: GetUserIdentity :one
+SELECT
+  *
+FROM
+  user_identities
+WHERE
+  provider = ?
+  AND subject = ?;
+
+-- name: CreateUserIdentity :exec
+INSERT INTO
+  user_identities (user_id, provider, subject, email, created_at)
+VALUES
+  (?, ?, ?, ?, NOW());
+
+-- name: DeleteUserIdentitiesByUserID :exec
+DELETE FROM
+  user_identities
+WHERE
+  user_id = ?;
\ No newline at end of file
@@ -26,7 +26,7 @@ FROM
 WHERE
   id = ?;
 
--- name: CreateUser :exec
+-- name: CreateUser :execresult
 INSERT INTO
   users (name, lastname, email, password, created_at)
 VALUES
@@ -44,25 +44,53 @@ SET
 WHERE
   id = ?;
 
--- name: GetLastInsertUser :one
+-- name: DeleteUser :exec
+DELETE FROM
+  users
+WHERE
+  id = ?;
+
+-- name: UpdateUserPassword :exec
+UPDATE
+  users
+SET
+  password = ?,
+  password_reset_required = ?,
+  updated_at = NOW()
+WHERE
+  id = ?;
+
+-- name: UpdateUserDisabledAt :exec
+UPDATE
+  users
+SET
+  disabled_at = ?,
+  updated_at = NOW()
+WHERE
+  id = ?;
+
+-- name: SearchUsers :many
 SELECT
   *
 FROM
   users
 WHERE
-  id = (
-    SELECT
-      LAST_INSERT_ID()
-    FROM
-      users AS u
-    LIMIT
-      1
-  );
+  sqlc.arg('query') = ''
+  OR email LIKE CONCAT('%', sqlc.arg('query'), '%')
+  OR name LIKE CONCAT('%', sqlc.arg('query'), '%')
+  OR lastname LIKE CONCAT('%', sqlc.arg('query'), '%')
+ORDER BY
+  id ASC
+LIMIT
+  ? OFFSET ?;
 
--- name: GetLastInsertUserID :one
+-- name: CountSearchUsers :one
 SELECT
-  LAST_INSERT_ID()
+  COUNT(*)
 FROM
   users
-LIMIT
-  1;
\ No newline at end of file
+WHERE
+  sqlc.arg('query') = ''
+  OR email LIKE CONCAT('%', sqlc.arg('query'), '%')
+  OR name LIKE CONCAT('%', sqlc.arg('query'), '%')
+  OR lastname LIKE CONCAT('%', sqlc.arg('query'), '%');
\ No newline at end of file
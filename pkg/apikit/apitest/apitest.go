@@ -0,0 +1,255 @@
+// Package apitest runs a service's routers over a store.Memory, or a SQLite
+// database from SQLite, for testing handlers through HTTP without a listener.
+package apitest
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"ticket/config"
+	"ticket/pkg/apikit"
+	"ticket/pkg/auth"
+	"ticket/pkg/db"
+	"ticket/pkg/openapi"
+	"ticket/pkg/store"
+	"ticket/pkg/wire"
+
+	"github.com/guregu/null/v5"
+)
+
+// Password is the password of the users AddUser creates.
+const Password = "password123"
+
+type Server struct {
+	API *apikit.API
+	// Store is the API's store: a new store.Memory, unless opts set another.
+	Store store.Store
+	Auth  *auth.Auth
+	// Spec, when set, must document the status of every successful response
+	// Run gets.
+	Spec *openapi.Document
+}
+
+// New mounts routers on an API with fresh keys and an empty store. opts are
+// applied last, so WithGlobal can replace the token settings and WithStore
+// the store.
+func New(t testing.TB, routers []apikit.Router, opts ...apikit.Option) *Server {
+	t.Helper()
+
+	opts = append([]apikit.Option{
+		apikit.WithLog(apikit.LogConfig{Level: "error"}),
+		apikit.WithCerts(Certs(t)),
+		apikit.WithGlobal(Config()),
+		// No database is connected, but handlers take their timeouts from it.
+		apikit.WithDB(apikit.DBConfig{}),
+		apikit.WithStore(store.NewMemory()),
+	}, opts...)
+
+	api := apikit.NewAPI(opts...).UseRouter(routers...)
+	api.Mount()
+
+	return &Server{
+		API:   api,
+		Store: api.Store,
+		Auth:  auth.New(api.Config),
+	}
+}
+
+// Config has the token settings New uses.
+func Config() config.Config {
+	var cf config.Config
+	cf.AccessTokenExpire = 3600
+	cf.RefreshTokenExpire = 7200
+
+	return cf
+}
+
+var keys = sync.OnceValues(func() (apikit.Certs, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return apikit.Certs{}, err
+	}
+
+	public, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return apikit.Certs{}, err
+	}
+
+	return apikit.Certs{
+		PrivateKey: pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}),
+		PublicKey:  pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: public}),
+	}, nil
+})
+
+// Certs returns a key pair for signing tokens, shared by the whole test
+// binary because generating one is slow.
+func Certs(t testing.TB) apikit.Certs {
+	t.Helper()
+
+	c, err := keys()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return c
+}
+
+// passwordHash is Password hashed once, as bcrypt is slow on purpose.
+var passwordHash = sync.OnceValues(func() (string, error) {
+	return (&auth.Auth{}).HashPassword(Password)
+})
+
+// AddUser creates a user whose password is Password, with the other fields
+// of u as they are. u.Password is ignored. It needs the Memory store.
+func (s *Server) AddUser(t testing.TB, u db.User) uint64 {
+	t.Helper()
+
+	m, ok := s.Store.(*store.Memory)
+	if !ok {
+		t.Fatalf("AddUser needs a *store.Memory, not %T", s.Store)
+	}
+
+	hash, err := passwordHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u.Password = null.StringFrom(hash)
+
+	return m.AddUser(u)
+}
+
+// Token returns an access token for userID with the given scopes.
+func (s *Server) Token(t testing.TB, userID uint64, scopes ...string) string {
+	t.Helper()
+
+	tokens, err := s.Auth.GenerateTokens(auth.TokenPayload{UserID: userID, Scopes: scopes})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return tokens.AccessToken
+}
+
+// Do sends a request with body encoded as JSON, unless it is nil, and with
+// token as the bearer token, unless it is empty.
+func (s *Server) Do(t testing.TB, method, path, token string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var b bytes.Buffer
+	if body != nil {
+		err := json.NewEncoder(&b).Encode(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	req := httptest.NewRequest(method, path, &b)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	return s.Serve(req)
+}
+
+// Serve sends req as it is, e.g. with cookies.
+func (s *Server) Serve(req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	s.API.App.ServeHTTP(rec, req)
+
+	return rec
+}
+
+// route returns the echo path that serves method and target, e.g.
+// /boards/:board_id for /boards/1.
+func (s *Server) route(method, target string) string {
+	path, _, _ := strings.Cut(target, "?")
+
+	c := s.API.App.NewContext(httptest.NewRequest(method, path, nil), httptest.NewRecorder())
+	s.API.App.Router().Find(method, path, c)
+
+	return c.Path()
+}
+
+// Decode unmarshals the response body into a T.
+func Decode[T any](t testing.TB, rec *httptest.ResponseRecorder) T {
+	t.Helper()
+
+	var v T
+	err := json.Unmarshal(rec.Body.Bytes(), &v)
+	if err != nil {
+		t.Fatalf("decoding %s: %v", rec.Body, err)
+	}
+
+	return v
+}
+
+// Code returns the code of an error response, or "" for a success.
+func Code(t testing.TB, rec *httptest.ResponseRecorder) wire.Code {
+	t.Helper()
+
+	if rec.Code < http.StatusBadRequest {
+		return ""
+	}
+
+	return Decode[wire.GenericResponse[wire.ErrorResponse]](t, rec).Data.Code
+}
+
+// Case is a request and the response it should get. Check, when set, looks
+// at the response or at the store afterwards.
+type Case struct {
+	Name     string
+	Method   string
+	Path     string
+	Token    string
+	Body     any
+	Want     int
+	WantCode wire.Code
+	Check    func(t *testing.T, s *Server, rec *httptest.ResponseRecorder)
+}
+
+// Run sends each case to a fresh server from newServer, so cases that change
+// the store do not affect each other.
+func Run(t *testing.T, newServer func(t *testing.T) *Server, cases []Case) {
+	t.Helper()
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			s := newServer(t)
+
+			rec := s.Do(t, tc.Method, tc.Path, tc.Token, tc.Body)
+			if rec.Code != tc.Want {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tc.Want, rec.Body)
+			}
+
+			if s.Spec != nil && rec.Code < http.StatusBadRequest {
+				route := s.route(tc.Method, tc.Path)
+				if !s.Spec.Documents(tc.Method, route, rec.Code) {
+					t.Errorf("%s %s answered %d, which the spec does not document", tc.Method, route, rec.Code)
+				}
+			}
+
+			if tc.WantCode != "" {
+				if code := Code(t, rec); code != tc.WantCode {
+					t.Errorf("code = %q, want %q", code, tc.WantCode)
+				}
+			}
+
+			if tc.Check != nil {
+				tc.Check(t, s, rec)
+			}
+		})
+	}
+}
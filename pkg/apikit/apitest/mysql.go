@@ -0,0 +1,78 @@
+package apitest
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+	"ticket/migration"
+	"ticket/pkg/migrate"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// MySQLEnv names the variable holding the DSN MySQL connects with, e.g.
+// root:randomrootpassword@tcp(localhost:3306)/. The user needs to be allowed
+// to create and drop databases.
+const MySQLEnv = "TICKET_TEST_MYSQL_DSN"
+
+// MySQL creates a new database on the server named by MySQLEnv, with every
+// migration applied, and drops it when the test ends. It skips the test when
+// MySQLEnv is not set. Pass it to New with
+// apikit.WithStore(store.NewSQL(conn)).
+func MySQL(t testing.TB) *sql.DB {
+	t.Helper()
+
+	dsn := os.Getenv(MySQLEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", MySQLEnv)
+	}
+
+	cf, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		t.Fatalf("%s: %v", MySQLEnv, err)
+	}
+	cf.ParseTime = true
+	cf.DBName = ""
+
+	ctx := context.Background()
+	name := fmt.Sprintf("ticket_test_%d", time.Now().UnixNano())
+
+	server, err := sql.Open("mysql", cf.FormatDSN())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { server.Close() })
+
+	_, err = server.ExecContext(ctx, "CREATE DATABASE "+name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_, err := server.ExecContext(context.Background(), "DROP DATABASE "+name)
+		if err != nil {
+			t.Errorf("dropping %s: %v", name, err)
+		}
+	})
+
+	cf.DBName = name
+	conn, err := sql.Open("mysql", cf.FormatDSN())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	m, err := migrate.New(conn, "mysql", migration.Schema, migration.Dir("mysql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = m.Up(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return conn
+}
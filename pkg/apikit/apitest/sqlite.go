@@ -0,0 +1,41 @@
+package apitest
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"ticket/migration"
+	"ticket/pkg/apikit"
+	"ticket/pkg/migrate"
+
+	_ "modernc.org/sqlite"
+)
+
+// SQLite opens a new database in t.TempDir, connected the way the services
+// connect, with every migration applied. Pass it to New with
+// apikit.WithStore(store.NewSQLite(conn)).
+func SQLite(t testing.TB) *sql.DB {
+	t.Helper()
+
+	conn, err := apikit.ConnectDBContext(context.Background(), apikit.DBConfig{
+		Driver: "sqlite",
+		Path:   filepath.Join(t.TempDir(), "ticket.db"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	m, err := migrate.New(conn, "sqlite", migration.Schema, migration.Dir("sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = m.Up(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return conn
+}
@@ -5,10 +5,14 @@ import (
 )
 
 type Configuration struct {
-	api    APIConfig
-	db     DBConfig
-	global config.Config
-	certs  Certs
+	api        APIConfig
+	db         DBConfig
+	hasDB      bool
+	global     config.Config
+	certs      Certs
+	tracing    TracingConfig
+	log        LogConfig
+	migrations MigrationConfig
 }
 
 func (cf *Configuration) API() APIConfig {
@@ -23,6 +27,14 @@ func (cf *Configuration) GLobal() config.Config {
 	return cf.global
 }
 
+func (cf *Configuration) Tracing() TracingConfig {
+	return cf.tracing
+}
+
+func (cf *Configuration) Log() LogConfig {
+	return cf.log
+}
+
 func (cf *Configuration) Certs() Certs {
 	return cf.certs
 }
@@ -42,3 +54,7 @@ func (cf *Configuration) AccessTokenExpire() int {
 func (cf *Configuration) RefreshTokenExpire() int {
 	return cf.global.RefreshTokenExpire
 }
+
+func (cf *Configuration) IdentitySecret() []byte {
+	return []byte(cf.global.IdentitySecret)
+}
@@ -4,20 +4,235 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"ticket/config"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
 )
 
+// NewDBConfig takes every setting of services.database from cf.
+func NewDBConfig(cf config.Config) DBConfig {
+	d := cf.Services.Database
+
+	return DBConfig{
+		Driver:            d.Driver,
+		Path:              d.Path,
+		Host:              d.Host,
+		Port:              d.Port,
+		Name:              d.Dbname,
+		User:              d.User,
+		Password:          d.Password,
+		TLS:               d.TLS,
+		TimeOut:           d.Timeout,
+		DialTimeout:       d.DialTimeout,
+		ReadTimeout:       d.ReadTimeout,
+		WriteTimeout:      d.WriteTimeout,
+		MaxOpenConns:      d.MaxOpenConns,
+		MaxIdleConns:      d.MaxIdleConns,
+		ConnMaxLifetime:   d.ConnMaxLifetime,
+		ConnMaxIdleTime:   d.ConnMaxIdleTime,
+		Charset:           d.Charset,
+		Collation:         d.Collation,
+		TimeZone:          d.TimeZone,
+		ConnectAttempts:   d.ConnectAttempts,
+		ConnectBackoff:    d.ConnectBackoff,
+		ConnectMaxBackoff: d.ConnectMaxBackoff,
+	}
+}
+
 func ConnectDBContext(ctx context.Context, cf DBConfig) (*sql.DB, error) {
-	dsname := fmt.Sprintf("%s:%s@tcp(%s)/%s?parseTime=true", cf.User, cf.Password, cf.Host, cf.Name)
-	fmt.Printf("\nDatasource is %s\n", dsname)
-	db, err := sql.Open("mysql", dsname)
+	dsn, err := cf.DSN()
 	if err != nil {
 		return nil, err
 	}
 
+	db, err := sql.Open(cf.driver(), dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	// Zero keeps database/sql's defaults; SetMaxIdleConns(0) would stop it
+	// reusing connections at all.
+	if cf.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(cf.MaxOpenConns)
+	}
+	if cf.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(cf.MaxIdleConns)
+	}
+	if cf.ConnMaxLifetime > 0 {
+		db.SetConnMaxLifetime(cf.ConnMaxLifetime)
+	}
+	if cf.ConnMaxIdleTime > 0 {
+		db.SetConnMaxIdleTime(cf.ConnMaxIdleTime)
+	}
+
 	err = db.PingContext(ctx)
 	if err != nil {
+		db.Close()
+
 		return nil, err
 	}
 
 	return db, nil
 }
+
+func (cf DBConfig) driver() string {
+	if cf.Driver == "" {
+		return "mysql"
+	}
+
+	return cf.Driver
+}
+
+// validate reports the settings a connection needs but cf leaves empty.
+func (cf DBConfig) validate() error {
+	var missing []string
+	if cf.driver() == "sqlite" {
+		if cf.Path == "" {
+			missing = append(missing, "path")
+		}
+	} else {
+		for name, value := range map[string]string{
+			"host":     cf.Host,
+			"name":     cf.Name,
+			"user":     cf.User,
+			"password": cf.Password,
+		} {
+			if value == "" {
+				missing = append(missing, name)
+			}
+		}
+	}
+
+	if len(missing) > 0 {
+		slices.Sort(missing)
+
+		return fmt.Errorf("%s database config is missing %s", cf.driver(), strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// label names the database in logs and metrics.
+func (cf DBConfig) label() string {
+	if cf.driver() == "sqlite" {
+		return cf.Path
+	}
+
+	return cf.Name
+}
+
+// DSN builds the data source name. For MySQL zero values keep the driver's
+// defaults, and the result contains the password, so never log it.
+func (cf DBConfig) DSN() (string, error) {
+	if cf.driver() == "sqlite" {
+		return sqliteDSN(cf.Path), nil
+	}
+
+	mc := mysql.NewConfig()
+	mc.Net = "tcp"
+	mc.Addr = cf.Host
+	if cf.Port > 0 {
+		mc.Addr = net.JoinHostPort(cf.Host, strconv.Itoa(cf.Port))
+	}
+	mc.DBName = cf.Name
+	mc.User = cf.User
+	mc.Passwd = cf.Password
+	mc.ParseTime = true
+	mc.TLSConfig = cf.TLS
+	mc.Timeout = cf.DialTimeout
+	mc.ReadTimeout = cf.ReadTimeout
+	mc.WriteTimeout = cf.WriteTimeout
+
+	if cf.Charset != "" {
+		mc.Params = map[string]string{"charset": cf.Charset}
+	}
+
+	if cf.Collation != "" {
+		mc.Collation = cf.Collation
+	}
+
+	if cf.TimeZone != "" {
+		loc, err := time.LoadLocation(cf.TimeZone)
+		if err != nil {
+			return "", err
+		}
+
+		mc.Loc = loc
+	}
+
+	return mc.FormatDSN(), nil
+}
+
+// sqliteDSN turns on foreign keys, which SQLite leaves off by default, and
+// makes writers wait for each other instead of failing. Transactions take the
+// write lock up front, as two deferred ones that both write would deadlock.
+func sqliteDSN(path string) string {
+	q := url.Values{}
+	q.Add("_pragma", "foreign_keys(1)")
+	q.Add("_pragma", "busy_timeout(5000)")
+	q.Add("_pragma", "journal_mode(WAL)")
+	q.Set("_txlock", "immediate")
+	q.Set("_time_format", "sqlite")
+
+	return "file:" + path + "?" + q.Encode()
+}
+
+// connectDB retries ConnectDBContext with exponential backoff until it
+// succeeds, the attempts run out or ctx is done.
+func (api *API) connectDB(ctx context.Context) (*sql.DB, error) {
+	cf := api.Config.db
+
+	attempts := cf.ConnectAttempts
+	if attempts <= 0 {
+		attempts = 1
+	}
+
+	backoff := cf.ConnectBackoff
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+
+	var err error
+	for i := 1; ; i++ {
+		attrs := []any{"driver", cf.driver(), "database", cf.label(), "attempt", i}
+		if cf.driver() == "mysql" {
+			attrs = append(attrs, "host", cf.Host)
+		}
+		api.Logger.Info("connecting to database", attrs...)
+
+		pingCtx, cancel := ctx, context.CancelFunc(func() {})
+		if cf.TimeOut > 0 {
+			pingCtx, cancel = context.WithTimeout(ctx, cf.TimeOut)
+		}
+
+		var db *sql.DB
+		db, err = ConnectDBContext(pingCtx, cf)
+		cancel()
+		if err == nil {
+			return db, nil
+		}
+
+		if i >= attempts {
+			return nil, err
+		}
+
+		api.Logger.Warn("failed to connect to database, retrying", "error", err, "retry_in", backoff)
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if cf.ConnectMaxBackoff > 0 && backoff > cf.ConnectMaxBackoff {
+			backoff = cf.ConnectMaxBackoff
+		}
+	}
+}
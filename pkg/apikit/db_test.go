@@ -0,0 +1,57 @@
+package apikit
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"ticket/config"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestConnectDBKeepsPoolDefaults(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := ConnectDBContext(ctx, DBConfig{Driver: "sqlite", Path: filepath.Join(t.TempDir(), "ticket.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	for range 2 {
+		_, err = db.ExecContext(ctx, "SELECT 1")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stats := db.Stats()
+	if stats.MaxOpenConnections != 0 {
+		t.Errorf("MaxOpenConnections = %d, want unlimited", stats.MaxOpenConnections)
+	}
+	if stats.OpenConnections != 1 || stats.Idle != 1 {
+		t.Errorf("open = %d, idle = %d, want the connection kept idle for reuse", stats.OpenConnections, stats.Idle)
+	}
+}
+
+func TestNewDBConfig(t *testing.T) {
+	var cf config.Config
+	cf.Services.Database.Dbname = "ticket"
+	cf.Services.Database.ReadTimeout = 30 * time.Second
+	cf.Services.Database.WriteTimeout = 30 * time.Second
+	cf.Services.Database.MaxIdleConns = 7
+	cf.Services.Database.ConnMaxLifetime = 5 * time.Minute
+
+	got := NewDBConfig(cf)
+	want := DBConfig{
+		Name:            "ticket",
+		ReadTimeout:     30 * time.Second,
+		WriteTimeout:    30 * time.Second,
+		MaxIdleConns:    7,
+		ConnMaxLifetime: 5 * time.Minute,
+	}
+	if got != want {
+		t.Errorf("NewDBConfig() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,124 @@
+package apikit
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"ticket/pkg/wire"
+
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+// Error is returned by handlers for failures that need a more specific code
+// than their status implies. Other errors are rendered with wire.CodeFor.
+type Error struct {
+	Status  int
+	Code    wire.Code
+	Message string
+}
+
+func NewError(status int, code wire.Code, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("code=%d, error=%s, message=%s", e.Status, e.Code, e.Message)
+}
+
+// errorHandler renders every error as the same envelope. Validation errors
+// list each failed field in the caller's language, and internal errors only
+// carry the request id; their cause is in the access log under the same id.
+func (api *API) errorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	status := http.StatusInternalServerError
+	res := wire.GenericResponse[wire.ErrorResponse]{
+		Error: true,
+		Data:  wire.ErrorResponse{RequestID: RequestIDFrom(c)},
+	}
+
+	var ae *Error
+	var he *echo.HTTPError
+	var ve validator.ValidationErrors
+	switch {
+	case errors.As(err, &ae):
+		status, res.Data.Code, res.Message = ae.Status, ae.Code, ae.Message
+	case errors.As(err, &ve):
+		status, res.Data.Code = http.StatusBadRequest, wire.CodeValidation
+		res.Message, res.Data.Fields = api.validationErrors(ve, c.Request().Header.Get("Accept-Language"))
+		res.Data.Messages = make(map[string]string, len(res.Data.Fields))
+		for _, f := range res.Data.Fields {
+			if _, ok := res.Data.Messages[f.Field]; !ok {
+				res.Data.Messages[f.Field] = f.Message
+			}
+		}
+	case errors.As(err, &he):
+		status, res.Data.Code, res.Message = he.Code, wire.CodeFor(he.Code), fmt.Sprint(he.Message)
+	}
+
+	if status == http.StatusInternalServerError {
+		res.Data.Code, res.Message = wire.CodeInternal, http.StatusText(status)
+	}
+
+	if c.Request().Method == http.MethodHead {
+		err = c.NoContent(status)
+	} else {
+		err = c.JSON(status, res)
+	}
+
+	if err != nil {
+		Logger(c).Error("failed to write error response", "error", err)
+	}
+}
+
+// validationErrors translates ve with the API's validator, or leaves the
+// validator's messages as they are when a different one is installed.
+func (api *API) validationErrors(ve validator.ValidationErrors, acceptLanguage string) (string, []wire.FieldError) {
+	v, ok := api.App.Validator.(*Validator)
+	var trans ut.Translator
+	if ok {
+		trans = v.Translator(acceptLanguage)
+	}
+
+	fields := make([]wire.FieldError, 0, len(ve))
+	for _, fe := range ve {
+		f := wire.FieldError{
+			Field:   fieldPath(fe),
+			Rule:    fe.Tag(),
+			Param:   fe.Param(),
+			Message: fe.Error(),
+		}
+		if ok {
+			f.Message = v.Message(fe, trans)
+		}
+
+		fields = append(fields, f)
+	}
+
+	if !ok {
+		return "request validation failed", fields
+	}
+
+	summary, err := trans.T(validationFailed)
+	if err != nil {
+		summary = "request validation failed"
+	}
+
+	return summary, fields
+}
+
+// fieldPath drops the name of the validated struct from the namespace. The
+// validator names fields after their json tags.
+func fieldPath(fe validator.FieldError) string {
+	_, path, ok := strings.Cut(fe.Namespace(), ".")
+	if !ok {
+		return fe.Field()
+	}
+
+	return path
+}
@@ -0,0 +1,110 @@
+package apikit
+
+import (
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	loggerKey = "logger"
+	// UserIDKey is set on the echo context by whichever middleware
+	// authenticates the request, so access logs can include it.
+	UserIDKey = "user_id"
+)
+
+// redactedKeys are attribute keys whose values never reach the logs.
+var redactedKeys = []string{"password", "secret", "token", "authorization", "dsn"}
+
+func newLogger(w io.Writer, cf LogConfig, service string) *slog.Logger {
+	var level slog.Level
+	err := level.UnmarshalText([]byte(cf.Level))
+	if err != nil {
+		level = slog.LevelInfo
+	}
+
+	opts := &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: redact,
+	}
+
+	var h slog.Handler
+	if cf.Format == "text" {
+		h = slog.NewTextHandler(w, opts)
+	} else {
+		h = slog.NewJSONHandler(w, opts)
+	}
+
+	return slog.New(h).With("service", service)
+}
+
+func redact(_ []string, a slog.Attr) slog.Attr {
+	key := strings.ToLower(a.Key)
+	for _, k := range redactedKeys {
+		if strings.Contains(key, k) {
+			return slog.String(a.Key, "[REDACTED]")
+		}
+	}
+
+	return a
+}
+
+// Logger returns the request's logger, which already carries the request id
+// and trace id.
+// Outside a request it falls back to the default logger.
+func Logger(c echo.Context) *slog.Logger {
+	if l, ok := c.Get(loggerKey).(*slog.Logger); ok {
+		return l
+	}
+
+	return slog.Default()
+}
+
+// AccessLog logs one line per request once the response has been written.
+// It must run after RequestID and the tracing middleware.
+func AccessLog(logger *slog.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			l := logger.With("request_id", RequestIDFrom(c))
+			if sc := trace.SpanContextFromContext(c.Request().Context()); sc.IsValid() {
+				l = l.With("trace_id", sc.TraceID().String())
+			}
+			c.Set(loggerKey, l)
+
+			err := next(c)
+			if err != nil {
+				c.Error(err)
+			}
+
+			req, res := c.Request(), c.Response()
+			attrs := []any{
+				"method", req.Method,
+				"path", req.URL.Path,
+				"status", res.Status,
+				"latency_ms", time.Since(start).Milliseconds(),
+				"remote_ip", c.RealIP(),
+			}
+
+			if userID := c.Get(UserIDKey); userID != nil {
+				attrs = append(attrs, UserIDKey, userID)
+			}
+
+			level := slog.LevelInfo
+			if err != nil {
+				attrs = append(attrs, "error", err.Error())
+				if res.Status >= 500 {
+					level = slog.LevelError
+				}
+			}
+
+			l.Log(req.Context(), level, "request", attrs...)
+
+			return nil
+		}
+	}
+}
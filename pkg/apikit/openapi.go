@@ -0,0 +1,52 @@
+package apikit
+
+import (
+	"net/http"
+	"ticket/pkg/openapi"
+	"ticket/pkg/wire"
+)
+
+// SpecVersion is the version of the services' OpenAPI documents.
+const SpecVersion = "1.0.0"
+
+// NewSpec starts the OpenAPI document of a service. Every operation added to
+// it may fail with the error envelope.
+func NewSpec(title string) *openapi.Document {
+	return openapi.New(title, SpecVersion).Errors(wire.GenericResponse[wire.ErrorResponse]{})
+}
+
+// UseSpec serves doc at /openapi.json and renders it at /docs. Mount adds the
+// endpoints every API has to it and warns about routes it does not describe.
+func (api *API) UseSpec(doc *openapi.Document) *API {
+	api.spec = doc
+
+	return api
+}
+
+// Missing returns the registered routes the API's document does not describe,
+// or nil without a document.
+func (api *API) Missing() []string {
+	if api.spec == nil {
+		return nil
+	}
+
+	return api.spec.Missing(api.App.Routes())
+}
+
+func (api *API) serveSpec() {
+	ops := api.spec.Group("").Tag(openapi.TagOperations)
+	ops.GET("/health", "getHealth").Summarize("Report that the process is up").ReturnsText(http.StatusOK, "text/plain")
+	ops.GET("/ready", "getReady").Summarize("Report whether the dependencies can serve traffic").
+		Returns(http.StatusOK, ReadyResponse{}).
+		Returns(http.StatusServiceUnavailable, ReadyResponse{})
+	ops.GET("/metrics", "getMetrics").Summarize("Prometheus metrics").ReturnsText(http.StatusOK, "text/plain")
+	ops.GET("/openapi.json", "getOpenAPI").Summarize("This document").Returns(http.StatusOK, map[string]any{})
+	ops.GET("/docs", "getDocs").Summarize("This document as a web page").ReturnsText(http.StatusOK, "text/html")
+
+	api.App.GET("/openapi.json", api.spec.Handler())
+	api.App.GET("/docs", openapi.Docs)
+
+	if missing := api.Missing(); len(missing) > 0 {
+		api.Logger.Warn("routes missing from the OpenAPI document", "routes", missing)
+	}
+}
@@ -1,7 +1,9 @@
 package apikit
 
 import (
+	"io/fs"
 	"ticket/config"
+	"ticket/pkg/store"
 	"time"
 )
 
@@ -11,6 +13,15 @@ type APIConfig struct {
 	Label string
 	Host  string
 	Port  int
+	// ShutdownTimeout bounds how long Start waits for in-flight requests
+	// after a shutdown signal.
+	ShutdownTimeout time.Duration
+	// DrainPeriod is how long Start keeps serving after a shutdown signal
+	// with /ready failing, before it stops taking new connections.
+	DrainPeriod time.Duration
+	// TrustedProxies are CIDR ranges allowed to set X-Forwarded-For. Without
+	// them RealIP is the address of the connection itself.
+	TrustedProxies []string
 }
 
 func WithAPI(c APIConfig) Option {
@@ -20,16 +31,46 @@ func WithAPI(c APIConfig) Option {
 }
 
 type DBConfig struct {
-	Host     string
+	// Driver is "mysql" or "sqlite". Path is the SQLite file; the other
+	// connection settings only apply to MySQL.
+	Driver string
+	Path   string
+	Host   string
+	// Port defaults to the driver's 3306 when zero.
+	Port     int
 	Name     string
 	User     string
 	Password string
-	TimeOut  time.Duration
+	TLS      string
+	// TimeOut bounds single database calls, including the startup ping.
+	TimeOut         time.Duration
+	DialTimeout     time.Duration
+	ReadTimeout     time.Duration
+	WriteTimeout    time.Duration
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	Charset         string
+	Collation       string
+	TimeZone        string
+	// ConnectAttempts, ConnectBackoff and ConnectMaxBackoff control the
+	// startup retries, doubling the wait after each failed attempt.
+	ConnectAttempts   int
+	ConnectBackoff    time.Duration
+	ConnectMaxBackoff time.Duration
 }
 
+const defaultDBTimeOut = 5 * time.Second
+
 func WithDB(c DBConfig) Option {
 	return func(a *API) {
+		if c.TimeOut <= 0 {
+			c.TimeOut = defaultDBTimeOut
+		}
+
 		a.Config.db = c
+		a.Config.hasDB = true
 	}
 }
 
@@ -49,3 +90,49 @@ func WithCerts(c Certs) Option {
 		a.Config.certs = c
 	}
 }
+
+type TracingConfig struct {
+	Exporter    string
+	Endpoint    string
+	Insecure    bool
+	SampleRatio float64
+}
+
+func WithTracing(c TracingConfig) Option {
+	return func(a *API) {
+		a.Config.tracing = c
+	}
+}
+
+type LogConfig struct {
+	Level  string
+	Format string
+}
+
+func WithLog(c LogConfig) Option {
+	return func(a *API) {
+		a.Config.log = c
+	}
+}
+
+type MigrationConfig struct {
+	// Source holds the migration files in Dir.
+	Source fs.FS
+	Dir    string
+	// Auto applies pending migrations in Start, right after connecting.
+	Auto bool
+}
+
+func WithMigrations(c MigrationConfig) Option {
+	return func(a *API) {
+		a.Config.migrations = c
+	}
+}
+
+// WithStore sets the store handlers query. Without it Start builds one over
+// the database; tests pass store.NewMemory() and leave the database unset.
+func WithStore(s store.Store) Option {
+	return func(a *API) {
+		a.Store = s
+	}
+}
@@ -0,0 +1,21 @@
+package apikit
+
+import (
+	"net/http"
+	"strconv"
+	"ticket/pkg/wire"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ParamID reads the path parameter name as an id that fits in bitSize bits.
+// A malformed one is a 400 that names the parameter, rather than quoting
+// strconv's error.
+func ParamID(c echo.Context, name string, bitSize int) (uint64, error) {
+	id, err := strconv.ParseUint(c.Param(name), 10, bitSize)
+	if err != nil {
+		return 0, NewError(http.StatusBadRequest, wire.CodeBadRequest, "invalid "+name)
+	}
+
+	return id, nil
+}
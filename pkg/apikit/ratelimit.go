@@ -0,0 +1,257 @@
+package apikit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type Limit struct {
+	RequestsPerMinute int
+	// Burst is the bucket size. It defaults to RequestsPerMinute.
+	Burst int
+}
+
+func (l Limit) capacity() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+
+	return float64(l.RequestsPerMinute)
+}
+
+func (l Limit) perSecond() float64 {
+	return float64(l.RequestsPerMinute) / 60
+}
+
+type RouteLimit struct {
+	// Method is matched exactly; empty matches every method.
+	Method string
+	// Path is matched segment by segment, where "*" matches any one segment.
+	Path string
+	Limit
+}
+
+func (r RouteLimit) matches(method, path string) bool {
+	if r.Method != "" && !strings.EqualFold(r.Method, method) {
+		return false
+	}
+
+	want := strings.Split(strings.Trim(r.Path, "/"), "/")
+	got := strings.Split(strings.Trim(path, "/"), "/")
+	if len(want) != len(got) {
+		return false
+	}
+
+	for i := range want {
+		if want[i] != "*" && want[i] != got[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// RateLimitStore keeps the token buckets. MemoryRateLimitStore is enough for a
+// single instance; replicas that must share limits need a shared backend.
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, limit Limit) (RateLimitResult, error)
+}
+
+type RateLimitConfig struct {
+	Limit  Limit
+	Routes []RouteLimit
+	// KeyFunc identifies the caller. It defaults to the client IP.
+	KeyFunc func(c echo.Context) string
+	// Skipper lets requests through uncounted. It defaults to skipping the
+	// /health, /ready and /metrics probes.
+	Skipper func(c echo.Context) bool
+	Store   RateLimitStore
+}
+
+// RateLimitKeyByIP keys on c.RealIP, which only reads X-Forwarded-For from
+// APIConfig.TrustedProxies, so callers cannot pick a new key per request.
+func RateLimitKeyByIP(c echo.Context) string {
+	return "ip:" + c.RealIP()
+}
+
+func skipProbes(c echo.Context) bool {
+	switch c.Request().URL.Path {
+	case "/health", "/ready", "/metrics":
+		return true
+	}
+
+	return false
+}
+
+// ipExtractor reads the client IP from X-Forwarded-For when the connection
+// comes from one of trusted, and uses the connection's address otherwise.
+func ipExtractor(trusted []string) echo.IPExtractor {
+	if len(trusted) == 0 {
+		return echo.ExtractIPDirect()
+	}
+
+	options := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+	for _, cidr := range trusted {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			continue
+		}
+
+		options = append(options, echo.TrustIPRange(ipNet))
+	}
+
+	return echo.ExtractIPFromXFFHeader(options...)
+}
+
+// RateLimiter applies token-bucket limits per caller. A request matching one
+// of cf.Routes is counted against that route's own bucket instead of the
+// default one.
+func RateLimiter(cf RateLimitConfig) echo.MiddlewareFunc {
+	if cf.KeyFunc == nil {
+		cf.KeyFunc = RateLimitKeyByIP
+	}
+
+	if cf.Skipper == nil {
+		cf.Skipper = skipProbes
+	}
+
+	if cf.Store == nil {
+		cf.Store = NewMemoryRateLimitStore()
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if cf.Skipper(c) {
+				return next(c)
+			}
+
+			req := c.Request()
+
+			bucket, limit := "default", cf.Limit
+			for i, r := range cf.Routes {
+				if r.matches(req.Method, req.URL.Path) {
+					bucket, limit = fmt.Sprintf("route%d", i), r.Limit
+					break
+				}
+			}
+
+			if limit.RequestsPerMinute <= 0 {
+				return next(c)
+			}
+
+			res, err := cf.Store.Take(req.Context(), bucket+"|"+cf.KeyFunc(c), limit)
+			if err != nil {
+				Logger(c).Error("rate limit store failed", "error", err)
+
+				return next(c)
+			}
+
+			h := c.Response().Header()
+			h.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+			h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			h.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+
+			if !res.Allowed {
+				h.Set("Retry-After", strconv.Itoa(ceilSeconds(res.RetryAfter)))
+
+				return echo.NewHTTPError(http.StatusTooManyRequests, "too many requests")
+			}
+
+			return next(c)
+		}
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	fullAt time.Time
+}
+
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	// now is the clock, replaced in tests.
+	now func() time.Time
+}
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		buckets: map[string]*bucket{},
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryRateLimitStore) Take(_ context.Context, key string, limit Limit) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	capacity, rate := limit.capacity(), limit.perSecond()
+
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, last: now}
+		s.buckets[key] = b
+	}
+
+	b.tokens = math.Min(capacity, b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+
+	res := RateLimitResult{Limit: int(capacity)}
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = seconds((1 - b.tokens) / rate)
+	}
+
+	res.Remaining = int(b.tokens)
+	res.Reset = seconds((capacity - b.tokens) / rate)
+	b.fullAt = now.Add(res.Reset)
+
+	return res, nil
+}
+
+// sweep drops buckets that have had time to refill completely, since a fresh
+// bucket behaves the same. It runs at most once a minute.
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+
+	s.lastSweep = now
+	for key, b := range s.buckets {
+		if !now.Before(b.fullAt) {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
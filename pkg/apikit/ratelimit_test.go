@@ -0,0 +1,250 @@
+package apikit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// fakeClock is a MemoryRateLimitStore clock that only moves when told to.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func newFakeStore() (*MemoryRateLimitStore, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	s := NewMemoryRateLimitStore()
+	s.now = clock.Now
+
+	return s, clock
+}
+
+func TestMemoryRateLimitStore(t *testing.T) {
+	s, clock := newFakeStore()
+	limit := Limit{RequestsPerMinute: 60, Burst: 2}
+
+	steps := []struct {
+		name    string
+		advance time.Duration
+		want    RateLimitResult
+	}{
+		{name: "first", want: RateLimitResult{Allowed: true, Limit: 2, Remaining: 1, Reset: time.Second}},
+		{name: "burst", want: RateLimitResult{Allowed: true, Limit: 2, Remaining: 0, Reset: 2 * time.Second}},
+		{name: "empty", want: RateLimitResult{Limit: 2, Remaining: 0, Reset: 2 * time.Second, RetryAfter: time.Second}},
+		{name: "half refilled", advance: 500 * time.Millisecond, want: RateLimitResult{Limit: 2, Remaining: 0, Reset: 1500 * time.Millisecond, RetryAfter: 500 * time.Millisecond}},
+		{name: "refilled one", advance: 500 * time.Millisecond, want: RateLimitResult{Allowed: true, Limit: 2, Remaining: 0, Reset: 2 * time.Second}},
+		{name: "refilled past the burst", advance: time.Minute, want: RateLimitResult{Allowed: true, Limit: 2, Remaining: 1, Reset: time.Second}},
+	}
+
+	for _, step := range steps {
+		clock.now = clock.now.Add(step.advance)
+
+		got, err := s.Take(context.Background(), "key", limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got != step.want {
+			t.Errorf("%s: Take() = %+v, want %+v", step.name, got, step.want)
+		}
+	}
+
+	got, err := s.Take(context.Background(), "other", limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Allowed || got.Remaining != 1 {
+		t.Errorf("other key: Take() = %+v, want its own full bucket", got)
+	}
+}
+
+func TestMemoryRateLimitStoreBurstDefault(t *testing.T) {
+	s, _ := newFakeStore()
+
+	got, err := s.Take(context.Background(), "key", Limit{RequestsPerMinute: 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Limit != 30 || got.Remaining != 29 {
+		t.Errorf("Take() = %+v, want a bucket of RequestsPerMinute", got)
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	type request struct {
+		method     string
+		path       string
+		remoteAddr string
+		xff        string
+	}
+
+	tests := []struct {
+		name     string
+		config   RateLimitConfig
+		trusted  []string
+		requests []request
+		// want is the status of each request.
+		want []int
+	}{
+		{
+			name:     "default limit",
+			config:   RateLimitConfig{Limit: Limit{RequestsPerMinute: 60, Burst: 2}},
+			requests: []request{{path: "/boards"}, {path: "/boards"}, {path: "/tickets"}},
+			want:     []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+		},
+		{
+			name:     "probes are skipped",
+			config:   RateLimitConfig{Limit: Limit{RequestsPerMinute: 60, Burst: 1}},
+			requests: []request{{path: "/health"}, {path: "/ready"}, {path: "/metrics"}, {path: "/boards"}, {path: "/health"}},
+			want:     []int{http.StatusOK, http.StatusOK, http.StatusOK, http.StatusOK, http.StatusOK},
+		},
+		{
+			name: "route override",
+			config: RateLimitConfig{
+				Limit:  Limit{RequestsPerMinute: 600, Burst: 100},
+				Routes: []RouteLimit{{Method: http.MethodPost, Path: "/sign-in", Limit: Limit{RequestsPerMinute: 60, Burst: 1}}},
+			},
+			requests: []request{{method: http.MethodPost, path: "/sign-in"}, {method: http.MethodPost, path: "/sign-in"}, {path: "/sign-in"}},
+			want:     []int{http.StatusOK, http.StatusTooManyRequests, http.StatusOK},
+		},
+		{
+			name: "route wildcard",
+			config: RateLimitConfig{
+				Routes: []RouteLimit{{Path: "/boards/*/reorder", Limit: Limit{RequestsPerMinute: 60, Burst: 1}}},
+			},
+			requests: []request{{path: "/boards/1/reorder"}, {path: "/boards/2/reorder"}, {path: "/boards/1/other"}},
+			want:     []int{http.StatusOK, http.StatusTooManyRequests, http.StatusOK},
+		},
+		{
+			name:     "separate buckets per IP",
+			config:   RateLimitConfig{Limit: Limit{RequestsPerMinute: 60, Burst: 1}},
+			requests: []request{{remoteAddr: "192.0.2.1:1234"}, {remoteAddr: "192.0.2.2:1234"}, {remoteAddr: "192.0.2.1:4321"}},
+			want:     []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+		},
+		{
+			name:     "X-Forwarded-For from an untrusted client is ignored",
+			config:   RateLimitConfig{Limit: Limit{RequestsPerMinute: 60, Burst: 1}},
+			trusted:  []string{"10.0.0.0/8"},
+			requests: []request{{remoteAddr: "192.0.2.1:1234", xff: "203.0.113.1"}, {remoteAddr: "192.0.2.1:1234", xff: "203.0.113.2"}},
+			want:     []int{http.StatusOK, http.StatusTooManyRequests},
+		},
+		{
+			name:    "X-Forwarded-For from a trusted proxy",
+			config:  RateLimitConfig{Limit: Limit{RequestsPerMinute: 60, Burst: 1}},
+			trusted: []string{"10.0.0.0/8"},
+			requests: []request{
+				{remoteAddr: "10.0.0.1:1234", xff: "203.0.113.1"},
+				{remoteAddr: "10.0.0.2:1234", xff: "203.0.113.2, 10.0.0.1"},
+				{remoteAddr: "10.0.0.1:1234", xff: "203.0.113.1"},
+			},
+			want: []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.Store, _ = newFakeStore()
+
+			e := echo.New()
+			e.IPExtractor = ipExtractor(tt.trusted)
+			e.Use(RateLimiter(tt.config))
+			e.Any("/*", func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			})
+
+			for i, r := range tt.requests {
+				if r.method == "" {
+					r.method = http.MethodGet
+				}
+				if r.path == "" {
+					r.path = "/"
+				}
+
+				req := httptest.NewRequest(r.method, r.path, nil)
+				if r.remoteAddr != "" {
+					req.RemoteAddr = r.remoteAddr
+				}
+				if r.xff != "" {
+					req.Header.Set(echo.HeaderXForwardedFor, r.xff)
+				}
+
+				rec := httptest.NewRecorder()
+				e.ServeHTTP(rec, req)
+
+				if rec.Code != tt.want[i] {
+					t.Errorf("request %d %s %s: status = %d, want %d", i, r.method, r.path, rec.Code, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimiterHeaders(t *testing.T) {
+	s, clock := newFakeStore()
+
+	e := echo.New()
+	e.Use(RateLimiter(RateLimitConfig{Limit: Limit{RequestsPerMinute: 30, Burst: 2}, Store: s}))
+	e.GET("/*", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+		return rec
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		advance time.Duration
+		status  int
+		headers map[string]string
+	}{
+		{
+			name: "first", path: "/boards", status: http.StatusOK,
+			headers: map[string]string{"RateLimit-Limit": "2", "RateLimit-Remaining": "1", "RateLimit-Reset": "2", "Retry-After": ""},
+		},
+		{
+			name: "last", path: "/boards", status: http.StatusOK,
+			headers: map[string]string{"RateLimit-Remaining": "0", "RateLimit-Reset": "4", "Retry-After": ""},
+		},
+		{
+			name: "limited", path: "/boards", status: http.StatusTooManyRequests,
+			headers: map[string]string{"RateLimit-Remaining": "0", "RateLimit-Reset": "4", "Retry-After": "2"},
+		},
+		{
+			name: "limited, rounded up", path: "/boards", advance: 500 * time.Millisecond, status: http.StatusTooManyRequests,
+			headers: map[string]string{"RateLimit-Reset": "4", "Retry-After": "2"},
+		},
+		{
+			name: "probe", path: "/health", status: http.StatusOK,
+			headers: map[string]string{"RateLimit-Limit": "", "Retry-After": ""},
+		},
+	}
+
+	for _, tt := range tests {
+		clock.now = clock.now.Add(tt.advance)
+
+		rec := get(tt.path)
+		if rec.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.name, rec.Code, tt.status)
+		}
+
+		for name, want := range tt.headers {
+			if got := rec.Header().Get(name); got != want {
+				t.Errorf("%s: %s = %q, want %q", tt.name, name, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package apikit
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const readyCheckTimeout = 2 * time.Second
+
+// ReadyCheck reports whether a dependency can serve traffic.
+type ReadyCheck func(ctx context.Context) error
+
+type ReadyResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+// AddReadyCheck registers a dependency that /ready reports on. The database
+// check is added by Start when a database is configured.
+func (api *API) AddReadyCheck(name string, check ReadyCheck) *API {
+	api.readyChecks[name] = check
+
+	return api
+}
+
+// ready answers 503 while any check fails or once shutdown has begun, so load
+// balancers stop sending traffic before the server stops accepting it.
+func (api *API) ready(c echo.Context) error {
+	res := ReadyResponse{
+		Status: "ok",
+		Checks: make(map[string]string, len(api.readyChecks)),
+	}
+
+	if api.shuttingDown.Load() {
+		res.Status = "shutting down"
+
+		return c.JSON(http.StatusServiceUnavailable, res)
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), readyCheckTimeout)
+	defer cancel()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range api.readyChecks {
+		wg.Add(1)
+		go func(name string, check ReadyCheck) {
+			defer wg.Done()
+
+			status := "ok"
+			err := check(ctx)
+			if err != nil {
+				status = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			res.Checks[name] = status
+			if err != nil {
+				res.Status = "unavailable"
+			}
+		}(name, check)
+	}
+	wg.Wait()
+
+	if res.Status != "ok" {
+		return c.JSON(http.StatusServiceUnavailable, res)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
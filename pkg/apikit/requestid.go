@@ -0,0 +1,23 @@
+package apikit
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestID accepts the caller's X-Request-ID or generates one, and writes it
+// back onto the request so it is forwarded to any service called from here.
+func RequestID() echo.MiddlewareFunc {
+	return middleware.RequestIDWithConfig(middleware.RequestIDConfig{
+		RequestIDHandler: func(c echo.Context, id string) {
+			c.Request().Header.Set(echo.HeaderXRequestID, id)
+			trace.SpanFromContext(c.Request().Context()).SetAttributes(attribute.String("http.request_id", id))
+		},
+	})
+}
+
+func RequestIDFrom(c echo.Context) string {
+	return c.Request().Header.Get(echo.HeaderXRequestID)
+}
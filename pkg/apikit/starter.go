@@ -4,27 +4,38 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"ticket/pkg/migrate"
+	"ticket/pkg/openapi"
+	"ticket/pkg/store"
 	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/labstack/echo/otelecho"
 )
 
-type GenericResponse[T any] struct {
-	Error   bool   `json:"error"`
-	Message string `json:"message"`
-	Data    T      `json:"data,omitempty"`
-}
-
 type Router func(api *API)
 
+const defaultShutdownTimeout = 15 * time.Second
+
 type API struct {
-	Config  *Configuration
-	DB      *sql.DB
-	App     *echo.Echo
-	routers []Router
+	Config       *Configuration
+	DB           *sql.DB
+	Store        store.Store
+	App          *echo.Echo
+	Logger       *slog.Logger
+	routers      []Router
+	spec         *openapi.Document
+	readyChecks  map[string]ReadyCheck
+	shuttingDown atomic.Bool
 }
 
 type CustomValidator struct {
@@ -33,20 +44,29 @@ type CustomValidator struct {
 
 func NewAPI(options ...Option) *API {
 	api := &API{
-		App:     echo.New(),
-		routers: []Router{},
-		Config:  &Configuration{},
+		App:         echo.New(),
+		routers:     []Router{},
+		Config:      &Configuration{},
+		readyChecks: map[string]ReadyCheck{},
 	}
 
 	for _, o := range options {
 		o(api)
 	}
 
+	api.Logger = newLogger(os.Stdout, api.Config.log, api.Config.api.Label)
+	slog.SetDefault(api.Logger)
+
+	api.App.HideBanner = true
+	api.App.HidePort = true
+	api.App.HTTPErrorHandler = api.errorHandler
+	api.App.IPExtractor = ipExtractor(api.Config.api.TrustedProxies)
+	api.App.Use(otelecho.Middleware(api.Config.api.Label), RequestID(), Metrics(api.Config.api.Label), AccessLog(api.Logger))
+
 	return api
 }
 
 func (api *API) UseRouter(routers ...Router) *API {
-	fmt.Println("Setting up routers...")
 	api.routers = append(api.routers, routers...)
 
 	return api
@@ -58,52 +78,136 @@ func (api *API) Use(middleware ...echo.MiddlewareFunc) *API {
 	return api
 }
 
+// Start runs the API until SIGINT or SIGTERM. It then fails /ready for
+// DrainPeriod, stops taking new connections, waits up to ShutdownTimeout for
+// in-flight requests and closes the database pool.
 func (api *API) Start() {
-	if isDBConfigValid(api.Config.db) {
-		fmt.Printf("Start connect in 5 seconds...\n")
-		time.Sleep(5 * time.Second)
-		fmt.Printf("\nConnecting to database...\n")
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	shutdownTracing, err := setupTracing(ctx, api.Config.api.Label, api.Config.tracing)
+	if err != nil {
+		api.fatal("failed to set up tracing", "error", err)
+	}
+	defer shutdownTracing(context.Background())
+
+	if api.Config.hasDB {
 		dbcf := api.Config.db
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		defer cancel()
-
-		var err error
-		maxRetries := 5
-		for i := 0; i < maxRetries; i++ {
-			api.DB, err = ConnectDBContext(ctx, dbcf)
-			if err == nil {
-				break
-			}
-			fmt.Printf("\nError connecting to database: %v\n", err.Error())
-			fmt.Printf("Retrying in 5 seconds...\n")
-			time.Sleep(5 * time.Second)
+
+		err = dbcf.validate()
+		if err != nil {
+			api.fatal("invalid database config", "error", err)
 		}
 
-		if api.DB == nil {
-			log.Fatalf("\nFailed to connect to database %s\n", dbcf.Name)
+		api.DB, err = api.connectDB(ctx)
+		if err != nil {
+			api.fatal("failed to connect to database", "database", dbcf.label(), "error", err)
 		}
+		defer api.DB.Close()
 
-		fmt.Printf("\nConnected to database %s\n", dbcf.Name)
+		api.Logger.Info("connected to database", "database", dbcf.label())
+
+		if api.Config.migrations.Auto {
+			api.migrate(ctx)
+		}
 
+		prometheus.MustRegister(collectors.NewDBStatsCollector(api.DB, dbcf.label()))
+		api.AddReadyCheck("database", api.DB.PingContext)
+
+		if api.Store == nil {
+			api.Store = store.New(dbcf.driver(), api.DB)
+		}
 	}
 
-	api.App.Validator = NewValidator()
+	api.Mount()
+
+	addr := fmt.Sprintf("%s:%d", api.Config.api.Host, api.Config.api.Port)
+	api.Logger.Info("starting API", "addr", addr)
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- api.App.Start(addr)
+	}()
+
+	select {
+	case err = <-errc:
+		if err != nil && err != http.ErrServerClosed {
+			api.fatal("server stopped", "error", err)
+		}
+
+		return
+	case <-ctx.Done():
+	}
+
+	api.shutdown()
+}
 
-	fmt.Println("Setting up health check endpoint...")
+// Mount registers the routes of the API and its routers. Start calls it;
+// tools that only need the routes, like cmd/openapi, call it instead.
+func (api *API) Mount() {
+	api.App.Validator = NewValidator()
 
 	api.App.GET("/health", func(c echo.Context) error {
 		return c.String(http.StatusOK, fmt.Sprintf("%s, OK!", api.Config.api.Label))
 	})
+	api.App.GET("/ready", api.ready)
+	api.App.GET("/metrics", metricsHandler())
 
 	for _, router := range api.routers {
 		router(api)
 	}
 
-	fmt.Printf("Starting API %s...\n", api.Config.api.Label)
+	if api.spec != nil {
+		api.serveSpec()
+	}
+}
+
+func (api *API) shutdown() {
+	api.shuttingDown.Store(true)
+
+	// Keep serving while load balancers see /ready fail and stop routing here.
+	if drain := api.Config.api.DrainPeriod; drain > 0 {
+		api.Logger.Info("draining", "period", drain)
+		time.Sleep(drain)
+	}
+
+	timeout := api.Config.api.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+
+	api.Logger.Info("shutting down", "timeout", timeout)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	err := api.App.Shutdown(ctx)
+	if err != nil {
+		api.Logger.Error("failed to drain connections", "error", err)
+
+		return
+	}
+
+	api.Logger.Info("stopped")
+}
+
+func (api *API) migrate(ctx context.Context) {
+	cf := api.Config.migrations
+
+	m, err := migrate.New(api.DB, api.Config.db.driver(), cf.Source, cf.Dir)
+	if err != nil {
+		api.fatal("failed to load migrations", "error", err)
+	}
+
+	err = m.Up(ctx)
+	if err != nil && err != migrate.ErrNoChange {
+		api.fatal("failed to migrate database", "error", err)
+	}
 
-	api.App.Logger.Fatal(api.App.Start(fmt.Sprintf("%s:%d", api.Config.api.Host, api.Config.api.Port)))
+	api.Logger.Info("database schema is up to date", "version", m.Latest())
 }
 
-func isDBConfigValid(dbcf DBConfig) bool {
-	return dbcf.Host != "" && dbcf.Name != "" && dbcf.User != "" && dbcf.Password != ""
+func (api *API) fatal(msg string, args ...any) {
+	api.Logger.Error(msg, args...)
+	os.Exit(1)
 }
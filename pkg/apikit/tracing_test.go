@@ -0,0 +1,42 @@
+package apikit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/labstack/echo/otelecho"
+)
+
+func TestTracingWithoutExporterLogsTraceID(t *testing.T) {
+	shutdown, err := setupTracing(context.Background(), "test", TracingConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer shutdown(context.Background())
+
+	var buf bytes.Buffer
+	e := echo.New()
+	e.Use(otelecho.Middleware("test"), RequestID(), AccessLog(newLogger(&buf, LogConfig{}, "test")))
+	e.GET("/", func(c echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	var line struct {
+		TraceID string `json:"trace_id"`
+	}
+	err = json.Unmarshal(buf.Bytes(), &line)
+	if err != nil {
+		t.Fatalf("access log %q: %v", buf.String(), err)
+	}
+
+	if len(line.TraceID) != 32 {
+		t.Errorf("trace_id = %q, want a trace id in %s", line.TraceID, buf.String())
+	}
+}
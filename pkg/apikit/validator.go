@@ -1,17 +1,130 @@
 package apikit
 
-import "github.com/go-playground/validator/v10"
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/th"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+)
+
+// validationFailed is the translation key of the message that sums up a
+// validation error.
+const validationFailed = "validation_failed"
 
 type Validator struct {
-	validator *validator.Validate
+	validator  *validator.Validate
+	translator *ut.UniversalTranslator
 }
 
+// NewValidator names fields after their json tags, so validation errors
+// point at the request body's keys, and translates messages to English and
+// Thai.
 func NewValidator() *Validator {
-	return &Validator{
-		validator: validator.New(validator.WithRequiredStructEnabled()),
-	}
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+
+		return name
+	})
+
+	english := en.New()
+	uni := ut.New(english, english, th.New())
+
+	enTrans, _ := uni.GetTranslator("en")
+	mustRegister(en_translations.RegisterDefaultTranslations(v, enTrans))
+	mustRegister(enTrans.Add(validationFailed, "request validation failed", false))
+
+	thTrans, _ := uni.GetTranslator("th")
+	mustRegister(registerThaiTranslations(v, thTrans))
+	mustRegister(thTrans.Add(validationFailed, "ข้อมูลไม่ถูกต้อง", false))
+
+	return &Validator{validator: v, translator: uni}
 }
 
 func (v *Validator) Validate(i interface{}) error {
 	return v.validator.Struct(i)
 }
+
+// Translator returns the translator for the most preferred language of an
+// Accept-Language header that has one, and English otherwise.
+func (v *Validator) Translator(acceptLanguage string) ut.Translator {
+	for _, lang := range parseAcceptLanguage(acceptLanguage) {
+		locale := strings.ReplaceAll(strings.ToLower(lang), "-", "_")
+		base, _, _ := strings.Cut(locale, "_")
+		if trans, ok := v.translator.FindTranslator(locale, base); ok {
+			return trans
+		}
+	}
+
+	return v.translator.GetFallback()
+}
+
+// Message translates fe with trans. Rules trans has no translation for fall
+// back to English.
+func (v *Validator) Message(fe validator.FieldError, trans ut.Translator) string {
+	msg := fe.Translate(trans)
+	if msg == fe.Error() {
+		msg = fe.Translate(v.translator.GetFallback())
+	}
+
+	return msg
+}
+
+// parseAcceptLanguage returns the languages of the header, most preferred
+// first. Languages with q=0 are left out.
+func parseAcceptLanguage(header string) []string {
+	type lang struct {
+		tag string
+		q   float64
+	}
+
+	var langs []lang
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+
+			q = parsed
+		}
+
+		if q > 0 {
+			langs = append(langs, lang{tag: tag, q: q})
+		}
+	}
+
+	sort.SliceStable(langs, func(i, j int) bool {
+		return langs[i].q > langs[j].q
+	})
+
+	tags := make([]string, 0, len(langs))
+	for _, l := range langs {
+		tags = append(tags, l.tag)
+	}
+
+	return tags
+}
+
+// mustRegister panics on a translation that fails to register, which is a
+// programming error found on startup.
+func mustRegister(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
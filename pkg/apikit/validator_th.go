@@ -0,0 +1,79 @@
+package apikit
+
+import (
+	"reflect"
+
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+)
+
+// thaiMessages covers the rules the services validate with. The validator
+// ships no Thai translations, and rules missing here fall back to the
+// validator's own English message.
+var thaiMessages = map[string]func(fe validator.FieldError) string{
+	"required": func(fe validator.FieldError) string {
+		return "กรุณาระบุ " + fe.Field()
+	},
+	"email": func(fe validator.FieldError) string {
+		return fe.Field() + " ต้องเป็นอีเมลที่ถูกต้อง"
+	},
+	"min": func(fe validator.FieldError) string {
+		switch kindOf(fe) {
+		case reflect.String:
+			return fe.Field() + " ต้องมีความยาวอย่างน้อย " + fe.Param() + " ตัวอักษร"
+		case reflect.Slice:
+			return fe.Field() + " ต้องมีอย่างน้อย " + fe.Param() + " รายการ"
+		}
+
+		return fe.Field() + " ต้องมีค่าอย่างน้อย " + fe.Param()
+	},
+	"max": func(fe validator.FieldError) string {
+		switch kindOf(fe) {
+		case reflect.String:
+			return fe.Field() + " ต้องมีความยาวไม่เกิน " + fe.Param() + " ตัวอักษร"
+		case reflect.Slice:
+			return fe.Field() + " ต้องมีไม่เกิน " + fe.Param() + " รายการ"
+		}
+
+		return fe.Field() + " ต้องมีค่าไม่เกิน " + fe.Param()
+	},
+	"len": func(fe validator.FieldError) string {
+		switch kindOf(fe) {
+		case reflect.String:
+			return fe.Field() + " ต้องมีความยาว " + fe.Param() + " ตัวอักษร"
+		case reflect.Slice:
+			return fe.Field() + " ต้องมี " + fe.Param() + " รายการ"
+		}
+
+		return fe.Field() + " ต้องมีค่าเท่ากับ " + fe.Param()
+	},
+	"oneof": func(fe validator.FieldError) string {
+		return fe.Field() + " ต้องเป็นค่าใดค่าหนึ่งใน [" + fe.Param() + "]"
+	},
+}
+
+func registerThaiTranslations(v *validator.Validate, trans ut.Translator) error {
+	for tag, message := range thaiMessages {
+		message := message
+		err := v.RegisterTranslation(tag, trans, func(ut.Translator) error {
+			return nil
+		}, func(_ ut.Translator, fe validator.FieldError) string {
+			return message(fe)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// kindOf folds arrays and maps into slices, as their messages count items.
+func kindOf(fe validator.FieldError) reflect.Kind {
+	switch k := fe.Kind(); k {
+	case reflect.Array, reflect.Map:
+		return reflect.Slice
+	default:
+		return k
+	}
+}
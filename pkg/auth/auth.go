@@ -5,6 +5,7 @@ type AuthConfig struct {
 	PublicKey          []byte
 	AccessTokenExpire  int
 	RefreshTokenExpire int
+	IdentitySecret     []byte
 }
 
 type Auth struct {
@@ -16,6 +17,7 @@ type Configurer interface {
 	PublicKey() []byte
 	AccessTokenExpire() int
 	RefreshTokenExpire() int
+	IdentitySecret() []byte
 }
 
 func New(c Configurer) *Auth {
@@ -25,6 +27,7 @@ func New(c Configurer) *Auth {
 			PublicKey:          c.PublicKey(),
 			AccessTokenExpire:  c.AccessTokenExpire(),
 			RefreshTokenExpire: c.RefreshTokenExpire(),
+			IdentitySecret:     c.IdentitySecret(),
 		},
 	}
 }
@@ -1,8 +1,14 @@
 package auth
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
+	"net/http"
 	"strings"
+	"ticket/pkg/apikit"
+	"ticket/pkg/db"
+	"ticket/pkg/wire"
 
 	"github.com/labstack/echo/v4"
 )
@@ -12,29 +18,110 @@ type AuthContext struct {
 	echo.Context
 }
 
-func Middleware(c Configurer) echo.MiddlewareFunc {
+// UserFinder loads the account behind a token, e.g. a store.Store.
+type UserFinder interface {
+	FindUserByID(ctx context.Context, id uint64) (db.User, error)
+}
+
+// MiddlewareOption changes what Middleware lets through.
+type MiddlewareOption func(*middlewareOptions)
+
+type middlewareOptions struct {
+	allowPasswordReset bool
+}
+
+// AllowPasswordReset lets sessions limited by ScopePasswordReset through, for
+// the routes a user needs to change their password.
+func AllowPasswordReset() MiddlewareOption {
+	return func(o *middlewareOptions) {
+		o.allowPasswordReset = true
+	}
+}
+
+// Middleware authenticates the request and rejects disabled users, and users
+// who must change their password unless AllowPasswordReset is given. Whether
+// a user exists and is disabled is cached for statusTTL.
+func Middleware(c Configurer, q UserFinder, opts ...MiddlewareOption) echo.MiddlewareFunc {
 	a := New(c)
+	statuses := newStatusCache(q, statusTTL)
+
+	var o middlewareOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
-			bearer := c.Request().Header.Get("Authorization")
+			claims, err := authenticate(a, c.Request())
+			if err != nil || claims == nil {
+				return echo.ErrUnauthorized
+			}
 
-			s := strings.Split(bearer, " ")
+			status, err := statuses.get(c.Request().Context(), claims.UserID)
+			if err != nil {
+				return err
+			}
 
-			if len(s) != 2 {
+			if !status.found {
 				return echo.ErrUnauthorized
 			}
 
-			token := s[1]
+			if status.disabled {
+				return apikit.NewError(http.StatusForbidden, wire.CodeAccountDisabled, "account disabled")
+			}
 
-			claims, err := a.ParseToken(token)
-			if err != nil || claims == nil {
-				fmt.Println("err:", err)
-				return echo.ErrUnauthorized
+			if claims.HasScope(ScopePasswordReset) && !o.allowPasswordReset {
+				return apikit.NewError(http.StatusForbidden, wire.CodePasswordResetRequired, "password change required")
 			}
 
 			c.Set("claims", claims)
+			c.Set(apikit.UserIDKey, claims.UserID)
+
+			return next(c)
+		}
+	}
+}
+
+// authenticate prefers the identity forwarded by the gateway and falls back to
+// the bearer token for requests that reach the service directly.
+func authenticate(a *Auth, r *http.Request) (*Claims, error) {
+	if identity := r.Header.Get(IdentityHeader); identity != "" && a.HasIdentitySecret() {
+		return a.ParseIdentity(identity)
+	}
+
+	bearer := r.Header.Get("Authorization")
+
+	s := strings.Split(bearer, " ")
+
+	if len(s) != 2 {
+		return nil, fmt.Errorf("missing bearer token")
+	}
+
+	return a.ParseToken(s[1])
+}
+
+// AdminMiddleware must run after Middleware. The admin flag is read from the
+// database on every request so revoking it takes effect immediately.
+func AdminMiddleware(q UserFinder) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			claims, ok := c.Get("claims").(*Claims)
+			if !ok {
+				return echo.ErrUnauthorized
+			}
+
+			user, err := q.FindUserByID(c.Request().Context(), claims.UserID)
+			if err != nil {
+				if err == sql.ErrNoRows {
+					return echo.ErrUnauthorized
+				}
 
-			fmt.Println("Authorized user: ", claims.UserID)
+				return err
+			}
+
+			if !user.IsAdmin {
+				return echo.ErrForbidden
+			}
 
 			return next(c)
 		}
@@ -0,0 +1,151 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sync"
+	"ticket/config"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+var (
+	ErrInvalidNonce = errors.New("invalid nonce")
+	// ErrDiscovery wraps failures to reach the provider's discovery document.
+	ErrDiscovery = errors.New("oidc discovery failed")
+)
+
+type OIDCIdentity struct {
+	Subject       string `json:"sub"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Name          string `json:"name"`
+	GivenName     string `json:"given_name"`
+	FamilyName    string `json:"family_name"`
+	Nonce         string `json:"nonce"`
+}
+
+type OIDCProvider struct {
+	Name     string
+	config   config.OIDCProvider
+	mu       sync.Mutex
+	provider *oidc.Provider
+}
+
+func NewOIDCProvider(name string, cf config.OIDCProvider) *OIDCProvider {
+	return &OIDCProvider{
+		Name:   name,
+		config: cf,
+	}
+}
+
+func NewOIDCProviders(cf map[string]config.OIDCProvider) map[string]*OIDCProvider {
+	providers := make(map[string]*OIDCProvider, len(cf))
+	for name, c := range cf {
+		providers[name] = NewOIDCProvider(name, c)
+	}
+
+	return providers
+}
+
+func (p *OIDCProvider) ClientRedirectURL() string {
+	return p.config.ClientRedirectURL
+}
+
+// discover fetches the provider metadata on first use, so a provider that is
+// down at startup does not keep the service from booting.
+func (p *OIDCProvider) discover(ctx context.Context) (*oidc.Provider, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.provider != nil {
+		return p.provider, nil
+	}
+
+	provider, err := oidc.NewProvider(ctx, p.config.Issuer)
+	if err != nil {
+		return nil, fmt.Errorf("%w for %s: %w", ErrDiscovery, p.Name, err)
+	}
+
+	p.provider = provider
+
+	return provider, nil
+}
+
+func (p *OIDCProvider) oauth2Config(provider *oidc.Provider) oauth2.Config {
+	scopes := p.config.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{oidc.ScopeOpenID, "email", "profile"}
+	}
+
+	return oauth2.Config{
+		ClientID:     p.config.ClientID,
+		ClientSecret: p.config.ClientSecret,
+		RedirectURL:  p.config.RedirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       scopes,
+	}
+}
+
+func (p *OIDCProvider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	provider, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	oc := p.oauth2Config(provider)
+
+	return oc.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier)), nil
+}
+
+func (p *OIDCProvider) Exchange(ctx context.Context, code, verifier, nonce string) (OIDCIdentity, error) {
+	provider, err := p.discover(ctx)
+	if err != nil {
+		return OIDCIdentity{}, err
+	}
+
+	oc := p.oauth2Config(provider)
+
+	token, err := oc.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return OIDCIdentity{}, err
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return OIDCIdentity{}, fmt.Errorf("missing id_token in token response")
+	}
+
+	idToken, err := provider.Verifier(&oidc.Config{ClientID: p.config.ClientID}).Verify(ctx, rawIDToken)
+	if err != nil {
+		return OIDCIdentity{}, err
+	}
+
+	var identity OIDCIdentity
+	err = idToken.Claims(&identity)
+	if err != nil {
+		return OIDCIdentity{}, err
+	}
+
+	if identity.Nonce != nonce {
+		return OIDCIdentity{}, ErrInvalidNonce
+	}
+
+	identity.Subject = idToken.Subject
+
+	return identity, nil
+}
+
+func RandomString(n int) (string, error) {
+	b := make([]byte, n)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"ticket/config"
+	"ticket/pkg/auth/oidctest"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+)
+
+// login runs AuthCodeURL and the provider's side of the flow, and returns the
+// code and the verifier to exchange it with.
+func login(t *testing.T, mock *oidctest.Provider, p *OIDCProvider, nonce string, claims jwt.MapClaims) (string, string) {
+	t.Helper()
+
+	verifier := oauth2.GenerateVerifier()
+	authURL, err := p.AuthCodeURL(context.Background(), "state", nonce, verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code, state := mock.Authorize(t, authURL, claims)
+	if state != "state" {
+		t.Fatalf("state = %q, want it passed through", state)
+	}
+
+	return code, verifier
+}
+
+func TestOIDCExchange(t *testing.T) {
+	mock := oidctest.New(t)
+	p := NewOIDCProvider("test", mock.Config())
+
+	code, verifier := login(t, mock, p, "nonce", jwt.MapClaims{
+		"sub":            "subject-1",
+		"email":          "ada@example.com",
+		"email_verified": true,
+		"given_name":     "Ada",
+	})
+
+	identity, err := p.Exchange(context.Background(), code, verifier, "nonce")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := OIDCIdentity{Subject: "subject-1", Email: "ada@example.com", EmailVerified: true, GivenName: "Ada", Nonce: "nonce"}
+	if identity != want {
+		t.Errorf("identity = %+v, want %+v", identity, want)
+	}
+}
+
+func TestOIDCExchangeNonceMismatch(t *testing.T) {
+	mock := oidctest.New(t)
+	p := NewOIDCProvider("test", mock.Config())
+
+	code, verifier := login(t, mock, p, "nonce", jwt.MapClaims{"sub": "subject-1", "nonce": "replayed"})
+
+	_, err := p.Exchange(context.Background(), code, verifier, "nonce")
+	if !errors.Is(err, ErrInvalidNonce) {
+		t.Fatalf("Exchange error = %v, want %v", err, ErrInvalidNonce)
+	}
+}
+
+func TestOIDCExchangeRejects(t *testing.T) {
+	tests := []struct {
+		name   string
+		claims jwt.MapClaims
+		// verifier replaces the login's PKCE verifier when set.
+		verifier string
+	}{
+		{name: "wrong verifier", claims: jwt.MapClaims{"sub": "subject-1"}, verifier: oauth2.GenerateVerifier()},
+		{name: "other audience", claims: jwt.MapClaims{"sub": "subject-1", "aud": "another-client"}},
+		{name: "other issuer", claims: jwt.MapClaims{"sub": "subject-1", "iss": "https://issuer.invalid"}},
+		{name: "expired", claims: jwt.MapClaims{"sub": "subject-1", "exp": 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := oidctest.New(t)
+			p := NewOIDCProvider("test", mock.Config())
+
+			code, verifier := login(t, mock, p, "nonce", tt.claims)
+			if tt.verifier != "" {
+				verifier = tt.verifier
+			}
+
+			_, err := p.Exchange(context.Background(), code, verifier, "nonce")
+			if err == nil {
+				t.Fatal("Exchange succeeded, want an error")
+			}
+
+			if errors.Is(err, ErrDiscovery) {
+				t.Errorf("Exchange error = %v, want it not to be a discovery failure", err)
+			}
+		})
+	}
+}
+
+func TestOIDCDiscoveryFailure(t *testing.T) {
+	mock := oidctest.New(t)
+	issuer := mock.URL
+	mock.Close()
+
+	p := NewOIDCProvider("test", config.OIDCProvider{Issuer: issuer, ClientID: oidctest.ClientID})
+
+	_, err := p.AuthCodeURL(context.Background(), "state", "nonce", oauth2.GenerateVerifier())
+	if !errors.Is(err, ErrDiscovery) {
+		t.Fatalf("AuthCodeURL error = %v, want %v", err, ErrDiscovery)
+	}
+}
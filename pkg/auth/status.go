@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+)
+
+// statusTTL is how long Middleware trusts a user's looked up status, and so
+// how long a disabled user can keep using a session on each service.
+const statusTTL = 30 * time.Second
+
+// userStatus is what Middleware needs to know about a user beyond the claims.
+type userStatus struct {
+	found    bool
+	disabled bool
+	expires  time.Time
+}
+
+// statusCache keeps each user's status for statusTTL, so an authenticated
+// request does not query the users table every time.
+type statusCache struct {
+	q   UserFinder
+	ttl time.Duration
+
+	mu        sync.Mutex
+	entries   map[uint64]userStatus
+	nextSweep time.Time
+}
+
+func newStatusCache(q UserFinder, ttl time.Duration) *statusCache {
+	return &statusCache{
+		q:       q,
+		ttl:     ttl,
+		entries: map[uint64]userStatus{},
+	}
+}
+
+// get returns the user's status, looking it up when it is missing or stale.
+// Lookup errors are not cached.
+func (s *statusCache) get(ctx context.Context, id uint64) (userStatus, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	status, ok := s.entries[id]
+	s.mu.Unlock()
+
+	if ok && now.Before(status.expires) {
+		return status, nil
+	}
+
+	user, err := s.q.FindUserByID(ctx, id)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return userStatus{}, err
+	}
+
+	status = userStatus{
+		found:    err == nil,
+		disabled: user.DisabledAt.Valid,
+		expires:  now.Add(s.ttl),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[id] = status
+	s.sweep(now)
+
+	return status, nil
+}
+
+// sweep drops expired entries once per ttl, so users who stop sending
+// requests do not stay in memory. It must be called with mu held.
+func (s *statusCache) sweep(now time.Time) {
+	if now.Before(s.nextSweep) {
+		return
+	}
+
+	for id, status := range s.entries {
+		if !now.Before(status.expires) {
+			delete(s.entries, id)
+		}
+	}
+
+	s.nextSweep = now.Add(s.ttl)
+}
@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"ticket/pkg/db"
+	"time"
+
+	"github.com/guregu/null/v5"
+)
+
+type countingFinder struct {
+	users   map[uint64]db.User
+	lookups int
+}
+
+func (f *countingFinder) FindUserByID(_ context.Context, id uint64) (db.User, error) {
+	f.lookups++
+
+	user, ok := f.users[id]
+	if !ok {
+		return db.User{}, sql.ErrNoRows
+	}
+
+	return user, nil
+}
+
+func TestStatusCache(t *testing.T) {
+	f := &countingFinder{users: map[uint64]db.User{1: {ID: 1}}}
+	s := newStatusCache(f, time.Hour)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		status, err := s.get(ctx, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !status.found || status.disabled {
+			t.Fatalf("status = %+v, want found and enabled", status)
+		}
+	}
+
+	if f.lookups != 1 {
+		t.Errorf("looked up %d times, want 1", f.lookups)
+	}
+
+	status, err := s.get(ctx, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if status.found {
+		t.Errorf("unknown user found")
+	}
+}
+
+func TestStatusCacheExpiry(t *testing.T) {
+	f := &countingFinder{users: map[uint64]db.User{1: {ID: 1}}}
+	s := newStatusCache(f, time.Millisecond)
+	ctx := context.Background()
+
+	_, err := s.get(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f.users[1] = db.User{ID: 1, DisabledAt: null.TimeFrom(time.Now())}
+	time.Sleep(2 * time.Millisecond)
+
+	status, err := s.get(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !status.disabled {
+		t.Errorf("status = %+v after expiry, want disabled", status)
+	}
+}
@@ -2,23 +2,46 @@ package auth
 
 import (
 	"fmt"
+	"slices"
+	"ticket/pkg/db"
+	"ticket/pkg/wire"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// ScopePasswordReset limits a session to the routes a user needs to change
+// their password. It is given to users with password_reset_required set.
+const ScopePasswordReset = "password_reset"
+
 type TokenPayload struct {
-	UserID uint64 `json:"user_id"`
+	UserID         uint64   `json:"user_id"`
+	ImpersonatorID uint64   `json:"impersonator_id,omitempty"`
+	Scopes         []string `json:"scopes,omitempty"`
 }
 
 type Claims struct {
-	UserID uint64 `json:"user_id"`
+	UserID         uint64   `json:"user_id"`
+	ImpersonatorID uint64   `json:"impersonator_id,omitempty"`
+	Scopes         []string `json:"scopes,omitempty"`
 	jwt.RegisteredClaims
 }
 
-type Tokens struct {
-	AccessToken  string `json:"access_token"`
-	RefreshToken string `json:"refresh_token"`
+// PayloadFor returns the payload of a new session for user.
+func PayloadFor(user db.User) TokenPayload {
+	payload := TokenPayload{
+		UserID: user.ID,
+	}
+
+	if user.PasswordResetRequired {
+		payload.Scopes = []string{ScopePasswordReset}
+	}
+
+	return payload
+}
+
+func (c *Claims) HasScope(scope string) bool {
+	return slices.Contains(c.Scopes, scope)
 }
 
 type GenerateTokensConfig struct {
@@ -26,19 +49,18 @@ type GenerateTokensConfig struct {
 	RefreshTokenExpire int
 }
 
-func (a *Auth) GenerateTokens(tokenPayload TokenPayload) (Tokens, error) {
+func (a *Auth) GenerateTokens(tokenPayload TokenPayload) (wire.Tokens, error) {
 	accessToken, err := a.GenerateTokenString(tokenPayload, a.config.AccessTokenExpire)
-	fmt.Println(err)
 	if err != nil {
-		return Tokens{}, err
+		return wire.Tokens{}, err
 	}
 
 	refreshToken, err := a.GenerateTokenString(tokenPayload, a.config.RefreshTokenExpire)
 	if err != nil {
-		return Tokens{}, err
+		return wire.Tokens{}, err
 	}
 
-	return Tokens{
+	return wire.Tokens{
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
 	}, nil
@@ -46,8 +68,10 @@ func (a *Auth) GenerateTokens(tokenPayload TokenPayload) (Tokens, error) {
 
 func (a *Auth) GenerateTokenString(tokenPayload TokenPayload, unixDuration int) (string, error) {
 	claims := Claims{
-		tokenPayload.UserID,
-		jwt.RegisteredClaims{
+		UserID:         tokenPayload.UserID,
+		ImpersonatorID: tokenPayload.ImpersonatorID,
+		Scopes:         tokenPayload.Scopes,
+		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Second * time.Duration(unixDuration))),
 		},
 	}
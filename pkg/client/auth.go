@@ -0,0 +1,73 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"ticket/pkg/wire"
+)
+
+// ErrSignedOut is returned by Refresh before the client has tokens.
+var ErrSignedOut = errors.New("client: not signed in")
+
+type signInRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type refreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// SignIn exchanges an email and password for tokens, which the client sends
+// from then on.
+func (c *Client) SignIn(ctx context.Context, email, password string) (wire.Tokens, error) {
+	var tokens wire.Tokens
+	err := c.call(ctx, http.MethodPost, c.authenPrefix+"/sign-in", signInRequest{
+		Email:    email,
+		Password: password,
+	}, "", &tokens)
+	if err != nil {
+		return wire.Tokens{}, err
+	}
+
+	c.setTokens(tokens)
+
+	return tokens, nil
+}
+
+// Refresh exchanges the refresh token for new tokens. Requests answered 401
+// refresh on their own, so callers rarely need it.
+func (c *Client) Refresh(ctx context.Context) (wire.Tokens, error) {
+	refreshToken := c.Tokens().RefreshToken
+	if refreshToken == "" {
+		return wire.Tokens{}, ErrSignedOut
+	}
+
+	var tokens wire.Tokens
+	err := c.call(ctx, http.MethodPost, c.authenPrefix+"/refresh-token", refreshTokenRequest{
+		RefreshToken: refreshToken,
+	}, "", &tokens)
+	if err != nil {
+		return wire.Tokens{}, err
+	}
+
+	c.setTokens(tokens)
+
+	return tokens, nil
+}
+
+// refreshFrom refreshes unless another caller already replaced the stale
+// access token while this one waited.
+func (c *Client) refreshFrom(ctx context.Context, stale string) error {
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+
+	if c.Tokens().AccessToken != stale {
+		return nil
+	}
+
+	_, err := c.Refresh(ctx)
+
+	return err
+}
@@ -0,0 +1,46 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"ticket/pkg/db"
+)
+
+type titleRequest struct {
+	Title string `json:"title"`
+}
+
+func (c *Client) boardPath(boardID uint32) string {
+	return fmt.Sprintf("%s/boards/%d", c.ticketPrefix, boardID)
+}
+
+// Boards lists the boards of the signed-in user, without their statuses.
+func (c *Client) Boards(ctx context.Context) ([]db.Board, error) {
+	var boards []db.Board
+	err := c.do(ctx, http.MethodGet, c.ticketPrefix+"/boards", nil, &boards)
+
+	return boards, err
+}
+
+// Board returns a board with its statuses and their tickets, both in order.
+func (c *Client) Board(ctx context.Context, boardID uint32) (db.BoardWithRelated, error) {
+	var board db.BoardWithRelated
+	err := c.do(ctx, http.MethodGet, c.boardPath(boardID), nil, &board)
+
+	return board, err
+}
+
+func (c *Client) CreateBoard(ctx context.Context, title string) (db.BoardWithRelated, error) {
+	var board db.BoardWithRelated
+	err := c.do(ctx, http.MethodPost, c.ticketPrefix+"/boards", titleRequest{Title: title}, &board)
+
+	return board, err
+}
+
+func (c *Client) RenameBoard(ctx context.Context, boardID uint32, title string) (db.BoardWithRelated, error) {
+	var board db.BoardWithRelated
+	err := c.do(ctx, http.MethodPut, c.boardPath(boardID), titleRequest{Title: title}, &board)
+
+	return board, err
+}
@@ -0,0 +1,283 @@
+// Package client is a typed Go client for the ticket and authen APIs behind
+// the gateway. It signs in, refreshes the access token when a request is
+// answered 401, retries requests that are safe to repeat, and returns error
+// responses as *Error.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"ticket/pkg/wire"
+	"time"
+)
+
+const (
+	defaultAuthenPrefix = "/authen-service"
+	defaultTicketPrefix = "/ticket-service"
+	defaultTimeout      = 30 * time.Second
+)
+
+type Client struct {
+	baseURL      string
+	http         *http.Client
+	retry        RetryPolicy
+	authenPrefix string
+	ticketPrefix string
+	onTokens     func(wire.Tokens)
+
+	mu     sync.Mutex
+	tokens wire.Tokens
+	// refreshMu lets one caller refresh at a time; the others reuse its tokens.
+	refreshMu sync.Mutex
+}
+
+type Option func(*Client)
+
+// RetryPolicy controls how often a failed request is sent again. Requests
+// answered 429 are always retried, after Retry-After when the server sets it.
+// Connection failures and 502, 503 and 504 are only retried for GET, PUT and
+// DELETE, which the API keeps idempotent.
+type RetryPolicy struct {
+	// Attempts is how many times a request is sent in total. The default is 3.
+	Attempts int
+	// Backoff is the first wait between attempts, doubling up to MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+var defaultRetry = RetryPolicy{
+	Attempts:   3,
+	Backoff:    100 * time.Millisecond,
+	MaxBackoff: 2 * time.Second,
+}
+
+// WithHTTPClient sends requests with hc instead of a client with a 30 second
+// timeout.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.http = hc
+	}
+}
+
+// WithRetry replaces the default policy. Fields left zero keep their default.
+func WithRetry(p RetryPolicy) Option {
+	return func(c *Client) {
+		if p.Attempts > 0 {
+			c.retry.Attempts = p.Attempts
+		}
+
+		if p.Backoff > 0 {
+			c.retry.Backoff = p.Backoff
+		}
+
+		if p.MaxBackoff > 0 {
+			c.retry.MaxBackoff = p.MaxBackoff
+		}
+	}
+}
+
+// WithTokens starts the client signed in, e.g. with tokens saved earlier.
+func WithTokens(t wire.Tokens) Option {
+	return func(c *Client) {
+		c.tokens = t
+	}
+}
+
+// OnTokens calls fn with the new tokens after every sign-in and refresh, so
+// they can be saved.
+func OnTokens(fn func(wire.Tokens)) Option {
+	return func(c *Client) {
+		c.onTokens = fn
+	}
+}
+
+// WithPrefixes sets the gateway route prefixes of the services, which default
+// to /authen-service and /ticket-service.
+func WithPrefixes(authen, ticket string) Option {
+	return func(c *Client) {
+		c.authenPrefix = authen
+		c.ticketPrefix = ticket
+	}
+}
+
+// New returns a client for the gateway at baseURL, e.g. http://localhost:3999.
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Scheme == "" || u.Host == "" {
+		return nil, errors.New("client: base URL must be absolute, e.g. http://localhost:3999")
+	}
+
+	c := &Client{
+		baseURL:      strings.TrimRight(u.String(), "/"),
+		http:         &http.Client{Timeout: defaultTimeout},
+		retry:        defaultRetry,
+		authenPrefix: defaultAuthenPrefix,
+		ticketPrefix: defaultTicketPrefix,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c, nil
+}
+
+// Tokens returns the tokens the client sends, which are empty before SignIn.
+func (c *Client) Tokens() wire.Tokens {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.tokens
+}
+
+func (c *Client) setTokens(t wire.Tokens) {
+	c.mu.Lock()
+	c.tokens = t
+	c.mu.Unlock()
+
+	if c.onTokens != nil {
+		c.onTokens(t)
+	}
+}
+
+// do sends a request and decodes the response into out. A request sent with
+// an access token that is answered 401 is sent once more after a refresh.
+func (c *Client) do(ctx context.Context, method, path string, body, out any) error {
+	token := c.Tokens().AccessToken
+	err := c.call(ctx, method, path, body, token, out)
+
+	var ae *Error
+	if token == "" || !errors.As(err, &ae) || ae.Status != http.StatusUnauthorized {
+		return err
+	}
+
+	err = c.refreshFrom(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	return c.call(ctx, method, path, body, c.Tokens().AccessToken, out)
+}
+
+// call sends body as JSON with token, which may be empty.
+func (c *Client) call(ctx context.Context, method, path string, body any, token string, out any) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return err
+		}
+	}
+
+	return c.send(ctx, method, path, payload, token, out)
+}
+
+// send runs the retry policy around single attempts.
+func (c *Client) send(ctx context.Context, method, path string, payload []byte, token string, out any) error {
+	backoff := c.retry.Backoff
+	for attempt := 1; ; attempt++ {
+		err := c.attempt(ctx, method, path, payload, token, out)
+		if err == nil || attempt >= c.retry.Attempts || !retryable(ctx, method, err) {
+			return err
+		}
+
+		wait := backoff + rand.N(backoff)
+		var ae *Error
+		if errors.As(err, &ae) && ae.retryAfter > wait {
+			wait = ae.retryAfter
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+
+		backoff = min(backoff*2, c.retry.MaxBackoff)
+	}
+}
+
+func (c *Client) attempt(ctx context.Context, method, path string, payload []byte, token string, out any) error {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusBadRequest {
+		return newError(res)
+	}
+
+	if out == nil || res.StatusCode == http.StatusNoContent {
+		_, err = io.Copy(io.Discard, res.Body)
+
+		return err
+	}
+
+	return json.NewDecoder(res.Body).Decode(out)
+}
+
+func retryable(ctx context.Context, method string, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var ae *Error
+	if errors.As(err, &ae) {
+		switch ae.Status {
+		case http.StatusTooManyRequests:
+			return true
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return isIdempotent(method)
+		}
+
+		return false
+	}
+
+	// http.Client reports transport failures as *url.Error; anything else,
+	// like a response that does not decode, would fail again.
+	var ue *url.Error
+
+	return errors.As(err, &ue) && isIdempotent(method)
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+
+	return false
+}
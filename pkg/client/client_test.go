@@ -0,0 +1,285 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"ticket/pkg/wire"
+	"time"
+)
+
+var fastRetry = WithRetry(RetryPolicy{Attempts: 3, Backoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond})
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, code wire.Code) {
+	writeJSON(w, status, wire.GenericResponse[wire.ErrorResponse]{
+		Error:   true,
+		Message: http.StatusText(status),
+		Data:    wire.ErrorResponse{Code: code},
+	})
+}
+
+// authServer accepts the access token "fresh" and refreshes any refresh
+// token to it, counting refreshes.
+func authServer(t *testing.T, refreshes *atomic.Int32) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /authen-service/refresh-token", func(w http.ResponseWriter, r *http.Request) {
+		refreshes.Add(1)
+		// Hold the refresh long enough for concurrent callers to pile up.
+		time.Sleep(20 * time.Millisecond)
+		writeJSON(w, http.StatusOK, wire.Tokens{AccessToken: "fresh", RefreshToken: "refresh-2"})
+	})
+	mux.HandleFunc("GET /ticket-service/boards", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer fresh" {
+			writeError(w, http.StatusUnauthorized, wire.CodeUnauthorized)
+
+			return
+		}
+
+		writeJSON(w, http.StatusOK, []any{})
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestRefreshOn401(t *testing.T) {
+	var refreshes atomic.Int32
+	srv := authServer(t, &refreshes)
+
+	var saved []wire.Tokens
+	c, err := New(srv.URL,
+		WithTokens(wire.Tokens{AccessToken: "stale", RefreshToken: "refresh-1"}),
+		OnTokens(func(tk wire.Tokens) { saved = append(saved, tk) }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = c.Boards(context.Background())
+	if err != nil {
+		t.Fatalf("Boards: %v", err)
+	}
+
+	if n := refreshes.Load(); n != 1 {
+		t.Errorf("refreshed %d times, want 1", n)
+	}
+
+	want := wire.Tokens{AccessToken: "fresh", RefreshToken: "refresh-2"}
+	if len(saved) != 1 || saved[0] != want {
+		t.Errorf("OnTokens got %v, want [%v]", saved, want)
+	}
+}
+
+func TestRefreshOn401Concurrent(t *testing.T) {
+	var refreshes atomic.Int32
+	srv := authServer(t, &refreshes)
+
+	c, err := New(srv.URL, WithTokens(wire.Tokens{AccessToken: "stale", RefreshToken: "refresh-1"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const callers = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_, err := c.Boards(context.Background())
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Boards: %v", err)
+		}
+	}
+
+	if n := refreshes.Load(); n != 1 {
+		t.Errorf("refreshed %d times for %d callers, want 1", n, callers)
+	}
+}
+
+func TestRefreshFailure(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusUnauthorized, wire.CodeUnauthorized)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c, err := New(srv.URL, WithTokens(wire.Tokens{AccessToken: "stale", RefreshToken: "expired"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = c.Boards(context.Background())
+
+	var ae *Error
+	if !errors.As(err, &ae) || ae.Code != wire.CodeUnauthorized {
+		t.Fatalf("Boards error = %v, want an unauthorized *Error", err)
+	}
+}
+
+func TestRetryPolicy(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		status       int
+		wantAttempts int32
+	}{
+		{"GET 503 is retried", http.MethodGet, http.StatusServiceUnavailable, 3},
+		{"PUT 502 is retried", http.MethodPut, http.StatusBadGateway, 3},
+		{"DELETE 504 is retried", http.MethodDelete, http.StatusGatewayTimeout, 3},
+		{"POST 503 is not retried", http.MethodPost, http.StatusServiceUnavailable, 1},
+		{"PATCH 502 is not retried", http.MethodPatch, http.StatusBadGateway, 1},
+		{"POST 429 is retried", http.MethodPost, http.StatusTooManyRequests, 3},
+		{"GET 404 is not retried", http.MethodGet, http.StatusNotFound, 1},
+		{"GET 500 is not retried", http.MethodGet, http.StatusInternalServerError, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts.Add(1)
+				writeError(w, tt.status, wire.CodeFor(tt.status))
+			}))
+			defer srv.Close()
+
+			c, err := New(srv.URL, fastRetry)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = c.do(context.Background(), tt.method, "/x", nil, nil)
+
+			var ae *Error
+			if !errors.As(err, &ae) || ae.Status != tt.status {
+				t.Fatalf("error = %v, want status %d", err, tt.status)
+			}
+
+			if n := attempts.Load(); n != tt.wantAttempts {
+				t.Errorf("sent %d times, want %d", n, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestRetryUntilSuccess(t *testing.T) {
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) < 3 {
+			writeError(w, http.StatusServiceUnavailable, wire.CodeUnavailable)
+
+			return
+		}
+
+		writeJSON(w, http.StatusOK, map[string]string{"ok": "yes"})
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, fastRetry)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out map[string]string
+	err = c.do(context.Background(), http.MethodGet, "/x", nil, &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if out["ok"] != "yes" || attempts.Load() != 3 {
+		t.Errorf("got %v after %d attempts, want ok after 3", out, attempts.Load())
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			writeError(w, http.StatusTooManyRequests, wire.CodeRateLimited)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, fastRetry)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	err = c.do(context.Background(), http.MethodPost, "/x", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if waited := time.Since(start); waited < time.Second {
+		t.Errorf("retried after %v, want at least the 1s of Retry-After", waited)
+	}
+}
+
+type failingTransport struct {
+	calls atomic.Int32
+}
+
+func (f *failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	f.calls.Add(1)
+
+	return nil, errors.New("connection refused")
+}
+
+func TestRetryTransportErrors(t *testing.T) {
+	tests := []struct {
+		method       string
+		wantAttempts int32
+	}{
+		{http.MethodGet, 3},
+		{http.MethodPost, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			ft := &failingTransport{}
+			c, err := New("http://gateway.invalid", fastRetry, WithHTTPClient(&http.Client{Transport: ft}))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = c.do(context.Background(), tt.method, "/x", nil, nil)
+			if err == nil {
+				t.Fatal("want an error")
+			}
+
+			if n := ft.calls.Load(); n != tt.wantAttempts {
+				t.Errorf("sent %d times, want %d", n, tt.wantAttempts)
+			}
+		})
+	}
+}
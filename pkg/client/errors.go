@@ -0,0 +1,68 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"ticket/pkg/wire"
+	"time"
+)
+
+const maxErrorBody = 64 << 10
+
+// Error is an error response. ErrorResponse holds the envelope's data: branch
+// on Code, e.g. wire.CodeInvalidLayout, rather than on Message.
+type Error struct {
+	Status  int
+	Message string
+	wire.ErrorResponse
+
+	retryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("%s: %s", e.Code, e.Message)
+	if len(e.Fields) > 0 {
+		fields := make([]string, 0, len(e.Fields))
+		for _, f := range e.Fields {
+			fields = append(fields, f.Message)
+		}
+
+		msg += " (" + strings.Join(fields, "; ") + ")"
+	}
+
+	return msg
+}
+
+// newError reads the envelope of an error response. Responses that are not
+// one, e.g. from a proxy in front of the gateway, keep their body as the
+// message and get the code of their status.
+func newError(res *http.Response) *Error {
+	e := &Error{Status: res.StatusCode}
+
+	body, _ := io.ReadAll(io.LimitReader(res.Body, maxErrorBody))
+
+	var env wire.GenericResponse[wire.ErrorResponse]
+	if json.Unmarshal(body, &env) == nil && env.Error {
+		e.Message, e.ErrorResponse = env.Message, env.Data
+	} else {
+		e.Message = strings.TrimSpace(string(body))
+	}
+
+	if e.Message == "" {
+		e.Message = http.StatusText(res.StatusCode)
+	}
+
+	if e.Code == "" {
+		e.Code = wire.CodeFor(res.StatusCode)
+	}
+
+	if secs, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil && secs > 0 {
+		e.retryAfter = time.Duration(secs) * time.Second
+	}
+
+	return e
+}
@@ -0,0 +1,69 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"ticket/pkg/db"
+)
+
+type sortStatusesRequest struct {
+	StatusIDs []uint32 `json:"status_ids"`
+}
+
+type reorderRequest struct {
+	Statuses []reorderStatus `json:"statuses"`
+}
+
+type reorderStatus struct {
+	ID        uint32   `json:"id"`
+	TicketIDs []uint64 `json:"ticket_ids"`
+}
+
+func (c *Client) statusPath(boardID, statusID uint32) string {
+	return fmt.Sprintf("%s/statuses/%d", c.boardPath(boardID), statusID)
+}
+
+func (c *Client) CreateStatus(ctx context.Context, boardID uint32, title string) (db.StatusWithRelated, error) {
+	var status db.StatusWithRelated
+	err := c.do(ctx, http.MethodPost, c.boardPath(boardID)+"/statuses", titleRequest{Title: title}, &status)
+
+	return status, err
+}
+
+func (c *Client) RenameStatus(ctx context.Context, boardID, statusID uint32, title string) (db.StatusWithRelated, error) {
+	var status db.StatusWithRelated
+	err := c.do(ctx, http.MethodPatch, c.statusPath(boardID, statusID), titleRequest{Title: title}, &status)
+
+	return status, err
+}
+
+// SortStatuses puts the statuses of a board in the order of statusIDs, which
+// must list all of them.
+func (c *Client) SortStatuses(ctx context.Context, boardID uint32, statusIDs ...uint32) ([]db.StatusWithRelated, error) {
+	var statuses []db.StatusWithRelated
+	err := c.do(ctx, http.MethodPut, c.boardPath(boardID)+"/statuses/sort-orders", sortStatusesRequest{StatusIDs: statusIDs}, &statuses)
+
+	return statuses, err
+}
+
+// ReorderTickets moves tickets between the statuses of orders and sorts them,
+// in one transaction. Each status must list every ticket it ends up with, and
+// a ticket that leaves a status must be listed in the one it moves to; the API
+// answers wire.CodeInvalidLayout otherwise. It returns the listed statuses.
+func (c *Client) ReorderTickets(ctx context.Context, boardID uint32, orders ...db.StatusOrder) ([]db.StatusWithRelated, error) {
+	body := reorderRequest{Statuses: make([]reorderStatus, 0, len(orders))}
+	for _, o := range orders {
+		ids := o.TicketIDs
+		if ids == nil {
+			ids = []uint64{}
+		}
+
+		body.Statuses = append(body.Statuses, reorderStatus{ID: o.StatusID, TicketIDs: ids})
+	}
+
+	var statuses []db.StatusWithRelated
+	err := c.do(ctx, http.MethodPut, c.boardPath(boardID)+"/statuses/tickets/bulk-reorder", body, &statuses)
+
+	return statuses, err
+}
@@ -0,0 +1,113 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"ticket/pkg/db"
+)
+
+// NewTicket is a ticket to create. Every field is required.
+type NewTicket struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Contact     string `json:"contact"`
+}
+
+// TicketUpdate changes the fields that are set. Tickets move between statuses
+// with MoveTicket or ReorderTickets.
+type TicketUpdate struct {
+	Title       *string `json:"title,omitempty"`
+	Description *string `json:"description,omitempty"`
+	Contact     *string `json:"contact,omitempty"`
+}
+
+type sortTicketsRequest struct {
+	Tickets []ticketRef `json:"tickets"`
+}
+
+type ticketRef struct {
+	ID uint64 `json:"id"`
+}
+
+// CreateTicket adds a ticket at the end of a status.
+func (c *Client) CreateTicket(ctx context.Context, boardID, statusID uint32, t NewTicket) (db.Ticket, error) {
+	var ticket db.Ticket
+	err := c.do(ctx, http.MethodPost, c.statusPath(boardID, statusID)+"/tickets", t, &ticket)
+
+	return ticket, err
+}
+
+// UpdateTicket changes a ticket in statusID, the status it is in.
+func (c *Client) UpdateTicket(ctx context.Context, boardID, statusID uint32, ticketID uint64, u TicketUpdate) (db.Ticket, error) {
+	var ticket db.Ticket
+	path := fmt.Sprintf("%s/tickets/%d", c.statusPath(boardID, statusID), ticketID)
+	err := c.do(ctx, http.MethodPatch, path, u, &ticket)
+
+	return ticket, err
+}
+
+// SortTickets puts the tickets of a status in the order of ticketIDs, which
+// must list all of them.
+func (c *Client) SortTickets(ctx context.Context, boardID, statusID uint32, ticketIDs ...uint64) ([]db.Ticket, error) {
+	body := sortTicketsRequest{Tickets: make([]ticketRef, 0, len(ticketIDs))}
+	for _, id := range ticketIDs {
+		body.Tickets = append(body.Tickets, ticketRef{ID: id})
+	}
+
+	var tickets []db.Ticket
+	err := c.do(ctx, http.MethodPut, c.statusPath(boardID, statusID)+"/tickets/sort-orders", body, &tickets)
+
+	return tickets, err
+}
+
+// MoveTicket moves a ticket to position, counted from 0, in status toStatusID
+// of the same board. A position past the end, or a negative one, appends it.
+// It reads the board to build the new layout; if the board changes in
+// between, the API rejects the layout with wire.CodeInvalidLayout and the
+// move can be tried again.
+func (c *Client) MoveTicket(ctx context.Context, boardID uint32, ticketID uint64, toStatusID uint32, position int) ([]db.StatusWithRelated, error) {
+	board, err := c.Board(ctx, boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	orders := make(map[uint32][]uint64, len(board.Statuses))
+	var from uint32
+	var found, hasTarget bool
+	for _, s := range board.Statuses {
+		ids := make([]uint64, 0, len(s.Tickets))
+		for _, t := range s.Tickets {
+			if t.ID == ticketID {
+				from, found = s.ID, true
+				continue
+			}
+
+			ids = append(ids, t.ID)
+		}
+
+		orders[s.ID] = ids
+		hasTarget = hasTarget || s.ID == toStatusID
+	}
+
+	if !found {
+		return nil, fmt.Errorf("client: ticket %d is not on board %d", ticketID, boardID)
+	}
+
+	if !hasTarget {
+		return nil, fmt.Errorf("client: status %d is not on board %d", toStatusID, boardID)
+	}
+
+	to := orders[toStatusID]
+	if position < 0 || position > len(to) {
+		position = len(to)
+	}
+	orders[toStatusID] = append(to[:position:position], append([]uint64{ticketID}, to[position:]...)...)
+
+	layout := []db.StatusOrder{{StatusID: toStatusID, TicketIDs: orders[toStatusID]}}
+	if from != toStatusID {
+		layout = append(layout, db.StatusOrder{StatusID: from, TicketIDs: orders[from]})
+	}
+
+	return c.ReorderTickets(ctx, boardID, layout...)
+}
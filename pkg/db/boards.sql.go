@@ -7,6 +7,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 
 	null "github.com/guregu/null/v5"
 )
@@ -27,7 +28,7 @@ func (q *Queries) CountBoardByUserID(ctx context.Context, userID uint64) (int64,
 	return count, err
 }
 
-const createBoard = `-- name: CreateBoard :exec
+const createBoard = `-- name: CreateBoard :execresult
 INSERT INTO
   boards (user_id, title, sort_order, created_at)
 VALUES
@@ -40,9 +41,8 @@ type CreateBoardParams struct {
 	SortOrder uint32      `db:"sort_order" json:"sort_order"`
 }
 
-func (q *Queries) CreateBoard(ctx context.Context, arg CreateBoardParams) error {
-	_, err := q.db.ExecContext(ctx, createBoard, arg.UserID, arg.Title, arg.SortOrder)
-	return err
+func (q *Queries) CreateBoard(ctx context.Context, arg CreateBoardParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, createBoard, arg.UserID, arg.Title, arg.SortOrder)
 }
 
 const deleteBoard = `-- name: DeleteBoard :exec
@@ -57,6 +57,18 @@ func (q *Queries) DeleteBoard(ctx context.Context, id uint32) error {
 	return err
 }
 
+const deleteBoardsByUserID = `-- name: DeleteBoardsByUserID :exec
+DELETE FROM
+  boards
+WHERE
+  user_id = ?
+`
+
+func (q *Queries) DeleteBoardsByUserID(ctx context.Context, userID uint64) error {
+	_, err := q.db.ExecContext(ctx, deleteBoardsByUserID, userID)
+	return err
+}
+
 const getBoard = `-- name: GetBoard :one
 SELECT
   id, user_id, title, sort_order, created_at, updated_at
@@ -125,52 +137,6 @@ func (q *Queries) GetBoardsByUserID(ctx context.Context, userID uint64) ([]Board
 	return items, nil
 }
 
-const getLastInsertBoard = `-- name: GetLastInsertBoard :one
-SELECT
-  id, user_id, title, sort_order, created_at, updated_at
-FROM
-  boards
-WHERE
-  id = (
-    SELECT
-      LAST_INSERT_ID()
-    FROM
-      boards AS b
-    LIMIT
-      1
-  )
-`
-
-func (q *Queries) GetLastInsertBoard(ctx context.Context) (Board, error) {
-	row := q.db.QueryRowContext(ctx, getLastInsertBoard)
-	var i Board
-	err := row.Scan(
-		&i.ID,
-		&i.UserID,
-		&i.Title,
-		&i.SortOrder,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-	)
-	return i, err
-}
-
-const getLastInsertBoardID = `-- name: GetLastInsertBoardID :one
-SELECT
-  LAST_INSERT_ID()
-FROM
-  boards
-LIMIT
-  1
-`
-
-func (q *Queries) GetLastInsertBoardID(ctx context.Context) (int64, error) {
-	row := q.db.QueryRowContext(ctx, getLastInsertBoardID)
-	var last_insert_id int64
-	err := row.Scan(&last_insert_id)
-	return last_insert_id, err
-}
-
 const updateBoard = `-- name: UpdateBoard :exec
 UPDATE
   boards
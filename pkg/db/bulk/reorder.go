@@ -0,0 +1,44 @@
+// Package bulk builds the statements sqlc cannot generate because they expand
+// a slice, for both the MySQL queries in pkg/db and the SQLite ones in
+// pkg/db/sqlite. It lives outside the directories sqlc writes to.
+package bulk
+
+import "strings"
+
+// TicketPosition places a ticket in a status at a sort order.
+type TicketPosition struct {
+	ID        uint64 `json:"id"`
+	StatusID  uint32 `json:"status_id"`
+	SortOrder uint32 `json:"sort_order"`
+}
+
+// ReorderTickets moves every ticket in positions in one UPDATE, setting
+// updated_at to now, an SQL expression such as NOW(), on the tickets that
+// change status. updated_at is assigned first, since MySQL evaluates
+// single-table assignments left to right and it has to compare the old
+// status_id; SQLite evaluates every assignment against the old row anyway.
+func ReorderTickets(positions []TicketPosition, now string) (string, []interface{}) {
+	var statusCase, sortCase strings.Builder
+	var statusArgs, sortArgs, idArgs []interface{}
+	for _, p := range positions {
+		statusCase.WriteString(" WHEN ? THEN ?")
+		statusArgs = append(statusArgs, p.ID, p.StatusID)
+		sortCase.WriteString(" WHEN ? THEN ?")
+		sortArgs = append(sortArgs, p.ID, p.SortOrder)
+		idArgs = append(idArgs, p.ID)
+	}
+
+	status := "CASE id" + statusCase.String() + " END"
+	query := "UPDATE tickets SET" +
+		" updated_at = CASE WHEN status_id <> " + status + " THEN " + now + " ELSE updated_at END," +
+		" status_id = " + status + "," +
+		" sort_order = CASE id" + sortCase.String() + " END" +
+		" WHERE id IN (" + strings.Repeat(",?", len(positions))[1:] + ")"
+
+	args := append([]interface{}{}, statusArgs...)
+	args = append(args, statusArgs...)
+	args = append(args, sortArgs...)
+	args = append(args, idArgs...)
+
+	return query, args
+}
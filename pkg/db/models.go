@@ -38,11 +38,24 @@ type Ticket struct {
 }
 
 type User struct {
+	ID                    uint64      `db:"id" json:"id"`
+	Name                  null.String `db:"name" json:"name"`
+	Lastname              null.String `db:"lastname" json:"lastname"`
+	Email                 null.String `db:"email" json:"email"`
+	Password              null.String `db:"password" json:"password"`
+	CreatedAt             null.Time   `db:"created_at" json:"created_at"`
+	UpdatedAt             null.Time   `db:"updated_at" json:"updated_at"`
+	IsAdmin               bool        `db:"is_admin" json:"is_admin"`
+	DisabledAt            null.Time   `db:"disabled_at" json:"disabled_at"`
+	PasswordResetRequired bool        `db:"password_reset_required" json:"password_reset_required"`
+}
+
+type UserIdentity struct {
 	ID        uint64      `db:"id" json:"id"`
-	Name      null.String `db:"name" json:"name"`
-	Lastname  null.String `db:"lastname" json:"lastname"`
+	UserID    uint64      `db:"user_id" json:"user_id"`
+	Provider  string      `db:"provider" json:"provider"`
+	Subject   string      `db:"subject" json:"subject"`
 	Email     null.String `db:"email" json:"email"`
-	Password  null.String `db:"password" json:"password"`
 	CreatedAt null.Time   `db:"created_at" json:"created_at"`
 	UpdatedAt null.Time   `db:"updated_at" json:"updated_at"`
 }
@@ -0,0 +1,122 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"ticket/pkg/db/bulk"
+)
+
+// ErrLayout wraps every reason PlanReorder rejects a layout.
+var ErrLayout = errors.New("invalid layout")
+
+// TicketPosition places a ticket in a status at a sort order.
+type TicketPosition = bulk.TicketPosition
+
+// StatusOrder is the intended order of the tickets in one status.
+type StatusOrder struct {
+	StatusID  uint32
+	TicketIDs []uint64
+}
+
+// PlanReorder checks a layout and returns the positions to write, numbered
+// from 1 in each status, and how many tickets change status. found are the
+// listed tickets that exist on the board, and current the tickets in the
+// listed statuses today. Each status and ticket may be listed once, every
+// listed ticket must be found, and every current ticket must be listed, or
+// it would keep a sort order that collides with the new ones.
+func PlanReorder(orders []StatusOrder, found []Ticket, current []Ticket) ([]TicketPosition, int, error) {
+	statusOf := make(map[uint64]uint32)
+	listedStatus := make(map[uint32]bool)
+	var positions []TicketPosition
+	for _, o := range orders {
+		if listedStatus[o.StatusID] {
+			return nil, 0, fmt.Errorf("%w: status %d is listed more than once", ErrLayout, o.StatusID)
+		}
+		listedStatus[o.StatusID] = true
+
+		for i, id := range o.TicketIDs {
+			if other, ok := statusOf[id]; ok {
+				if other == o.StatusID {
+					return nil, 0, fmt.Errorf("%w: ticket %d is listed twice in status %d", ErrLayout, id, other)
+				}
+
+				return nil, 0, fmt.Errorf("%w: ticket %d is claimed by statuses %d and %d", ErrLayout, id, other, o.StatusID)
+			}
+			statusOf[id] = o.StatusID
+
+			positions = append(positions, TicketPosition{ID: id, StatusID: o.StatusID, SortOrder: uint32(i + 1)})
+		}
+	}
+
+	foundIDs := make(map[uint64]uint32)
+	for _, t := range found {
+		foundIDs[t.ID] = t.StatusID
+	}
+
+	var unknown []uint64
+	for _, p := range positions {
+		if _, ok := foundIDs[p.ID]; !ok {
+			unknown = append(unknown, p.ID)
+		}
+	}
+
+	if len(unknown) > 0 {
+		return nil, 0, fmt.Errorf("%w: %s not found on this board", ErrLayout, plural("ticket", unknown))
+	}
+
+	for _, o := range orders {
+		var missing []uint64
+		for _, t := range current {
+			if t.StatusID != o.StatusID {
+				continue
+			}
+
+			if _, ok := statusOf[t.ID]; !ok {
+				missing = append(missing, t.ID)
+			}
+		}
+
+		if len(missing) > 0 {
+			return nil, 0, fmt.Errorf("%w: status %d is missing %s", ErrLayout, o.StatusID, plural("ticket", missing))
+		}
+	}
+
+	moved := 0
+	for _, p := range positions {
+		if foundIDs[p.ID] != p.StatusID {
+			moved++
+		}
+	}
+
+	return positions, moved, nil
+}
+
+// plural formats ids as "ticket 1" or "tickets 1, 2".
+func plural(noun string, ids []uint64) string {
+	strs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		strs = append(strs, strconv.FormatUint(id, 10))
+	}
+
+	if len(ids) > 1 {
+		noun += "s"
+	}
+
+	return noun + " " + strings.Join(strs, ", ")
+}
+
+// ReorderTickets moves every ticket to its position in one UPDATE. It is
+// written by hand because sqlc cannot expand a slice into CASE branches.
+func (q *Queries) ReorderTickets(ctx context.Context, positions []TicketPosition) error {
+	if len(positions) == 0 {
+		return nil
+	}
+
+	query, args := bulk.ReorderTickets(positions, "NOW()")
+	_, err := q.db.ExecContext(ctx, query, args...)
+
+	return err
+}
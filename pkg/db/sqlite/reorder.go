@@ -0,0 +1,19 @@
+package sqlite
+
+import (
+	"context"
+	"ticket/pkg/db/bulk"
+)
+
+// ReorderTickets moves every ticket to its position in one UPDATE. It is
+// written by hand because sqlc cannot expand a slice into CASE branches.
+func (q *Queries) ReorderTickets(ctx context.Context, positions []bulk.TicketPosition) error {
+	if len(positions) == 0 {
+		return nil
+	}
+
+	query, args := bulk.ReorderTickets(positions, "CURRENT_TIMESTAMP")
+	_, err := q.db.ExecContext(ctx, query, args...)
+
+	return err
+}
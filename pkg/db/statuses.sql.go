@@ -103,7 +103,7 @@ func (q *Queries) CountStatusWithBoardExclude(ctx context.Context, arg CountStat
 	return count, err
 }
 
-const createStatus = `-- name: CreateStatus :exec
+const createStatus = `-- name: CreateStatus :execresult
 INSERT INTO
   statuses (board_id, title, sort_order, created_at)
 VALUES
@@ -116,9 +116,8 @@ type CreateStatusParams struct {
 	SortOrder uint32      `db:"sort_order" json:"sort_order"`
 }
 
-func (q *Queries) CreateStatus(ctx context.Context, arg CreateStatusParams) error {
-	_, err := q.db.ExecContext(ctx, createStatus, arg.BoardID, arg.Title, arg.SortOrder)
-	return err
+func (q *Queries) CreateStatus(ctx context.Context, arg CreateStatusParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, createStatus, arg.BoardID, arg.Title, arg.SortOrder)
 }
 
 const deleteStatus = `-- name: DeleteStatus :exec
@@ -133,34 +132,18 @@ func (q *Queries) DeleteStatus(ctx context.Context, id uint32) error {
 	return err
 }
 
-const getLastInsertStatus = `-- name: GetLastInsertStatus :one
-SELECT
-  id, board_id, title, sort_order, created_at, updated_at
+const deleteStatusesByUserID = `-- name: DeleteStatusesByUserID :exec
+DELETE statuses
 FROM
   statuses
+  JOIN boards ON statuses.board_id = boards.id
 WHERE
-  id = (
-    SELECT
-      LAST_INSERT_ID()
-    FROM
-      statuses AS s
-    LIMIT
-      1
-  )
+  boards.user_id = ?
 `
 
-func (q *Queries) GetLastInsertStatus(ctx context.Context) (Status, error) {
-	row := q.db.QueryRowContext(ctx, getLastInsertStatus)
-	var i Status
-	err := row.Scan(
-		&i.ID,
-		&i.BoardID,
-		&i.Title,
-		&i.SortOrder,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-	)
-	return i, err
+func (q *Queries) DeleteStatusesByUserID(ctx context.Context, userID uint64) error {
+	_, err := q.db.ExecContext(ctx, deleteStatusesByUserID, userID)
+	return err
 }
 
 const getStatus = `-- name: GetStatus :one
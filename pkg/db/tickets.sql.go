@@ -7,6 +7,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"strings"
 
 	null "github.com/guregu/null/v5"
@@ -66,7 +67,7 @@ func (q *Queries) CountTicketWithBoard(ctx context.Context, arg CountTicketWithB
 	return count, err
 }
 
-const createTicket = `-- name: CreateTicket :exec
+const createTicket = `-- name: CreateTicket :execresult
 INSERT INTO
   tickets (
     status_id,
@@ -88,47 +89,29 @@ type CreateTicketParams struct {
 	SortOrder   uint32      `db:"sort_order" json:"sort_order"`
 }
 
-func (q *Queries) CreateTicket(ctx context.Context, arg CreateTicketParams) error {
-	_, err := q.db.ExecContext(ctx, createTicket,
+func (q *Queries) CreateTicket(ctx context.Context, arg CreateTicketParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, createTicket,
 		arg.StatusID,
 		arg.Title,
 		arg.Description,
 		arg.Contact,
 		arg.SortOrder,
 	)
-	return err
 }
 
-const getLastInsertTicket = `-- name: GetLastInsertTicket :one
-SELECT
-  id, status_id, title, description, contact, sort_order, created_at, updated_at
+const deleteTicketsByUserID = `-- name: DeleteTicketsByUserID :exec
+DELETE tickets
 FROM
   tickets
+  JOIN statuses ON tickets.status_id = statuses.id
+  JOIN boards ON statuses.board_id = boards.id
 WHERE
-  id = (
-    SELECT
-      LAST_INSERT_ID()
-    FROM
-      tickets AS t
-    LIMIT
-      1
-  )
+  boards.user_id = ?
 `
 
-func (q *Queries) GetLastInsertTicket(ctx context.Context) (Ticket, error) {
-	row := q.db.QueryRowContext(ctx, getLastInsertTicket)
-	var i Ticket
-	err := row.Scan(
-		&i.ID,
-		&i.StatusID,
-		&i.Title,
-		&i.Description,
-		&i.Contact,
-		&i.SortOrder,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-	)
-	return i, err
+func (q *Queries) DeleteTicketsByUserID(ctx context.Context, userID uint64) error {
+	_, err := q.db.ExecContext(ctx, deleteTicketsByUserID, userID)
+	return err
 }
 
 const getTicketByID = `-- name: GetTicketByID :one
@@ -7,11 +7,36 @@ package db
 
 import (
 	"context"
+	"database/sql"
 
 	null "github.com/guregu/null/v5"
 )
 
-const createUser = `-- name: CreateUser :exec
+const countSearchUsers = `-- name: CountSearchUsers :one
+SELECT
+  COUNT(*)
+FROM
+  users
+WHERE
+  ? = ''
+  OR email LIKE CONCAT('%', ?, '%')
+  OR name LIKE CONCAT('%', ?, '%')
+  OR lastname LIKE CONCAT('%', ?, '%')
+`
+
+func (q *Queries) CountSearchUsers(ctx context.Context, query string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countSearchUsers,
+		query,
+		query,
+		query,
+		query,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createUser = `-- name: CreateUser :execresult
 INSERT INTO
   users (name, lastname, email, password, created_at)
 VALUES
@@ -25,19 +50,30 @@ type CreateUserParams struct {
 	Password null.String `db:"password" json:"password"`
 }
 
-func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) error {
-	_, err := q.db.ExecContext(ctx, createUser,
+func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, createUser,
 		arg.Name,
 		arg.Lastname,
 		arg.Email,
 		arg.Password,
 	)
+}
+
+const deleteUser = `-- name: DeleteUser :exec
+DELETE FROM
+  users
+WHERE
+  id = ?
+`
+
+func (q *Queries) DeleteUser(ctx context.Context, id uint64) error {
+	_, err := q.db.ExecContext(ctx, deleteUser, id)
 	return err
 }
 
 const findUserByEmail = `-- name: FindUserByEmail :one
 SELECT
-  id, name, lastname, email, password, created_at, updated_at
+  id, name, lastname, email, password, created_at, updated_at, is_admin, disabled_at, password_reset_required
 FROM
   users
 WHERE
@@ -57,13 +93,16 @@ func (q *Queries) FindUserByEmail(ctx context.Context, email null.String) (User,
 		&i.Password,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.IsAdmin,
+		&i.DisabledAt,
+		&i.PasswordResetRequired,
 	)
 	return i, err
 }
 
 const findUserByID = `-- name: FindUserByID :one
 SELECT
-  id, name, lastname, email, password, created_at, updated_at
+  id, name, lastname, email, password, created_at, updated_at, is_admin, disabled_at, password_reset_required
 FROM
   users
 WHERE
@@ -81,28 +120,26 @@ func (q *Queries) FindUserByID(ctx context.Context, id uint64) (User, error) {
 		&i.Password,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.IsAdmin,
+		&i.DisabledAt,
+		&i.PasswordResetRequired,
 	)
 	return i, err
 }
 
-const getLastInsertUser = `-- name: GetLastInsertUser :one
+const getUserByID = `-- name: GetUserByID :one
 SELECT
-  id, name, lastname, email, password, created_at, updated_at
+  id, name, lastname, email, password, created_at, updated_at, is_admin, disabled_at, password_reset_required
 FROM
   users
 WHERE
-  id = (
-    SELECT
-      LAST_INSERT_ID()
-    FROM
-      users AS u
-    LIMIT
-      1
-  )
+  id = ?
+LIMIT
+  1
 `
 
-func (q *Queries) GetLastInsertUser(ctx context.Context) (User, error) {
-	row := q.db.QueryRowContext(ctx, getLastInsertUser)
+func (q *Queries) GetUserByID(ctx context.Context, id uint64) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByID, id)
 	var i User
 	err := row.Scan(
 		&i.ID,
@@ -112,50 +149,74 @@ func (q *Queries) GetLastInsertUser(ctx context.Context) (User, error) {
 		&i.Password,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.IsAdmin,
+		&i.DisabledAt,
+		&i.PasswordResetRequired,
 	)
 	return i, err
 }
 
-const getLastInsertUserID = `-- name: GetLastInsertUserID :one
+const searchUsers = `-- name: SearchUsers :many
 SELECT
-  LAST_INSERT_ID()
+  id, name, lastname, email, password, created_at, updated_at, is_admin, disabled_at, password_reset_required
 FROM
   users
+WHERE
+  ? = ''
+  OR email LIKE CONCAT('%', ?, '%')
+  OR name LIKE CONCAT('%', ?, '%')
+  OR lastname LIKE CONCAT('%', ?, '%')
+ORDER BY
+  id ASC
 LIMIT
-  1
+  ? OFFSET ?
 `
 
-func (q *Queries) GetLastInsertUserID(ctx context.Context) (int64, error) {
-	row := q.db.QueryRowContext(ctx, getLastInsertUserID)
-	var last_insert_id int64
-	err := row.Scan(&last_insert_id)
-	return last_insert_id, err
+type SearchUsersParams struct {
+	Query  string `db:"query" json:"query"`
+	Limit  int32  `db:"limit" json:"limit"`
+	Offset int32  `db:"offset" json:"offset"`
 }
 
-const getUserByID = `-- name: GetUserByID :one
-SELECT
-  id, name, lastname, email, password, created_at, updated_at
-FROM
-  users
-WHERE
-  id = ?
-LIMIT
-  1
-`
-
-func (q *Queries) GetUserByID(ctx context.Context, id uint64) (User, error) {
-	row := q.db.QueryRowContext(ctx, getUserByID, id)
-	var i User
-	err := row.Scan(
-		&i.ID,
-		&i.Name,
-		&i.Lastname,
-		&i.Email,
-		&i.Password,
-		&i.CreatedAt,
-		&i.UpdatedAt,
+func (q *Queries) SearchUsers(ctx context.Context, arg SearchUsersParams) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, searchUsers,
+		arg.Query,
+		arg.Query,
+		arg.Query,
+		arg.Query,
+		arg.Limit,
+		arg.Offset,
 	)
-	return i, err
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []User{}
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Lastname,
+			&i.Email,
+			&i.Password,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.IsAdmin,
+			&i.DisabledAt,
+			&i.PasswordResetRequired,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
 const updateUser = `-- name: UpdateUser :exec
@@ -189,3 +250,45 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) error {
 	)
 	return err
 }
+
+const updateUserDisabledAt = `-- name: UpdateUserDisabledAt :exec
+UPDATE
+  users
+SET
+  disabled_at = ?,
+  updated_at = NOW()
+WHERE
+  id = ?
+`
+
+type UpdateUserDisabledAtParams struct {
+	DisabledAt null.Time `db:"disabled_at" json:"disabled_at"`
+	ID         uint64    `db:"id" json:"id"`
+}
+
+func (q *Queries) UpdateUserDisabledAt(ctx context.Context, arg UpdateUserDisabledAtParams) error {
+	_, err := q.db.ExecContext(ctx, updateUserDisabledAt, arg.DisabledAt, arg.ID)
+	return err
+}
+
+const updateUserPassword = `-- name: UpdateUserPassword :exec
+UPDATE
+  users
+SET
+  password = ?,
+  password_reset_required = ?,
+  updated_at = NOW()
+WHERE
+  id = ?
+`
+
+type UpdateUserPasswordParams struct {
+	Password              null.String `db:"password" json:"password"`
+	PasswordResetRequired bool        `db:"password_reset_required" json:"password_reset_required"`
+	ID                    uint64      `db:"id" json:"id"`
+}
+
+func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) error {
+	_, err := q.db.ExecContext(ctx, updateUserPassword, arg.Password, arg.PasswordResetRequired, arg.ID)
+	return err
+}
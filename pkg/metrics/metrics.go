@@ -0,0 +1,36 @@
+// Package metrics holds the business counters shared by the services. HTTP,
+// database and gateway metrics live next to the code they measure.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	TicketsCreated = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "tickets_created_total",
+		Help: "Tickets created.",
+	})
+
+	TicketsMoved = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "tickets_moved_total",
+		Help: "Tickets moved from one status to another.",
+	})
+
+	SignIns = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "sign_ins_total",
+		Help: "Sign-in attempts, by method and result.",
+	}, []string{"method", "result"})
+)
+
+// RecordSignIn counts a sign-in attempt. Requests rejected before the
+// credentials were checked, e.g. by validation, are not counted.
+func RecordSignIn(method string, succeeded bool) {
+	result := "failed"
+	if succeeded {
+		result = "succeeded"
+	}
+
+	SignIns.WithLabelValues(method, result).Inc()
+}
@@ -0,0 +1,366 @@
+// Package migrate applies numbered SQL migrations and records them in the
+// schema_migrations table.
+//
+// Migrations are pairs of files named <version>_<name>.up.sql and
+// <version>_<name>.down.sql. Statements are split on semicolons that end a
+// line, since the MySQL driver runs one statement per Exec. MySQL commits DDL
+// implicitly, so a migration that fails halfway must be repaired by hand. On
+// SQLite a run happens in one transaction and rolls back as a whole.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	lockName    = "schema_migrations"
+	lockTimeout = 60
+)
+
+var (
+	ErrNoChange = errors.New("no change")
+	fileName    = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+)
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+type Migrator struct {
+	db         *sql.DB
+	driver     string
+	migrations []Migration
+}
+
+// New reads every migration in dir of fsys. driver is the database/sql driver
+// name, "mysql" or "sqlite", and decides how concurrent runs are serialized.
+func New(db *sql.DB, driver string, fsys fs.FS, dir string) (*Migrator, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, e := range entries {
+		m := fileName.FindStringSubmatch(e.Name())
+		if e.IsDir() || m == nil {
+			continue
+		}
+
+		version, err := strconv.ParseInt(m[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", e.Name(), err)
+		}
+
+		b, err := fs.ReadFile(fsys, path.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		mg, ok := byVersion[version]
+		if !ok {
+			mg = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mg
+		} else if mg.Name != m[2] {
+			return nil, fmt.Errorf("version %d is used by both %s and %s", version, mg.Name, m[2])
+		}
+
+		if m[3] == "up" {
+			mg.Up = string(b)
+		} else {
+			mg.Down = string(b)
+		}
+	}
+
+	mr := &Migrator{db: db, driver: driver}
+	for _, mg := range byVersion {
+		if mg.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", mg.Version, mg.Name)
+		}
+
+		mr.migrations = append(mr.migrations, *mg)
+	}
+
+	sort.Slice(mr.migrations, func(i, j int) bool {
+		return mr.migrations[i].Version < mr.migrations[j].Version
+	})
+
+	return mr, nil
+}
+
+// Latest is the highest known version, or 0 without migrations.
+func (mr *Migrator) Latest() int64 {
+	if len(mr.migrations) == 0 {
+		return 0
+	}
+
+	return mr.migrations[len(mr.migrations)-1].Version
+}
+
+// Up applies every pending migration.
+func (mr *Migrator) Up(ctx context.Context) error {
+	return mr.To(ctx, mr.Latest())
+}
+
+// Down reverts the most recently applied migration.
+func (mr *Migrator) Down(ctx context.Context) error {
+	return mr.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := mr.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(mr.migrations) - 1; i >= 0; i-- {
+			if _, ok := applied[mr.migrations[i].Version]; ok {
+				return mr.revert(ctx, conn, mr.migrations[i])
+			}
+		}
+
+		return ErrNoChange
+	})
+}
+
+// To applies or reverts migrations until version is the latest applied one.
+// Version 0 reverts everything.
+func (mr *Migrator) To(ctx context.Context, version int64) error {
+	if version != 0 && !mr.known(version) {
+		return fmt.Errorf("unknown migration version %d", version)
+	}
+
+	return mr.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := mr.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		changed := false
+		for i := len(mr.migrations) - 1; i >= 0; i-- {
+			mg := mr.migrations[i]
+			if _, ok := applied[mg.Version]; ok && mg.Version > version {
+				err = mr.revert(ctx, conn, mg)
+				if err != nil {
+					return err
+				}
+				changed = true
+			}
+		}
+
+		for _, mg := range mr.migrations {
+			if _, ok := applied[mg.Version]; !ok && mg.Version <= version {
+				err = mr.apply(ctx, conn, mg)
+				if err != nil {
+					return err
+				}
+				changed = true
+			}
+		}
+
+		if !changed {
+			return ErrNoChange
+		}
+
+		return nil
+	})
+}
+
+func (mr *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var statuses []Status
+	err := mr.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := mr.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, mg := range mr.migrations {
+			s := Status{Migration: mg}
+			if at, ok := applied[mg.Version]; ok {
+				s.AppliedAt = &at
+			}
+
+			statuses = append(statuses, s)
+		}
+
+		return nil
+	})
+
+	return statuses, err
+}
+
+func (mr *Migrator) known(version int64) bool {
+	for _, mg := range mr.migrations {
+		if mg.Version == version {
+			return true
+		}
+	}
+
+	return false
+}
+
+// withLock serializes runs across processes, e.g. two services migrating on
+// startup. MySQL uses a named lock held on a single connection, SQLite a
+// write transaction.
+func (mr *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := mr.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	unlock, err := mr.lock(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+  version BIGINT NOT NULL PRIMARY KEY,
+  name VARCHAR(255) NOT NULL,
+  applied_at DATETIME NOT NULL
+)`)
+	if err == nil {
+		err = fn(conn)
+	}
+
+	return unlock(err)
+}
+
+// lock returns a function that releases the lock and passes the run's error
+// through.
+func (mr *Migrator) lock(ctx context.Context, conn *sql.Conn) (func(error) error, error) {
+	if mr.driver == "sqlite" {
+		_, err := conn.ExecContext(ctx, "BEGIN IMMEDIATE")
+		if err != nil {
+			return nil, err
+		}
+
+		return func(err error) error {
+			if err != nil {
+				conn.ExecContext(context.Background(), "ROLLBACK")
+
+				return err
+			}
+
+			_, err = conn.ExecContext(ctx, "COMMIT")
+
+			return err
+		}, nil
+	}
+
+	var locked sql.NullInt64
+	err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", lockName, lockTimeout).Scan(&locked)
+	if err != nil {
+		return nil, err
+	}
+
+	if locked.Int64 != 1 {
+		return nil, fmt.Errorf("timed out waiting for the %s lock", lockName)
+	}
+
+	return func(err error) error {
+		conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", lockName)
+
+		return err
+	}, nil
+}
+
+func (mr *Migrator) applied(ctx context.Context, conn *sql.Conn) (map[int64]time.Time, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int64]time.Time{}
+	for rows.Next() {
+		var version int64
+		var at time.Time
+		err = rows.Scan(&version, &at)
+		if err != nil {
+			return nil, err
+		}
+
+		applied[version] = at
+	}
+
+	return applied, rows.Err()
+}
+
+func (mr *Migrator) apply(ctx context.Context, conn *sql.Conn, mg Migration) error {
+	err := execAll(ctx, conn, mg.Up)
+	if err != nil {
+		return fmt.Errorf("migration %d_%s up: %w", mg.Version, mg.Name, err)
+	}
+
+	_, err = conn.ExecContext(ctx, "INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)",
+		mg.Version, mg.Name, time.Now().UTC())
+
+	return err
+}
+
+func (mr *Migrator) revert(ctx context.Context, conn *sql.Conn, mg Migration) error {
+	if mg.Down == "" {
+		return fmt.Errorf("migration %d_%s has no down file", mg.Version, mg.Name)
+	}
+
+	err := execAll(ctx, conn, mg.Down)
+	if err != nil {
+		return fmt.Errorf("migration %d_%s down: %w", mg.Version, mg.Name, err)
+	}
+
+	_, err = conn.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = ?", mg.Version)
+
+	return err
+}
+
+func execAll(ctx context.Context, conn *sql.Conn, script string) error {
+	for _, stmt := range splitStatements(script) {
+		_, err := conn.ExecContext(ctx, stmt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func splitStatements(script string) []string {
+	var stmts []string
+	var b strings.Builder
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+
+		b.WriteString(line)
+		b.WriteString("\n")
+
+		if strings.HasSuffix(trimmed, ";") {
+			stmts = append(stmts, strings.TrimSpace(b.String()))
+			b.Reset()
+		}
+	}
+
+	if rest := strings.TrimSpace(b.String()); rest != "" {
+		stmts = append(stmts, rest)
+	}
+
+	return stmts
+}
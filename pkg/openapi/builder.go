@@ -0,0 +1,186 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const mimeJSON = "application/json"
+
+// Group adds operations under a common prefix, with the tags and security of
+// the group. Groups nest the way echo groups do, so a spec can follow its
+// router line by line.
+type Group struct {
+	doc     *Document
+	prefix  string
+	tags    []string
+	secured bool
+}
+
+// Group returns a group under g's prefix that starts with g's settings.
+func (g *Group) Group(prefix string) *Group {
+	c := *g
+	c.prefix += prefix
+
+	return &c
+}
+
+// Tag sets the tags of the group's operations, replacing those of its parent.
+func (g *Group) Tag(tags ...string) *Group {
+	g.tags = tags
+
+	return g
+}
+
+// Secured marks the group's operations as requiring a bearer token.
+func (g *Group) Secured() *Group {
+	g.secured = true
+
+	return g
+}
+
+func (g *Group) GET(path, id string) *Operation {
+	return g.Add(http.MethodGet, path, id)
+}
+
+func (g *Group) POST(path, id string) *Operation {
+	return g.Add(http.MethodPost, path, id)
+}
+
+func (g *Group) PUT(path, id string) *Operation {
+	return g.Add(http.MethodPut, path, id)
+}
+
+func (g *Group) PATCH(path, id string) *Operation {
+	return g.Add(http.MethodPatch, path, id)
+}
+
+func (g *Group) DELETE(path, id string) *Operation {
+	return g.Add(http.MethodDelete, path, id)
+}
+
+// Add adds the operation id for method on path, written the way echo writes
+// it, e.g. /boards/:board_id. Its path parameters are added with it.
+func (g *Group) Add(method, path, id string) *Operation {
+	d := g.doc
+	op := &Operation{
+		OperationID: id,
+		Tags:        g.tags,
+		Responses:   map[string]*Response{},
+		doc:         d,
+	}
+
+	if g.secured {
+		op.Security = []map[string][]string{{bearerScheme: {}}}
+	}
+
+	if d.errors != nil {
+		op.Responses["default"] = &Response{
+			Description: "Error",
+			Content:     map[string]MediaType{mimeJSON: {Schema: d.errors}},
+		}
+	}
+
+	p := Path(g.prefix + path)
+	for _, name := range pathParams(p) {
+		s, ok := d.params[name]
+		if !ok {
+			s = &Schema{Type: "string"}
+		}
+
+		op.Parameters = append(op.Parameters, Parameter{Name: name, In: "path", Required: true, Schema: s})
+	}
+
+	item, ok := d.Paths[p]
+	if !ok {
+		item = PathItem{}
+		d.Paths[p] = item
+	}
+	item[strings.ToLower(method)] = op
+
+	return op
+}
+
+func (o *Operation) Summarize(summary string) *Operation {
+	o.Summary = summary
+
+	return o
+}
+
+// Body sets the JSON request body to the schema of v.
+func (o *Operation) Body(v any) *Operation {
+	o.RequestBody = &RequestBody{
+		Required: true,
+		Content:  map[string]MediaType{mimeJSON: {Schema: o.doc.schema(reflect.TypeOf(v), request)}},
+	}
+
+	return o
+}
+
+// Query adds a query parameter for every field of the struct v that has a
+// query tag.
+func (o *Operation) Query(v any) *Operation {
+	t := reflect.TypeOf(v)
+	for _, f := range reflect.VisibleFields(t) {
+		name := f.Tag.Get("query")
+		if name == "" || name == "-" || !f.IsExported() {
+			continue
+		}
+
+		s := o.doc.schema(f.Type, request)
+		required := constrain(s, f.Type, f.Tag.Get("validate"))
+		o.Parameters = append(o.Parameters, Parameter{Name: name, In: "query", Required: required, Schema: s})
+	}
+
+	return o
+}
+
+// Returns adds the response for status with a JSON body of the schema of v,
+// or without a body when v is nil.
+func (o *Operation) Returns(status int, v any) *Operation {
+	res := &Response{Description: http.StatusText(status)}
+	if v != nil {
+		res.Content = map[string]MediaType{mimeJSON: {Schema: o.doc.schema(reflect.TypeOf(v), response)}}
+	}
+
+	o.Responses[strconv.Itoa(status)] = res
+
+	return o
+}
+
+// ReturnsText adds the response for status with a text body of contentType,
+// e.g. text/html.
+func (o *Operation) ReturnsText(status int, contentType string) *Operation {
+	o.Responses[strconv.Itoa(status)] = &Response{
+		Description: http.StatusText(status),
+		Content:     map[string]MediaType{contentType: {Schema: &Schema{Type: "string"}}},
+	}
+
+	return o
+}
+
+// Path turns an echo path into an OpenAPI one, e.g. /boards/:board_id into
+// /boards/{board_id}.
+func Path(echoPath string) string {
+	segments := strings.Split(echoPath, "/")
+	for i, s := range segments {
+		if name, ok := strings.CutPrefix(s, ":"); ok {
+			segments[i] = "{" + name + "}"
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
+
+func pathParams(path string) []string {
+	var names []string
+	for _, s := range strings.Split(path, "/") {
+		if strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}") {
+			names = append(names, s[1:len(s)-1])
+		}
+	}
+
+	return names
+}
@@ -0,0 +1,42 @@
+package openapi
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Missing returns the routes d has no operation for, as "GET /boards/{board_id}".
+// The catch-all routes echo adds for group middleware are left out.
+func (d *Document) Missing(routes []*echo.Route) []string {
+	var missing []string
+	for _, r := range routes {
+		if r.Method == echo.RouteNotFound {
+			continue
+		}
+
+		p := Path(r.Path)
+		if _, ok := d.Paths[p][strings.ToLower(r.Method)]; !ok {
+			missing = append(missing, r.Method+" "+p)
+		}
+	}
+
+	sort.Strings(missing)
+
+	return missing
+}
+
+// Documents reports whether d lists status as a response of the operation
+// for method and echoPath, e.g. PUT /boards/:board_id.
+func (d *Document) Documents(method, echoPath string, status int) bool {
+	op, ok := d.Paths[Path(echoPath)][strings.ToLower(method)]
+	if !ok {
+		return false
+	}
+
+	_, ok = op.Responses[strconv.Itoa(status)]
+
+	return ok
+}
@@ -0,0 +1,34 @@
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// docsPage renders the openapi.json next to it. It is a single file with no
+// external assets, so it works offline and behind the gateway.
+//
+//go:embed docs.html
+var docsPage []byte
+
+// Docs serves the docs page.
+func Docs(c echo.Context) error {
+	return c.HTMLBlob(http.StatusOK, docsPage)
+}
+
+// Handler serves d as JSON. d is encoded once, so operations added later are
+// not served.
+func (d *Document) Handler() echo.HandlerFunc {
+	b, err := json.Marshal(d)
+
+	return func(c echo.Context) error {
+		if err != nil {
+			return err
+		}
+
+		return c.JSONBlob(http.StatusOK, b)
+	}
+}
@@ -0,0 +1,175 @@
+// Package openapi builds OpenAPI 3 documents in Go, next to the routers they
+// describe, so the request and response types the handlers use are the
+// schemas clients see.
+package openapi
+
+import (
+	"reflect"
+)
+
+// Version is the OpenAPI version of the documents.
+const Version = "3.0.3"
+
+// TagOperations tags the endpoints every service has for its orchestrator,
+// like /health. The gateway leaves them out of its merged document.
+const TagOperations = "operations"
+
+const bearerScheme = "bearer"
+
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+
+	params map[string]*Schema
+	errors *Schema
+	types  map[string]reflect.Type
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// PathItem maps lower-case HTTP methods to their operations.
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+
+	doc *Document
+}
+
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas,omitempty"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *uint64            `json:"minLength,omitempty"`
+	MaxLength            *uint64            `json:"maxLength,omitempty"`
+	MinItems             *uint64            `json:"minItems,omitempty"`
+	MaxItems             *uint64            `json:"maxItems,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// New starts an empty document. Secured operations use a JWT bearer token.
+func New(title, version string) *Document {
+	return &Document{
+		OpenAPI: Version,
+		Info:    Info{Title: title, Version: version},
+		Paths:   map[string]PathItem{},
+		Components: Components{
+			Schemas: map[string]*Schema{},
+			SecuritySchemes: map[string]SecurityScheme{
+				bearerScheme: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+		},
+		params: map[string]*Schema{},
+		types:  map[string]reflect.Type{},
+	}
+}
+
+// Errors sets the body of the default response of the operations added after
+// it, the one every operation may fail with.
+func (d *Document) Errors(v any) *Document {
+	d.errors = d.schema(reflect.TypeOf(v), response)
+
+	return d
+}
+
+// PathParam sets the schema of the path parameter name to that of v, e.g.
+// uint32(0) for an id. Parameters without one are strings.
+func (d *Document) PathParam(name string, v any) *Document {
+	d.params[name] = d.schema(reflect.TypeOf(v), request)
+
+	return d
+}
+
+// Group returns a group of operations under prefix, like echo's Group.
+func (d *Document) Group(prefix string) *Group {
+	return &Group{doc: d, prefix: prefix}
+}
+
+// Merge adds the paths and schemas of other to d, with its paths under prefix.
+// Schemas that both documents have are kept as d has them, which holds as
+// long as the services share the Go types behind them. Operations tagged
+// TagOperations are left out.
+func (d *Document) Merge(prefix string, other *Document) {
+	for path, item := range other.Paths {
+		merged := PathItem{}
+		for method, op := range item {
+			if !hasTag(op, TagOperations) {
+				merged[method] = op
+			}
+		}
+
+		if len(merged) > 0 {
+			d.Paths[prefix+path] = merged
+		}
+	}
+
+	for name, s := range other.Components.Schemas {
+		if _, ok := d.Components.Schemas[name]; !ok {
+			d.Components.Schemas[name] = s
+		}
+	}
+
+	for name, s := range other.Components.SecuritySchemes {
+		d.Components.SecuritySchemes[name] = s
+	}
+}
+
+func hasTag(op *Operation, tag string) bool {
+	for _, t := range op.Tags {
+		if t == tag {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,250 @@
+package openapi
+
+import (
+	"encoding/json"
+	"path"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// use tells which fields of a struct are required. Request bodies require
+// the fields validated as required, and responses every field that is not
+// omitempty. A named type gets the use it is first seen with.
+type use int
+
+const (
+	request use = iota
+	response
+)
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+	// typeArgPackage matches the package path of a type argument in the name
+	// of an instantiated generic type.
+	typeArgPackage = regexp.MustCompile(`[^\[\],]*[./]`)
+)
+
+func (d *Document) schema(t reflect.Type, u use) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	if value, ok := nullable(t); ok {
+		s := d.schema(value, u)
+		s.Nullable = true
+
+		return s
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return d.schema(t.Elem(), u)
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer", Format: "int32", Minimum: float(0)}
+	case reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64", Minimum: float(0)}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+
+		return &Schema{Type: "array", Items: d.schema(t.Elem(), u)}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: d.schema(t.Elem(), u)}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return d.structSchema(t, u)
+		}
+
+		return d.ref(t, u)
+	}
+
+	return &Schema{}
+}
+
+// ref registers the named struct t as a component and refers to it. Types of
+// different packages with the same name are told apart by their package.
+func (d *Document) ref(t reflect.Type, u use) *Schema {
+	name := componentName(t)
+	if seen, ok := d.types[name]; ok && seen != t {
+		name = path.Base(t.PkgPath()) + "." + name
+	}
+
+	if _, ok := d.types[name]; !ok {
+		d.types[name] = t
+		d.Components.Schemas[name] = &Schema{}
+		*d.Components.Schemas[name] = *d.structSchema(t, u)
+	}
+
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+func (d *Document) structSchema(t reflect.Type, u use) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() || f.Anonymous {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		fs := d.schema(f.Type, u)
+		required := constrain(fs, f.Type, f.Tag.Get("validate"))
+		if u == response {
+			required = !strings.Contains(","+opts+",", ",omitempty,")
+		}
+
+		s.Properties[name] = fs
+		if required {
+			s.Required = append(s.Required, name)
+		}
+	}
+
+	return s
+}
+
+// constrain adds the validate rules of a field of type t to its schema s and
+// reports whether the field is required. Rules after dive apply to items.
+func constrain(s *Schema, t reflect.Type, rules string) bool {
+	required := false
+	for _, rule := range strings.Split(rules, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		if name == "dive" {
+			if s.Items != nil && t.Kind() == reflect.Slice {
+				_, rest, _ := strings.Cut(rules, "dive")
+				constrain(s.Items, t.Elem(), strings.TrimPrefix(rest, ","))
+			}
+
+			break
+		}
+
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+
+		switch name {
+		case "required":
+			required = true
+		case "email":
+			s.Format = "email"
+		case "url":
+			s.Format = "uri"
+		case "oneof":
+			s.Enum = strings.Fields(param)
+		case "min", "gte":
+			bound(s, t, param, true)
+		case "max", "lte":
+			bound(s, t, param, false)
+		case "len":
+			bound(s, t, param, true)
+			bound(s, t, param, false)
+		}
+	}
+
+	return required
+}
+
+// bound sets the lower or upper bound of s, on its length, items or value
+// depending on t.
+func bound(s *Schema, t reflect.Type, param string, lower bool) {
+	n, err := strconv.ParseFloat(param, 64)
+	if err != nil || s.Ref != "" {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		if lower {
+			s.MinLength = count(n)
+		} else {
+			s.MaxLength = count(n)
+		}
+	case reflect.Slice, reflect.Array, reflect.Map:
+		if lower {
+			s.MinItems = count(n)
+		} else {
+			s.MaxItems = count(n)
+		}
+	default:
+		if lower {
+			s.Minimum = float(n)
+		} else {
+			s.Maximum = float(n)
+		}
+	}
+}
+
+// nullable returns the type of the value of t when t is a nullable wrapper
+// like null.String: a struct with a Valid flag and one value that marshals
+// itself to JSON null or the value.
+func nullable(t reflect.Type) (reflect.Type, bool) {
+	if t.Kind() != reflect.Struct || !(t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType)) {
+		return nil, false
+	}
+
+	valid, ok := t.FieldByName("Valid")
+	if !ok || valid.Type.Kind() != reflect.Bool {
+		return nil, false
+	}
+
+	var value reflect.Type
+	for _, f := range reflect.VisibleFields(t) {
+		if f.Anonymous || !f.IsExported() || f.Name == "Valid" {
+			continue
+		}
+
+		if value != nil {
+			return nil, false
+		}
+		value = f.Type
+	}
+
+	return value, value != nil
+}
+
+// componentName is the name of t, with the package paths of type arguments
+// left out, e.g. GenericResponse_ErrorResponse.
+func componentName(t reflect.Type) string {
+	name := strings.ReplaceAll(t.Name(), "interface {}", "Any")
+	name = typeArgPackage.ReplaceAllString(name, "")
+
+	return strings.NewReplacer("[", "_", "]", "", ",", "_", "*", "").Replace(name)
+}
+
+func float(n float64) *float64 {
+	return &n
+}
+
+func count(n float64) *uint64 {
+	c := uint64(n)
+
+	return &c
+}
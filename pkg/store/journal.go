@@ -0,0 +1,116 @@
+package store
+
+import (
+	"cmp"
+	"slices"
+	"ticket/pkg/db"
+)
+
+// journal keeps each row a Memory transaction changes as it was before the
+// first change, so rolling back restores those rows and nothing else. Writes
+// made outside the transaction survive, as they would in MySQL. A nil
+// journal records nothing.
+type journal struct {
+	users      before[uint64, db.User]
+	identities before[uint64, db.UserIdentity]
+	boards     before[uint32, db.Board]
+	statuses   before[uint32, db.Status]
+	tickets    before[uint64, db.Ticket]
+}
+
+func newJournal() *journal {
+	return &journal{
+		users:      before[uint64, db.User]{},
+		identities: before[uint64, db.UserIdentity]{},
+		boards:     before[uint32, db.Board]{},
+		statuses:   before[uint32, db.Status]{},
+		tickets:    before[uint64, db.Ticket]{},
+	}
+}
+
+func userKey(u db.User) uint64              { return u.ID }
+func identityKey(ui db.UserIdentity) uint64 { return ui.ID }
+func boardKey(b db.Board) uint32            { return b.ID }
+func statusKey(s db.Status) uint32          { return s.ID }
+func ticketKey(t db.Ticket) uint64          { return t.ID }
+
+// The methods below must be called with Memory.mu held, before the row with
+// the given id is inserted, updated or deleted.
+
+func (j *journal) user(t *tables, id uint64) {
+	if j != nil {
+		j.users.save(t.users, id, userKey)
+	}
+}
+
+func (j *journal) identity(t *tables, id uint64) {
+	if j != nil {
+		j.identities.save(t.identities, id, identityKey)
+	}
+}
+
+func (j *journal) board(t *tables, id uint32) {
+	if j != nil {
+		j.boards.save(t.boards, id, boardKey)
+	}
+}
+
+func (j *journal) status(t *tables, id uint32) {
+	if j != nil {
+		j.statuses.save(t.statuses, id, statusKey)
+	}
+}
+
+func (j *journal) ticket(t *tables, id uint64) {
+	if j != nil {
+		j.tickets.save(t.tickets, id, ticketKey)
+	}
+}
+
+// restore rolls the journaled rows of t back. Ids handed out by the
+// transaction are not reused, like AUTO_INCREMENT.
+func (j *journal) restore(t *tables) {
+	t.users = j.users.restore(t.users, userKey)
+	t.identities = j.identities.restore(t.identities, identityKey)
+	t.boards = j.boards.restore(t.boards, boardKey)
+	t.statuses = j.statuses.restore(t.statuses, statusKey)
+	t.tickets = j.tickets.restore(t.tickets, ticketKey)
+}
+
+// before maps a row id to the row before the transaction changed it, or to
+// nil when the transaction inserted it.
+type before[K cmp.Ordered, R any] map[K]*R
+
+func (b before[K, R]) save(rows []R, id K, key func(R) K) {
+	if _, ok := b[id]; ok {
+		return
+	}
+
+	b[id] = nil
+	for _, r := range rows {
+		if key(r) == id {
+			b[id] = &r
+			break
+		}
+	}
+}
+
+// restore keeps rows ordered by id, which is the order they are inserted in.
+func (b before[K, R]) restore(rows []R, key func(R) K) []R {
+	for id, old := range b {
+		i, found := slices.BinarySearchFunc(rows, id, func(r R, id K) int {
+			return cmp.Compare(key(r), id)
+		})
+
+		switch {
+		case found && old != nil:
+			rows[i] = *old
+		case found:
+			rows = slices.Delete(rows, i, i+1)
+		case old != nil:
+			rows = slices.Insert(rows, i, *old)
+		}
+	}
+
+	return rows
+}
@@ -0,0 +1,918 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"ticket/pkg/db"
+	"time"
+
+	"github.com/guregu/null/v5"
+)
+
+var (
+	// ErrForeignKey and ErrDuplicate stand in for the MySQL constraint errors.
+	ErrForeignKey = errors.New("store: foreign key constraint fails")
+	ErrDuplicate  = errors.New("store: duplicate entry")
+)
+
+// Memory is an in-memory Store for tests. It mirrors the SQL queries,
+// including ownership joins, ordering and foreign keys. Transactions are
+// serialized and roll back only the rows they changed, but writes inside one
+// are visible to concurrent queries outside it.
+type Memory struct {
+	// Now stamps created_at and updated_at.
+	Now func() time.Time
+
+	*memoryState
+
+	// journal is set on the Memory a transaction hands to fn.
+	journal *journal
+}
+
+// memoryState is shared between a Memory and its transactions.
+type memoryState struct {
+	tx   sync.Mutex
+	mu   sync.Mutex
+	data tables
+}
+
+type tables struct {
+	users      []db.User
+	identities []db.UserIdentity
+	boards     []db.Board
+	statuses   []db.Status
+	tickets    []db.Ticket
+	lastID     map[string]uint64
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		Now:         time.Now,
+		memoryState: &memoryState{data: tables{lastID: map[string]uint64{}}},
+	}
+}
+
+// Tx runs transactions one at a time, so opts has no effect.
+func (m *Memory) Tx(ctx context.Context, opts *sql.TxOptions, fn func(q Querier) error) error {
+	m.tx.Lock()
+	defer m.tx.Unlock()
+
+	j := newJournal()
+	err := fn(&Memory{Now: m.Now, memoryState: m.memoryState, journal: j})
+	if err != nil {
+		m.mu.Lock()
+		j.restore(&m.data)
+		m.mu.Unlock()
+	}
+
+	return err
+}
+
+// AddUser inserts u as is, e.g. an admin, and returns its id.
+func (m *Memory) AddUser(u db.User) uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	u.ID = m.data.nextID("users")
+	m.journal.user(&m.data, u.ID)
+	if !u.CreatedAt.Valid {
+		u.CreatedAt = m.now()
+	}
+	m.data.users = append(m.data.users, u)
+
+	return u.ID
+}
+
+func (t *tables) nextID(table string) uint64 {
+	t.lastID[table]++
+
+	return t.lastID[table]
+}
+
+// now truncates to seconds like a DATETIME column.
+func (m *Memory) now() null.Time {
+	return null.TimeFrom(m.Now().UTC().Truncate(time.Second))
+}
+
+type result int64
+
+func (r result) LastInsertId() (int64, error) { return int64(r), nil }
+func (r result) RowsAffected() (int64, error) { return 1, nil }
+
+func descending(direction interface{}) bool {
+	switch d := direction.(type) {
+	case string:
+		return d == "desc"
+	case null.String:
+		return d.Valid && d.String == "desc"
+	case sql.NullString:
+		return d.Valid && d.String == "desc"
+	}
+
+	return false
+}
+
+func ascending(direction interface{}) bool {
+	switch d := direction.(type) {
+	case string:
+		return d == "asc"
+	case null.String:
+		return d.Valid && d.String == "asc"
+	case sql.NullString:
+		return d.Valid && d.String == "asc"
+	}
+
+	return false
+}
+
+func contains[T comparable](ids []T, id T) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (t *tables) user(id uint64) (int, bool) {
+	for i, u := range t.users {
+		if u.ID == id {
+			return i, true
+		}
+	}
+
+	return 0, false
+}
+
+func (t *tables) board(id uint32) (int, bool) {
+	for i, b := range t.boards {
+		if b.ID == id {
+			return i, true
+		}
+	}
+
+	return 0, false
+}
+
+func (t *tables) status(id uint32) (int, bool) {
+	for i, s := range t.statuses {
+		if s.ID == id {
+			return i, true
+		}
+	}
+
+	return 0, false
+}
+
+func (t *tables) ticket(id uint64) (int, bool) {
+	for i, tk := range t.tickets {
+		if tk.ID == id {
+			return i, true
+		}
+	}
+
+	return 0, false
+}
+
+// ownedBoard is the boards side of the ownership joins.
+func (t *tables) ownedBoard(boardID uint32, userID uint64) (db.Board, bool) {
+	i, ok := t.board(boardID)
+	if !ok || t.boards[i].UserID != userID {
+		return db.Board{}, false
+	}
+
+	return t.boards[i], true
+}
+
+func (m *Memory) CountBoardByUserID(ctx context.Context, userID uint64) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var n int64
+	for _, b := range m.data.boards {
+		if b.UserID == userID {
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+func (m *Memory) CreateBoard(ctx context.Context, arg db.CreateBoardParams) (sql.Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.data.user(arg.UserID); !ok {
+		return nil, ErrForeignKey
+	}
+
+	id := uint32(m.data.nextID("boards"))
+	m.journal.board(&m.data, id)
+	m.data.boards = append(m.data.boards, db.Board{
+		ID:        id,
+		UserID:    arg.UserID,
+		Title:     arg.Title,
+		SortOrder: arg.SortOrder,
+		CreatedAt: m.now(),
+	})
+
+	return result(id), nil
+}
+
+func (m *Memory) DeleteBoardsByUserID(ctx context.Context, userID uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	boards := m.data.boards[:0:0]
+	for _, b := range m.data.boards {
+		if b.UserID != userID {
+			boards = append(boards, b)
+			continue
+		}
+		m.journal.board(&m.data, b.ID)
+
+		for _, s := range m.data.statuses {
+			if s.BoardID == b.ID {
+				return ErrForeignKey
+			}
+		}
+	}
+	m.data.boards = boards
+
+	return nil
+}
+
+func (m *Memory) GetBoard(ctx context.Context, arg db.GetBoardParams) (db.Board, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	b, ok := m.data.ownedBoard(arg.ID, arg.UserID)
+	if !ok {
+		return db.Board{}, sql.ErrNoRows
+	}
+
+	return b, nil
+}
+
+func (m *Memory) GetBoardsByUserID(ctx context.Context, userID uint64) ([]db.Board, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	boards := []db.Board{}
+	for _, b := range m.data.boards {
+		if b.UserID == userID {
+			boards = append(boards, b)
+		}
+	}
+
+	return boards, nil
+}
+
+func (m *Memory) UpdateBoard(ctx context.Context, arg db.UpdateBoardParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if i, ok := m.data.board(arg.ID); ok {
+		m.journal.board(&m.data, arg.ID)
+		m.data.boards[i].Title = arg.Title
+		m.data.boards[i].UpdatedAt = m.now()
+	}
+
+	return nil
+}
+
+func (m *Memory) CountStatusByBoardID(ctx context.Context, boardID uint32) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var n int64
+	for _, s := range m.data.statuses {
+		if s.BoardID == boardID {
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+// CountStatusWithBoard matches nothing for empty ids, like IN (NULL).
+func (m *Memory) CountStatusWithBoard(ctx context.Context, arg db.CountStatusWithBoardParams) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.data.ownedBoard(arg.BoardID, arg.UserID); !ok {
+		return 0, nil
+	}
+
+	var n int64
+	for _, s := range m.data.statuses {
+		if s.BoardID == arg.BoardID && contains(arg.Ids, s.ID) {
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+// CountStatusWithBoardExclude matches nothing for empty ids, like
+// NOT IN (NULL).
+func (m *Memory) CountStatusWithBoardExclude(ctx context.Context, arg db.CountStatusWithBoardExcludeParams) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.data.ownedBoard(arg.BoardID, arg.UserID); !ok || len(arg.Ids) == 0 {
+		return 0, nil
+	}
+
+	var n int64
+	for _, s := range m.data.statuses {
+		if s.BoardID == arg.BoardID && !contains(arg.Ids, s.ID) {
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+func (m *Memory) CreateStatus(ctx context.Context, arg db.CreateStatusParams) (sql.Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.data.board(arg.BoardID); !ok {
+		return nil, ErrForeignKey
+	}
+
+	id := uint32(m.data.nextID("statuses"))
+	m.journal.status(&m.data, id)
+	m.data.statuses = append(m.data.statuses, db.Status{
+		ID:        id,
+		BoardID:   arg.BoardID,
+		Title:     arg.Title,
+		SortOrder: arg.SortOrder,
+		CreatedAt: m.now(),
+	})
+
+	return result(id), nil
+}
+
+func (m *Memory) DeleteStatusesByUserID(ctx context.Context, userID uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	statuses := m.data.statuses[:0:0]
+	for _, s := range m.data.statuses {
+		if b, ok := m.data.board(s.BoardID); !ok || m.data.boards[b].UserID != userID {
+			statuses = append(statuses, s)
+			continue
+		}
+		m.journal.status(&m.data, s.ID)
+
+		for _, t := range m.data.tickets {
+			if t.StatusID == s.ID {
+				return ErrForeignKey
+			}
+		}
+	}
+	m.data.statuses = statuses
+
+	return nil
+}
+
+// GetStatus treats an invalid id or board id as a wildcard.
+func (m *Memory) GetStatus(ctx context.Context, arg db.GetStatusParams) (db.Status, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, s := range m.data.statuses {
+		if arg.ID.Valid && s.ID != uint32(arg.ID.Int32) {
+			continue
+		}
+
+		if arg.BoardID.Valid && s.BoardID != uint32(arg.BoardID.Int32) {
+			continue
+		}
+
+		return s, nil
+	}
+
+	return db.Status{}, sql.ErrNoRows
+}
+
+func (m *Memory) GetStatusWithBoard(ctx context.Context, arg db.GetStatusWithBoardParams) (db.GetStatusWithBoardRow, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	b, ok := m.data.ownedBoard(arg.BoardID, arg.UserID)
+	if !ok {
+		return db.GetStatusWithBoardRow{}, sql.ErrNoRows
+	}
+
+	i, ok := m.data.status(arg.ID)
+	if !ok || m.data.statuses[i].BoardID != b.ID {
+		return db.GetStatusWithBoardRow{}, sql.ErrNoRows
+	}
+
+	return db.GetStatusWithBoardRow{Status: m.data.statuses[i], Board: b}, nil
+}
+
+// GetStatuses returns every status for empty ids, ordered by board, then by
+// sort order in the requested direction.
+func (m *Memory) GetStatuses(ctx context.Context, arg db.GetStatusesParams) ([]db.Status, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	statuses := []db.Status{}
+	for _, s := range m.data.statuses {
+		if arg.BoardID.Valid && s.BoardID != uint32(arg.BoardID.Int32) {
+			continue
+		}
+
+		if len(arg.Ids) > 0 && !contains(arg.Ids, s.ID) {
+			continue
+		}
+
+		statuses = append(statuses, s)
+	}
+
+	asc, desc := ascending(arg.SortOrderDirection), descending(arg.SortOrderDirection)
+	sort.SliceStable(statuses, func(i, j int) bool {
+		a, b := statuses[i], statuses[j]
+		if a.BoardID != b.BoardID {
+			return a.BoardID < b.BoardID
+		}
+
+		if asc {
+			return a.SortOrder < b.SortOrder
+		}
+
+		if desc {
+			return a.SortOrder > b.SortOrder
+		}
+
+		return false
+	})
+
+	return statuses, nil
+}
+
+func (m *Memory) UpdateStatus(ctx context.Context, arg db.UpdateStatusParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if i, ok := m.data.status(arg.ID); ok {
+		m.journal.status(&m.data, arg.ID)
+		m.data.statuses[i].Title = arg.Title
+		m.data.statuses[i].SortOrder = arg.SortOrder
+		m.data.statuses[i].UpdatedAt = m.now()
+	}
+
+	return nil
+}
+
+func (m *Memory) UpdateStatusSortOrder(ctx context.Context, arg db.UpdateStatusSortOrderParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if i, ok := m.data.status(arg.ID); ok {
+		m.journal.status(&m.data, arg.ID)
+		m.data.statuses[i].SortOrder = arg.SortOrder
+	}
+
+	return nil
+}
+
+func (m *Memory) CountTicketByStatusID(ctx context.Context, statusID uint32) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var n int64
+	for _, t := range m.data.tickets {
+		if t.StatusID == statusID {
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+func (m *Memory) CreateTicket(ctx context.Context, arg db.CreateTicketParams) (sql.Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.data.status(arg.StatusID); !ok {
+		return nil, ErrForeignKey
+	}
+
+	id := m.data.nextID("tickets")
+	m.journal.ticket(&m.data, id)
+	m.data.tickets = append(m.data.tickets, db.Ticket{
+		ID:          id,
+		StatusID:    arg.StatusID,
+		Title:       arg.Title,
+		Description: arg.Description,
+		Contact:     arg.Contact,
+		SortOrder:   arg.SortOrder,
+		CreatedAt:   m.now(),
+	})
+
+	return result(id), nil
+}
+
+func (m *Memory) DeleteTicketsByUserID(ctx context.Context, userID uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tickets := m.data.tickets[:0:0]
+	for _, t := range m.data.tickets {
+		if _, ok := m.data.ticketBoard(t, userID); !ok {
+			tickets = append(tickets, t)
+			continue
+		}
+		m.journal.ticket(&m.data, t.ID)
+	}
+	m.data.tickets = tickets
+
+	return nil
+}
+
+// ticketBoard is the statuses and boards side of the ticket ownership joins.
+func (t *tables) ticketBoard(tk db.Ticket, userID uint64) (db.Board, bool) {
+	i, ok := t.status(tk.StatusID)
+	if !ok {
+		return db.Board{}, false
+	}
+
+	return t.ownedBoard(t.statuses[i].BoardID, userID)
+}
+
+func (m *Memory) GetTicketByID(ctx context.Context, id uint64) (db.Ticket, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i, ok := m.data.ticket(id)
+	if !ok {
+		return db.Ticket{}, sql.ErrNoRows
+	}
+
+	return m.data.tickets[i], nil
+}
+
+func (m *Memory) GetTicketWithBoard(ctx context.Context, arg db.GetTicketWithBoardParams) (db.GetTicketWithBoardRow, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i, ok := m.data.ticket(arg.ID)
+	if !ok {
+		return db.GetTicketWithBoardRow{}, sql.ErrNoRows
+	}
+
+	b, ok := m.data.ticketBoard(m.data.tickets[i], arg.UserID)
+	if !ok || b.ID != arg.BoardID {
+		return db.GetTicketWithBoardRow{}, sql.ErrNoRows
+	}
+
+	return db.GetTicketWithBoardRow{Ticket: m.data.tickets[i], Board: b}, nil
+}
+
+// GetTickets returns every ticket for empty status ids, ordered by status,
+// then by sort order in the requested direction.
+func (m *Memory) GetTickets(ctx context.Context, arg db.GetTicketsParams) ([]db.Ticket, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tickets := []db.Ticket{}
+	for _, t := range m.data.tickets {
+		if len(arg.StatusIds) == 0 || contains(arg.StatusIds, t.StatusID) {
+			tickets = append(tickets, t)
+		}
+	}
+
+	asc, desc := ascending(arg.SortOrderDirection), descending(arg.SortOrderDirection)
+	sort.SliceStable(tickets, func(i, j int) bool {
+		a, b := tickets[i], tickets[j]
+		if a.StatusID != b.StatusID {
+			return a.StatusID < b.StatusID
+		}
+
+		if asc {
+			return a.SortOrder < b.SortOrder
+		}
+
+		if desc {
+			return a.SortOrder > b.SortOrder
+		}
+
+		return false
+	})
+
+	return tickets, nil
+}
+
+func (m *Memory) GetTicketsWithBoard(ctx context.Context, arg db.GetTicketsWithBoardParams) ([]db.GetTicketsWithBoardRow, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rows := []db.GetTicketsWithBoardRow{}
+	for _, t := range m.data.tickets {
+		if !contains(arg.Ids, t.ID) {
+			continue
+		}
+
+		if b, ok := m.data.ticketBoard(t, arg.UserID); ok && b.ID == arg.BoardID {
+			rows = append(rows, db.GetTicketsWithBoardRow{Ticket: t, Board: b})
+		}
+	}
+
+	return rows, nil
+}
+
+// ReorderTickets checks every position before moving any ticket, as the
+// single UPDATE fails as a whole. updated_at only changes for tickets that
+// move to another status.
+func (m *Memory) ReorderTickets(ctx context.Context, positions []db.TicketPosition) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, p := range positions {
+		if _, ok := m.data.status(p.StatusID); !ok {
+			return ErrForeignKey
+		}
+	}
+
+	for _, p := range positions {
+		i, ok := m.data.ticket(p.ID)
+		if !ok {
+			continue
+		}
+
+		m.journal.ticket(&m.data, p.ID)
+		t := &m.data.tickets[i]
+		if t.StatusID != p.StatusID {
+			t.UpdatedAt = m.now()
+		}
+		t.StatusID = p.StatusID
+		t.SortOrder = p.SortOrder
+	}
+
+	return nil
+}
+
+func (m *Memory) UpdateTicket(ctx context.Context, arg db.UpdateTicketParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i, ok := m.data.ticket(arg.ID)
+	if !ok {
+		return nil
+	}
+
+	if _, ok := m.data.status(arg.StatusID); !ok {
+		return ErrForeignKey
+	}
+
+	m.journal.ticket(&m.data, arg.ID)
+	t := &m.data.tickets[i]
+	t.StatusID = arg.StatusID
+	t.Title = arg.Title
+	t.Description = arg.Description
+	t.Contact = arg.Contact
+	t.SortOrder = arg.SortOrder
+	t.UpdatedAt = m.now()
+
+	return nil
+}
+
+// matchUser is the WHERE clause of SearchUsers: an empty query matches
+// everyone, otherwise a substring of the email, name or lastname.
+func matchUser(u db.User, query string) bool {
+	if query == "" {
+		return true
+	}
+
+	for _, s := range []null.String{u.Email, u.Name, u.Lastname} {
+		if s.Valid && strings.Contains(strings.ToLower(s.String), strings.ToLower(query)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *Memory) CountSearchUsers(ctx context.Context, query string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var n int64
+	for _, u := range m.data.users {
+		if matchUser(u, query) {
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+func (m *Memory) CreateUser(ctx context.Context, arg db.CreateUserParams) (sql.Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	id := m.data.nextID("users")
+	m.journal.user(&m.data, id)
+	m.data.users = append(m.data.users, db.User{
+		ID:        id,
+		Name:      arg.Name,
+		Lastname:  arg.Lastname,
+		Email:     arg.Email,
+		Password:  arg.Password,
+		CreatedAt: m.now(),
+	})
+
+	return result(id), nil
+}
+
+func (m *Memory) DeleteUser(ctx context.Context, id uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, b := range m.data.boards {
+		if b.UserID == id {
+			return ErrForeignKey
+		}
+	}
+
+	for _, ui := range m.data.identities {
+		if ui.UserID == id {
+			return ErrForeignKey
+		}
+	}
+
+	if i, ok := m.data.user(id); ok {
+		m.journal.user(&m.data, id)
+		m.data.users = append(m.data.users[:i:i], m.data.users[i+1:]...)
+	}
+
+	return nil
+}
+
+// FindUserByEmail never matches a NULL email, like email = NULL.
+func (m *Memory) FindUserByEmail(ctx context.Context, email null.String) (db.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !email.Valid {
+		return db.User{}, sql.ErrNoRows
+	}
+
+	for _, u := range m.data.users {
+		if u.Email.Valid && strings.EqualFold(u.Email.String, email.String) {
+			return u, nil
+		}
+	}
+
+	return db.User{}, sql.ErrNoRows
+}
+
+func (m *Memory) FindUserByID(ctx context.Context, id uint64) (db.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i, ok := m.data.user(id)
+	if !ok {
+		return db.User{}, sql.ErrNoRows
+	}
+
+	return m.data.users[i], nil
+}
+
+func (m *Memory) SearchUsers(ctx context.Context, arg db.SearchUsersParams) ([]db.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	users := []db.User{}
+	skipped := int32(0)
+	for _, u := range m.data.users {
+		if int32(len(users)) >= arg.Limit {
+			break
+		}
+
+		if !matchUser(u, arg.Query) {
+			continue
+		}
+
+		if skipped < arg.Offset {
+			skipped++
+			continue
+		}
+
+		users = append(users, u)
+	}
+
+	return users, nil
+}
+
+func (m *Memory) UpdateUser(ctx context.Context, arg db.UpdateUserParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if i, ok := m.data.user(arg.ID); ok {
+		m.journal.user(&m.data, arg.ID)
+		u := &m.data.users[i]
+		u.Name = arg.Name
+		u.Lastname = arg.Lastname
+		u.Email = arg.Email
+		u.Password = arg.Password
+		u.UpdatedAt = m.now()
+	}
+
+	return nil
+}
+
+func (m *Memory) UpdateUserDisabledAt(ctx context.Context, arg db.UpdateUserDisabledAtParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if i, ok := m.data.user(arg.ID); ok {
+		m.journal.user(&m.data, arg.ID)
+		m.data.users[i].DisabledAt = arg.DisabledAt
+		m.data.users[i].UpdatedAt = m.now()
+	}
+
+	return nil
+}
+
+func (m *Memory) UpdateUserPassword(ctx context.Context, arg db.UpdateUserPasswordParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if i, ok := m.data.user(arg.ID); ok {
+		m.journal.user(&m.data, arg.ID)
+		m.data.users[i].Password = arg.Password
+		m.data.users[i].PasswordResetRequired = arg.PasswordResetRequired
+		m.data.users[i].UpdatedAt = m.now()
+	}
+
+	return nil
+}
+
+func (m *Memory) CreateUserIdentity(ctx context.Context, arg db.CreateUserIdentityParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.data.user(arg.UserID); !ok {
+		return ErrForeignKey
+	}
+
+	for _, ui := range m.data.identities {
+		if ui.Provider == arg.Provider && ui.Subject == arg.Subject {
+			return ErrDuplicate
+		}
+	}
+
+	id := m.data.nextID("user_identities")
+	m.journal.identity(&m.data, id)
+	m.data.identities = append(m.data.identities, db.UserIdentity{
+		ID:        id,
+		UserID:    arg.UserID,
+		Provider:  arg.Provider,
+		Subject:   arg.Subject,
+		Email:     arg.Email,
+		CreatedAt: m.now(),
+	})
+
+	return nil
+}
+
+func (m *Memory) DeleteUserIdentitiesByUserID(ctx context.Context, userID uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	identities := m.data.identities[:0:0]
+	for _, ui := range m.data.identities {
+		if ui.UserID != userID {
+			identities = append(identities, ui)
+			continue
+		}
+		m.journal.identity(&m.data, ui.ID)
+	}
+	m.data.identities = identities
+
+	return nil
+}
+
+func (m *Memory) GetUserIdentity(ctx context.Context, arg db.GetUserIdentityParams) (db.UserIdentity, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, ui := range m.data.identities {
+		if ui.Provider == arg.Provider && ui.Subject == arg.Subject {
+			return ui, nil
+		}
+	}
+
+	return db.UserIdentity{}, sql.ErrNoRows
+}
@@ -0,0 +1,117 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"ticket/pkg/db"
+
+	"github.com/guregu/null/v5"
+)
+
+// TestMemoryRollback rolls back a transaction that inserts, updates and
+// deletes rows while another caller writes outside it, and checks only the
+// transaction's changes are undone.
+func TestMemoryRollback(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+	userID := m.AddUser(db.User{Email: null.StringFrom("a@example.com")})
+
+	create := func(q Querier, title string) uint32 {
+		t.Helper()
+
+		res, err := q.CreateBoard(ctx, db.CreateBoardParams{UserID: userID, Title: null.StringFrom(title)})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		id, _ := res.LastInsertId()
+
+		return uint32(id)
+	}
+
+	kept := create(m, "kept")
+	renamed := create(m, "renamed")
+
+	errRollback := errors.New("roll back")
+	var inside, outside uint32
+	err := m.Tx(ctx, nil, func(q Querier) error {
+		inside = create(q, "inside")
+
+		err := q.UpdateBoard(ctx, db.UpdateBoardParams{ID: renamed, Title: null.StringFrom("renamed in tx")})
+		if err != nil {
+			return err
+		}
+
+		// A write outside the transaction, e.g. another request.
+		outside = create(m, "outside")
+		err = m.UpdateBoard(ctx, db.UpdateBoardParams{ID: kept, Title: null.StringFrom("kept, renamed outside")})
+		if err != nil {
+			return err
+		}
+
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("Tx error = %v, want %v", err, errRollback)
+	}
+
+	boards, err := m.GetBoardsByUserID(ctx, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[uint32]string{
+		kept:    "kept, renamed outside",
+		renamed: "renamed",
+		outside: "outside",
+	}
+	if len(boards) != len(want) {
+		t.Fatalf("got %d boards, want %d: %+v", len(boards), len(want), boards)
+	}
+
+	for _, b := range boards {
+		if b.ID == inside {
+			t.Errorf("board %d created in the transaction survived the rollback", b.ID)
+		}
+
+		if title, ok := want[b.ID]; !ok || b.Title.String != title {
+			t.Errorf("board %d has title %q, want %q", b.ID, b.Title.String, title)
+		}
+	}
+}
+
+func TestMemoryRollbackRestoresDeletedRows(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+	first := m.AddUser(db.User{Email: null.StringFrom("a@example.com")})
+	second := m.AddUser(db.User{Email: null.StringFrom("b@example.com")})
+
+	err := m.Tx(ctx, nil, func(q Querier) error {
+		err := q.DeleteUser(ctx, first)
+		if err != nil {
+			return err
+		}
+
+		return errors.New("roll back")
+	})
+	if err == nil {
+		t.Fatal("want the transaction's error")
+	}
+
+	for _, id := range []uint64{first, second} {
+		_, err := m.FindUserByID(ctx, id)
+		if err != nil {
+			t.Errorf("user %d after rollback: %v", id, err)
+		}
+	}
+
+	users, err := m.SearchUsers(ctx, db.SearchUsersParams{Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(users) != 2 || users[0].ID != first || users[1].ID != second {
+		t.Errorf("users after rollback = %+v, want ids %d and %d in order", users, first, second)
+	}
+}
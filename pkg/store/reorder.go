@@ -0,0 +1,47 @@
+package store
+
+import (
+	"context"
+	"ticket/pkg/db"
+
+	"github.com/guregu/null/v5"
+)
+
+// PlanReorder loads the tickets db.PlanReorder checks orders against, on a
+// board that belongs to userID, and returns its result. The statuses in
+// orders must already be known to be on the board.
+func PlanReorder(ctx context.Context, q Querier, boardID uint32, userID uint64, orders ...db.StatusOrder) ([]db.TicketPosition, int, error) {
+	var statusIDs []uint32
+	var ticketIDs []uint64
+	for _, o := range orders {
+		statusIDs = append(statusIDs, o.StatusID)
+		ticketIDs = append(ticketIDs, o.TicketIDs...)
+	}
+
+	rows, err := q.GetTicketsWithBoard(ctx, db.GetTicketsWithBoardParams{
+		Ids:     ticketIDs,
+		BoardID: boardID,
+		UserID:  userID,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	found := make([]db.Ticket, 0, len(rows))
+	for _, row := range rows {
+		found = append(found, row.Ticket)
+	}
+
+	var current []db.Ticket
+	if len(statusIDs) > 0 {
+		current, err = q.GetTickets(ctx, db.GetTicketsParams{
+			StatusIds:          statusIDs,
+			SortOrderDirection: null.StringFrom("asc"),
+		})
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return db.PlanReorder(orders, found, current)
+}
@@ -0,0 +1,110 @@
+//go:build ignore
+
+// serialgen writes serial.go, which forwards every Querier method to the
+// wrapped Querier while holding serialQuerier's lock. Run it through
+// go generate after changing Querier.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"log"
+	"os"
+	"strings"
+)
+
+func main() {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "store.go", nil, parser.SkipObjectResolution)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	querier := findInterface(f, "Querier")
+	if querier == nil {
+		log.Fatal("store.go: no Querier interface")
+	}
+
+	var b bytes.Buffer
+	// The methods use the same types as Querier, so they need its imports.
+	b.WriteString("// Code generated by serialgen.go; DO NOT EDIT.\n\npackage store\n\n")
+	for _, decl := range f.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			b.WriteString(expr(fset, gen) + "\n")
+		}
+	}
+
+	for _, m := range querier.Methods.List {
+		fn := m.Type.(*ast.FuncType)
+		name := m.Names[0].Name
+
+		var params, args []string
+		for _, p := range fn.Params.List {
+			for _, n := range p.Names {
+				params = append(params, n.Name+" "+expr(fset, p.Type))
+				args = append(args, n.Name)
+			}
+		}
+
+		fmt.Fprintf(&b, "\nfunc (s *serialQuerier) %s(%s) %s {\n", name, strings.Join(params, ", "), results(fset, fn.Results))
+		b.WriteString("\ts.mu.Lock()\n\tdefer s.mu.Unlock()\n\n")
+		fmt.Fprintf(&b, "\treturn s.q.%s(%s)\n}\n", name, strings.Join(args, ", "))
+	}
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = os.WriteFile("serial.go", src, 0o644)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func findInterface(f *ast.File, name string) *ast.InterfaceType {
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+
+		for _, spec := range gen.Specs {
+			ts := spec.(*ast.TypeSpec)
+			if it, ok := ts.Type.(*ast.InterfaceType); ok && ts.Name.Name == name {
+				return it
+			}
+		}
+	}
+
+	return nil
+}
+
+func results(fset *token.FileSet, fl *ast.FieldList) string {
+	if fl == nil {
+		return ""
+	}
+
+	var types []string
+	for _, r := range fl.List {
+		types = append(types, expr(fset, r.Type))
+	}
+
+	if len(types) == 1 {
+		return types[0]
+	}
+
+	return "(" + strings.Join(types, ", ") + ")"
+}
+
+func expr(fset *token.FileSet, node ast.Node) string {
+	var b bytes.Buffer
+	printer.Fprint(&b, fset, node)
+
+	return b.String()
+}
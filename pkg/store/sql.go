@@ -0,0 +1,37 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"ticket/pkg/db"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// SQL is the Store backed by a database pool, with every query traced.
+type SQL struct {
+	*db.Queries
+	db *sql.DB
+}
+
+func NewSQL(conn *sql.DB) *SQL {
+	return &SQL{
+		Queries: db.New(traced(conn, semconv.DBSystemMySQL)),
+		db:      conn,
+	}
+}
+
+func (s *SQL) Tx(ctx context.Context, opts *sql.TxOptions, fn func(q Querier) error) error {
+	tx, err := s.db.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = fn(db.New(traced(tx, semconv.DBSystemMySQL)))
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,246 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"ticket/pkg/db"
+	"ticket/pkg/db/sqlite"
+
+	"github.com/guregu/null/v5"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// SQLite is the Store backed by a SQLite database, with every query traced.
+// The sqlc overrides keep the sqlite models identical to pkg/db, so results
+// convert directly.
+type SQLite struct {
+	sqliteQueries
+	db *sql.DB
+}
+
+func NewSQLite(conn *sql.DB) *SQLite {
+	return &SQLite{
+		sqliteQueries: sqliteQueries{sqlite.New(traced(conn, semconv.DBSystemSqlite))},
+		db:            conn,
+	}
+}
+
+func (s *SQLite) Tx(ctx context.Context, opts *sql.TxOptions, fn func(q Querier) error) error {
+	tx, err := s.db.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = fn(sqliteQueries{sqlite.New(traced(tx, semconv.DBSystemSqlite))})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+type sqliteQueries struct {
+	q *sqlite.Queries
+}
+
+func convertAll[S, D any](in []S, convert func(S) D) []D {
+	out := make([]D, 0, len(in))
+	for _, v := range in {
+		out = append(out, convert(v))
+	}
+
+	return out
+}
+
+func boardFromSQLite(b sqlite.Board) db.Board    { return db.Board(b) }
+func statusFromSQLite(s sqlite.Status) db.Status { return db.Status(s) }
+func ticketFromSQLite(t sqlite.Ticket) db.Ticket { return db.Ticket(t) }
+func userFromSQLite(u sqlite.User) db.User       { return db.User(u) }
+
+func (s sqliteQueries) CountBoardByUserID(ctx context.Context, userID uint64) (int64, error) {
+	return s.q.CountBoardByUserID(ctx, userID)
+}
+
+func (s sqliteQueries) CreateBoard(ctx context.Context, arg db.CreateBoardParams) (sql.Result, error) {
+	return s.q.CreateBoard(ctx, sqlite.CreateBoardParams(arg))
+}
+
+func (s sqliteQueries) DeleteBoardsByUserID(ctx context.Context, userID uint64) error {
+	return s.q.DeleteBoardsByUserID(ctx, userID)
+}
+
+func (s sqliteQueries) GetBoard(ctx context.Context, arg db.GetBoardParams) (db.Board, error) {
+	b, err := s.q.GetBoard(ctx, sqlite.GetBoardParams(arg))
+
+	return db.Board(b), err
+}
+
+func (s sqliteQueries) GetBoardsByUserID(ctx context.Context, userID uint64) ([]db.Board, error) {
+	boards, err := s.q.GetBoardsByUserID(ctx, userID)
+
+	return convertAll(boards, boardFromSQLite), err
+}
+
+func (s sqliteQueries) UpdateBoard(ctx context.Context, arg db.UpdateBoardParams) error {
+	return s.q.UpdateBoard(ctx, sqlite.UpdateBoardParams(arg))
+}
+
+func (s sqliteQueries) CountStatusByBoardID(ctx context.Context, boardID uint32) (int64, error) {
+	return s.q.CountStatusByBoardID(ctx, boardID)
+}
+
+func (s sqliteQueries) CountStatusWithBoard(ctx context.Context, arg db.CountStatusWithBoardParams) (int64, error) {
+	return s.q.CountStatusWithBoard(ctx, sqlite.CountStatusWithBoardParams(arg))
+}
+
+func (s sqliteQueries) CountStatusWithBoardExclude(ctx context.Context, arg db.CountStatusWithBoardExcludeParams) (int64, error) {
+	return s.q.CountStatusWithBoardExclude(ctx, sqlite.CountStatusWithBoardExcludeParams(arg))
+}
+
+func (s sqliteQueries) CreateStatus(ctx context.Context, arg db.CreateStatusParams) (sql.Result, error) {
+	return s.q.CreateStatus(ctx, sqlite.CreateStatusParams(arg))
+}
+
+func (s sqliteQueries) DeleteStatusesByUserID(ctx context.Context, userID uint64) error {
+	return s.q.DeleteStatusesByUserID(ctx, userID)
+}
+
+func (s sqliteQueries) GetStatus(ctx context.Context, arg db.GetStatusParams) (db.Status, error) {
+	st, err := s.q.GetStatus(ctx, sqlite.GetStatusParams(arg))
+
+	return db.Status(st), err
+}
+
+func (s sqliteQueries) GetStatusWithBoard(ctx context.Context, arg db.GetStatusWithBoardParams) (db.GetStatusWithBoardRow, error) {
+	row, err := s.q.GetStatusWithBoard(ctx, sqlite.GetStatusWithBoardParams(arg))
+
+	return db.GetStatusWithBoardRow{Status: db.Status(row.Status), Board: db.Board(row.Board)}, err
+}
+
+func (s sqliteQueries) GetStatuses(ctx context.Context, arg db.GetStatusesParams) ([]db.Status, error) {
+	statuses, err := s.q.GetStatuses(ctx, sqlite.GetStatusesParams{
+		BoardID:            arg.BoardID,
+		Ids:                arg.Ids,
+		SortOrderDirection: arg.SortOrderDirection,
+	})
+
+	return convertAll(statuses, statusFromSQLite), err
+}
+
+func (s sqliteQueries) UpdateStatus(ctx context.Context, arg db.UpdateStatusParams) error {
+	return s.q.UpdateStatus(ctx, sqlite.UpdateStatusParams(arg))
+}
+
+func (s sqliteQueries) UpdateStatusSortOrder(ctx context.Context, arg db.UpdateStatusSortOrderParams) error {
+	return s.q.UpdateStatusSortOrder(ctx, sqlite.UpdateStatusSortOrderParams(arg))
+}
+
+func (s sqliteQueries) CountTicketByStatusID(ctx context.Context, statusID uint32) (int64, error) {
+	return s.q.CountTicketByStatusID(ctx, statusID)
+}
+
+func (s sqliteQueries) CreateTicket(ctx context.Context, arg db.CreateTicketParams) (sql.Result, error) {
+	return s.q.CreateTicket(ctx, sqlite.CreateTicketParams(arg))
+}
+
+func (s sqliteQueries) DeleteTicketsByUserID(ctx context.Context, userID uint64) error {
+	return s.q.DeleteTicketsByUserID(ctx, userID)
+}
+
+func (s sqliteQueries) GetTicketByID(ctx context.Context, id uint64) (db.Ticket, error) {
+	t, err := s.q.GetTicketByID(ctx, id)
+
+	return db.Ticket(t), err
+}
+
+func (s sqliteQueries) GetTicketWithBoard(ctx context.Context, arg db.GetTicketWithBoardParams) (db.GetTicketWithBoardRow, error) {
+	row, err := s.q.GetTicketWithBoard(ctx, sqlite.GetTicketWithBoardParams(arg))
+
+	return db.GetTicketWithBoardRow{Ticket: db.Ticket(row.Ticket), Board: db.Board(row.Board)}, err
+}
+
+func (s sqliteQueries) GetTickets(ctx context.Context, arg db.GetTicketsParams) ([]db.Ticket, error) {
+	tickets, err := s.q.GetTickets(ctx, sqlite.GetTicketsParams{
+		StatusIds:          arg.StatusIds,
+		SortOrderDirection: arg.SortOrderDirection,
+	})
+
+	return convertAll(tickets, ticketFromSQLite), err
+}
+
+func (s sqliteQueries) GetTicketsWithBoard(ctx context.Context, arg db.GetTicketsWithBoardParams) ([]db.GetTicketsWithBoardRow, error) {
+	rows, err := s.q.GetTicketsWithBoard(ctx, sqlite.GetTicketsWithBoardParams(arg))
+
+	return convertAll(rows, func(row sqlite.GetTicketsWithBoardRow) db.GetTicketsWithBoardRow {
+		return db.GetTicketsWithBoardRow{Ticket: db.Ticket(row.Ticket), Board: db.Board(row.Board)}
+	}), err
+}
+
+func (s sqliteQueries) ReorderTickets(ctx context.Context, positions []db.TicketPosition) error {
+	return s.q.ReorderTickets(ctx, positions)
+}
+
+func (s sqliteQueries) UpdateTicket(ctx context.Context, arg db.UpdateTicketParams) error {
+	return s.q.UpdateTicket(ctx, sqlite.UpdateTicketParams(arg))
+}
+
+func (s sqliteQueries) CountSearchUsers(ctx context.Context, query string) (int64, error) {
+	return s.q.CountSearchUsers(ctx, query)
+}
+
+func (s sqliteQueries) CreateUser(ctx context.Context, arg db.CreateUserParams) (sql.Result, error) {
+	return s.q.CreateUser(ctx, sqlite.CreateUserParams(arg))
+}
+
+func (s sqliteQueries) DeleteUser(ctx context.Context, id uint64) error {
+	return s.q.DeleteUser(ctx, id)
+}
+
+func (s sqliteQueries) FindUserByEmail(ctx context.Context, email null.String) (db.User, error) {
+	u, err := s.q.FindUserByEmail(ctx, email)
+
+	return db.User(u), err
+}
+
+func (s sqliteQueries) FindUserByID(ctx context.Context, id uint64) (db.User, error) {
+	u, err := s.q.FindUserByID(ctx, id)
+
+	return db.User(u), err
+}
+
+func (s sqliteQueries) SearchUsers(ctx context.Context, arg db.SearchUsersParams) ([]db.User, error) {
+	users, err := s.q.SearchUsers(ctx, sqlite.SearchUsersParams{
+		Query:  arg.Query,
+		Limit:  int64(arg.Limit),
+		Offset: int64(arg.Offset),
+	})
+
+	return convertAll(users, userFromSQLite), err
+}
+
+func (s sqliteQueries) UpdateUser(ctx context.Context, arg db.UpdateUserParams) error {
+	return s.q.UpdateUser(ctx, sqlite.UpdateUserParams(arg))
+}
+
+func (s sqliteQueries) UpdateUserDisabledAt(ctx context.Context, arg db.UpdateUserDisabledAtParams) error {
+	return s.q.UpdateUserDisabledAt(ctx, sqlite.UpdateUserDisabledAtParams(arg))
+}
+
+func (s sqliteQueries) UpdateUserPassword(ctx context.Context, arg db.UpdateUserPasswordParams) error {
+	return s.q.UpdateUserPassword(ctx, sqlite.UpdateUserPasswordParams(arg))
+}
+
+func (s sqliteQueries) CreateUserIdentity(ctx context.Context, arg db.CreateUserIdentityParams) error {
+	return s.q.CreateUserIdentity(ctx, sqlite.CreateUserIdentityParams(arg))
+}
+
+func (s sqliteQueries) DeleteUserIdentitiesByUserID(ctx context.Context, userID uint64) error {
+	return s.q.DeleteUserIdentitiesByUserID(ctx, userID)
+}
+
+func (s sqliteQueries) GetUserIdentity(ctx context.Context, arg db.GetUserIdentityParams) (db.UserIdentity, error) {
+	ui, err := s.q.GetUserIdentity(ctx, sqlite.GetUserIdentityParams(arg))
+
+	return db.UserIdentity(ui), err
+}
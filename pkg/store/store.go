@@ -0,0 +1,81 @@
+// Package store puts the queries the handlers use behind an interface, so the
+// handlers run against MySQL or SQLite, or against Memory in tests.
+package store
+
+import (
+	"context"
+	"database/sql"
+	"ticket/pkg/db"
+
+	"github.com/guregu/null/v5"
+)
+
+// Querier is the subset of db.Queries used by the handlers and middleware.
+type Querier interface {
+	CountBoardByUserID(ctx context.Context, userID uint64) (int64, error)
+	CreateBoard(ctx context.Context, arg db.CreateBoardParams) (sql.Result, error)
+	DeleteBoardsByUserID(ctx context.Context, userID uint64) error
+	GetBoard(ctx context.Context, arg db.GetBoardParams) (db.Board, error)
+	GetBoardsByUserID(ctx context.Context, userID uint64) ([]db.Board, error)
+	UpdateBoard(ctx context.Context, arg db.UpdateBoardParams) error
+
+	CountStatusByBoardID(ctx context.Context, boardID uint32) (int64, error)
+	CountStatusWithBoard(ctx context.Context, arg db.CountStatusWithBoardParams) (int64, error)
+	CountStatusWithBoardExclude(ctx context.Context, arg db.CountStatusWithBoardExcludeParams) (int64, error)
+	CreateStatus(ctx context.Context, arg db.CreateStatusParams) (sql.Result, error)
+	DeleteStatusesByUserID(ctx context.Context, userID uint64) error
+	GetStatus(ctx context.Context, arg db.GetStatusParams) (db.Status, error)
+	GetStatusWithBoard(ctx context.Context, arg db.GetStatusWithBoardParams) (db.GetStatusWithBoardRow, error)
+	GetStatuses(ctx context.Context, arg db.GetStatusesParams) ([]db.Status, error)
+	UpdateStatus(ctx context.Context, arg db.UpdateStatusParams) error
+	UpdateStatusSortOrder(ctx context.Context, arg db.UpdateStatusSortOrderParams) error
+
+	CountTicketByStatusID(ctx context.Context, statusID uint32) (int64, error)
+	CreateTicket(ctx context.Context, arg db.CreateTicketParams) (sql.Result, error)
+	DeleteTicketsByUserID(ctx context.Context, userID uint64) error
+	GetTicketByID(ctx context.Context, id uint64) (db.Ticket, error)
+	GetTicketWithBoard(ctx context.Context, arg db.GetTicketWithBoardParams) (db.GetTicketWithBoardRow, error)
+	GetTickets(ctx context.Context, arg db.GetTicketsParams) ([]db.Ticket, error)
+	GetTicketsWithBoard(ctx context.Context, arg db.GetTicketsWithBoardParams) ([]db.GetTicketsWithBoardRow, error)
+	ReorderTickets(ctx context.Context, positions []db.TicketPosition) error
+	UpdateTicket(ctx context.Context, arg db.UpdateTicketParams) error
+
+	CountSearchUsers(ctx context.Context, query string) (int64, error)
+	CreateUser(ctx context.Context, arg db.CreateUserParams) (sql.Result, error)
+	DeleteUser(ctx context.Context, id uint64) error
+	FindUserByEmail(ctx context.Context, email null.String) (db.User, error)
+	FindUserByID(ctx context.Context, id uint64) (db.User, error)
+	SearchUsers(ctx context.Context, arg db.SearchUsersParams) ([]db.User, error)
+	UpdateUser(ctx context.Context, arg db.UpdateUserParams) error
+	UpdateUserDisabledAt(ctx context.Context, arg db.UpdateUserDisabledAtParams) error
+	UpdateUserPassword(ctx context.Context, arg db.UpdateUserPasswordParams) error
+
+	CreateUserIdentity(ctx context.Context, arg db.CreateUserIdentityParams) error
+	DeleteUserIdentitiesByUserID(ctx context.Context, userID uint64) error
+	GetUserIdentity(ctx context.Context, arg db.GetUserIdentityParams) (db.UserIdentity, error)
+}
+
+// Store runs queries directly or inside a transaction.
+type Store interface {
+	Querier
+	// Tx runs fn in a transaction begun with opts that commits when fn returns
+	// nil and rolls back otherwise. fn's error is returned unchanged. Handlers
+	// use WithTx, which adds retries, rather than calling Tx directly.
+	Tx(ctx context.Context, opts *sql.TxOptions, fn func(q Querier) error) error
+}
+
+// New returns the Store for conn, opened with the named database driver.
+func New(driver string, conn *sql.DB) Store {
+	if driver == "sqlite" {
+		return NewSQLite(conn)
+	}
+
+	return NewSQL(conn)
+}
+
+var (
+	_ Querier = (*db.Queries)(nil)
+	_ Store   = (*SQL)(nil)
+	_ Store   = (*SQLite)(nil)
+	_ Store   = (*Memory)(nil)
+)
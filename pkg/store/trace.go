@@ -0,0 +1,93 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"ticket/pkg/db"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("ticket/pkg/store")
+
+type tracedDBTX struct {
+	db     db.DBTX
+	system attribute.KeyValue
+}
+
+// traced wraps d so every statement runs inside a span named after the sqlc
+// query. system is the semconv db.system of the driver, e.g.
+// semconv.DBSystemMySQL.
+func traced(d db.DBTX, system attribute.KeyValue) db.DBTX {
+	return &tracedDBTX{db: d, system: system}
+}
+
+func (t *tracedDBTX) start(ctx context.Context, query string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, queryName(query),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			t.system,
+			attribute.String("db.statement", query),
+		),
+	)
+}
+
+func end(span trace.Span, err error) {
+	if err != nil && err != sql.ErrNoRows {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
+
+func (t *tracedDBTX) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, span := t.start(ctx, query)
+	res, err := t.db.ExecContext(ctx, query, args...)
+	end(span, err)
+
+	return res, err
+}
+
+func (t *tracedDBTX) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	ctx, span := t.start(ctx, query)
+	stmt, err := t.db.PrepareContext(ctx, query)
+	end(span, err)
+
+	return stmt, err
+}
+
+func (t *tracedDBTX) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	ctx, span := t.start(ctx, query)
+	rows, err := t.db.QueryContext(ctx, query, args...)
+	end(span, err)
+
+	return rows, err
+}
+
+func (t *tracedDBTX) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	ctx, span := t.start(ctx, query)
+	row := t.db.QueryRowContext(ctx, query, args...)
+	end(span, row.Err())
+
+	return row
+}
+
+// queryName reads the name from the "-- name: GetBoard :one" header sqlc puts
+// on every query.
+func queryName(query string) string {
+	const prefix = "-- name: "
+	if len(query) > len(prefix) && query[:len(prefix)] == prefix {
+		rest := query[len(prefix):]
+		for i := 0; i < len(rest); i++ {
+			if rest[i] == ' ' || rest[i] == '\n' {
+				return "db." + rest[:i]
+			}
+		}
+	}
+
+	return "db.query"
+}
@@ -0,0 +1,106 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+const (
+	defaultTxAttempts = 3
+	txRetryBackoff    = 20 * time.Millisecond
+)
+
+// MySQL errors that roll the transaction back and are worth running again.
+const (
+	mysqlLockWaitTimeout = 1205
+	mysqlDeadlock        = 1213
+)
+
+type txConfig struct {
+	opts     sql.TxOptions
+	attempts int
+}
+
+type TxOption func(*txConfig)
+
+// Isolation sets the transaction's isolation level. The default is the
+// database's, REPEATABLE READ on MySQL. SQLite transactions are always
+// serializable and ignore it.
+func Isolation(level sql.IsolationLevel) TxOption {
+	return func(cf *txConfig) {
+		cf.opts.Isolation = level
+	}
+}
+
+func ReadOnly() TxOption {
+	return func(cf *txConfig) {
+		cf.opts.ReadOnly = true
+	}
+}
+
+// Attempts sets how many times a transaction that hits a deadlock or a lock
+// wait timeout is run in total. The default is 3.
+func Attempts(n int) TxOption {
+	return func(cf *txConfig) {
+		cf.attempts = n
+	}
+}
+
+// WithTx runs fn in a transaction on s that begins with ctx, commits when fn
+// returns nil and rolls back otherwise. The Querier passed to fn runs one
+// statement at a time, so fn may share it between goroutines.
+//
+// When the database rolls the transaction back because of a deadlock or a
+// lock wait timeout, fn is run again in a new transaction after a short
+// backoff, so fn must only have effects through the Querier. Errors are
+// matched with errors.As, so fn may wrap them. Any other error is returned
+// unchanged.
+func WithTx(ctx context.Context, s Store, fn func(q Querier) error, opts ...TxOption) error {
+	cf := txConfig{attempts: defaultTxAttempts}
+	for _, opt := range opts {
+		opt(&cf)
+	}
+
+	backoff := txRetryBackoff
+	for attempt := 1; ; attempt++ {
+		err := s.Tx(ctx, &cf.opts, func(q Querier) error {
+			return fn(&serialQuerier{q: q})
+		})
+		if err == nil || attempt >= cf.attempts || !retryable(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff + rand.N(backoff)):
+		}
+
+		backoff *= 2
+	}
+}
+
+func retryable(err error) bool {
+	var me *mysql.MySQLError
+	if !errors.As(err, &me) {
+		return false
+	}
+
+	return me.Number == mysqlDeadlock || me.Number == mysqlLockWaitTimeout
+}
+
+//go:generate go run serialgen.go
+
+// serialQuerier runs one statement at a time. A transaction is a single
+// connection, and MySQL fails a statement sent while another one's rows are
+// still being read. Its methods are generated in serial.go.
+type serialQuerier struct {
+	mu sync.Mutex
+	q  Querier
+}
@@ -0,0 +1,78 @@
+package wire
+
+import "net/http"
+
+// Code is a stable, machine-readable error code. Clients should branch on it
+// rather than on the message, which is meant for people and may change.
+type Code string
+
+const (
+	CodeBadRequest       Code = "bad_request"
+	CodeValidation       Code = "validation_failed"
+	CodeUnauthorized     Code = "unauthorized"
+	CodeForbidden        Code = "forbidden"
+	CodeNotFound         Code = "not_found"
+	CodeMethodNotAllowed Code = "method_not_allowed"
+	CodeConflict         Code = "conflict"
+	CodeTooLarge         Code = "request_too_large"
+	CodeRateLimited      Code = "rate_limited"
+	CodeInternal         Code = "internal"
+	CodeBadGateway       Code = "bad_gateway"
+	CodeUnavailable      Code = "unavailable"
+	CodeGatewayTimeout   Code = "gateway_timeout"
+
+	CodeInvalidCredentials    Code = "invalid_credentials"
+	CodeAccountDisabled       Code = "account_disabled"
+	CodePasswordResetRequired Code = "password_reset_required"
+	CodeEmailTaken            Code = "email_taken"
+	CodeInvalidLayout         Code = "invalid_layout"
+	CodeOIDCFailed            Code = "oidc_failed"
+)
+
+var statusCodes = map[int]Code{
+	http.StatusBadRequest:            CodeBadRequest,
+	http.StatusUnauthorized:          CodeUnauthorized,
+	http.StatusForbidden:             CodeForbidden,
+	http.StatusNotFound:              CodeNotFound,
+	http.StatusMethodNotAllowed:      CodeMethodNotAllowed,
+	http.StatusConflict:              CodeConflict,
+	http.StatusRequestEntityTooLarge: CodeTooLarge,
+	http.StatusTooManyRequests:       CodeRateLimited,
+	http.StatusInternalServerError:   CodeInternal,
+	http.StatusBadGateway:            CodeBadGateway,
+	http.StatusServiceUnavailable:    CodeUnavailable,
+	http.StatusGatewayTimeout:        CodeGatewayTimeout,
+}
+
+// CodeFor is the code of an error that only has a status.
+func CodeFor(status int) Code {
+	if code, ok := statusCodes[status]; ok {
+		return code
+	}
+
+	if status >= http.StatusInternalServerError {
+		return CodeInternal
+	}
+
+	return CodeBadRequest
+}
+
+// ErrorResponse is the data of every error response, which is sent as a
+// GenericResponse with Error set. Messages maps each field of Fields to its
+// message, for forms that show them inline.
+type ErrorResponse struct {
+	Code      Code              `json:"code"`
+	RequestID string            `json:"request_id,omitempty"`
+	Fields    []FieldError      `json:"fields,omitempty"`
+	Messages  map[string]string `json:"messages,omitempty"`
+}
+
+// FieldError is one failed validation rule. Field is the JSON path of the
+// value, e.g. statuses[0].ticket_ids, and Message is in the language of the
+// request's Accept-Language header.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,19 @@
+// Package wire holds the JSON bodies the services send and the client reads.
+// It depends only on the standard library, so programs that call the API do
+// not import the services' own packages.
+package wire
+
+// GenericResponse is the envelope of every JSON response. Error responses
+// set Error, and their Data is an ErrorResponse.
+type GenericResponse[T any] struct {
+	Error   bool   `json:"error"`
+	Message string `json:"message"`
+	Data    T      `json:"data,omitempty"`
+}
+
+// Tokens are returned by sign-in, refresh, the OIDC callback and
+// impersonation. Sign-up returns none; sign in afterwards.
+type Tokens struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+}